@@ -3,10 +3,13 @@ package main
 import (
 	"oko/pkg/account"
 	"oko/pkg/action"
+	"oko/pkg/admin"
+	"oko/pkg/db"
 	"oko/pkg/domain"
 	"oko/pkg/ginapp"
 	"oko/pkg/ginapp/controller"
 	"oko/pkg/links"
+	"oko/pkg/org"
 	"oko/pkg/proxy"
 	"oko/pkg/repost"
 	"oko/pkg/rss"
@@ -29,6 +32,9 @@ func main() {
 		RootHandlers: controller.HandlerList{},
 		Ctrls: []controller.Ctrl{
 			account.NewController(),
+			admin.NewController(),
+			admin.NewAuditController(),
+			org.NewController(),
 			domain.NewController(),
 			links.NewController(),
 			repost.NewController(),
@@ -36,9 +42,10 @@ func main() {
 			action.NewController(),
 			rule.NewController(),
 			trigger.NewController(),
-			rss.NewController(),
+			rss.NewController(domain.NewDomainRepository(db.GetDB())),
 		},
-		Validators: valid.Validators,
+		Validators:        valid.Validators,
+		ValidatorMsgFuncs: valid.MessageFuncs,
 	}
 	api.Init()
 	api.Do()
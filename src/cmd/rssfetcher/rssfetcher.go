@@ -0,0 +1,145 @@
+package main
+
+import (
+	"context"
+	"oko/pkg/db"
+	"oko/pkg/env"
+	"oko/pkg/feed"
+	"oko/pkg/links"
+	"oko/pkg/log"
+	"oko/pkg/rss"
+	"oko/pkg/worker"
+	"sync"
+	"time"
+)
+
+const (
+	defFetchInterval = "5m"
+	defFetchTimeout  = 30 * time.Second
+	defFetchWorkers  = 4
+	defUserAgent     = "oko-rssfetcher/1.0"
+	defPollMin       = 15 * time.Minute
+	defPollMax       = 24 * time.Hour
+	defPollDefault   = time.Hour
+	// longer validators don't fit the column and are not stored
+	maxValidatorLen = 255
+)
+
+// resultStore records fetch results of rss links.
+type resultStore interface {
+	SaveFetchResult(id uint, res rss.FetchResult) error
+}
+
+func main() {
+	worker.StartScheduler(handler, env.GetEnvOrDefault("RSS_FETCH_INTERVAL", defFetchInterval))
+}
+
+func handler() {
+	rssRepo := rss.NewRssRepository(db.GetDB())
+	linkRepo := links.NewLinkRepository(db.GetDB())
+	client := &feed.Client{
+		HTTP:      feed.PublicClient(env.GetEnvDurationOrDefault("RSS_FETCH_TIMEOUT", defFetchTimeout)),
+		UserAgent: env.GetEnvOrDefault("RSS_FETCH_USER_AGENT", defUserAgent),
+	}
+
+	schedule := feed.Schedule{
+		Min:     env.GetEnvDurationOrDefault("RSS_POLL_MIN", defPollMin),
+		Max:     env.GetEnvDurationOrDefault("RSS_POLL_MAX", defPollMax),
+		Default: env.GetEnvDurationOrDefault("RSS_POLL_DEFAULT", defPollDefault),
+	}
+
+	feeds, err := rssRepo.ListForFetch(time.Now())
+	if err != nil {
+		log.Errorln("Fail to list rss links", err)
+		return
+	}
+	log.Println("Start fetching", len(feeds), "feeds")
+
+	workers := env.GetEnvIntOrDefault("RSS_FETCH_WORKERS", defFetchWorkers)
+	if workers < 1 {
+		workers = 1
+	}
+	jobs := make(chan *rss.Rss)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for item := range jobs {
+				fetch(client, schedule, rssRepo, linkRepo, item)
+			}
+		}()
+	}
+	for _, item := range feeds {
+		jobs <- item
+	}
+	close(jobs)
+	wg.Wait()
+
+	log.Println("Finish it!")
+}
+
+// fetch ingests items of the feed into links and records result of the fetch with time of the next one.
+func fetch(client *feed.Client, schedule feed.Schedule, rssRepo resultStore, linkRepo links.Repository, item *rss.Rss) {
+	now := time.Now()
+	res := rss.FetchResult{
+		FetchedAt:    now,
+		ItemCount:    item.ItemCount,
+		ETag:         item.ETag,
+		LastModified: item.LastModified,
+		AvgItemGap:   time.Duration(item.AvgItemGap) * time.Second,
+	}
+
+	parsed, validators, err := client.FetchIfModified(context.Background(), item.Link, feed.Validators{
+		ETag:         item.ETag,
+		LastModified: item.LastModified,
+	})
+	switch err {
+	case nil:
+		res.ItemCount = len(parsed.Items)
+		res.ETag = trimValidator(validators.ETag)
+		res.LastModified = trimValidator(validators.LastModified)
+		res.AvgItemGap = feed.SmoothGap(res.AvgItemGap, feed.AverageGap(parsed.Items))
+		err = linkRepo.BulkCreateRecords(toLinks(item.DomainID, parsed.Items))
+	case feed.ErrNotModified:
+		err = nil
+	}
+
+	if err != nil {
+		log.Errorln("Fail to fetch", item.Link, err)
+		res.Error = err.Error()
+		res.FailureCount = item.FailureCount + 1
+		res.NextFetchAt = now.Add(schedule.Backoff(res.FailureCount))
+	} else {
+		res.NextFetchAt = now.Add(schedule.Next(res.AvgItemGap))
+	}
+
+	if err = rssRepo.SaveFetchResult(item.ID, res); err != nil {
+		log.Errorln("Fail to save fetch result", item.Link, err)
+	}
+}
+
+func trimValidator(v string) string {
+	if len(v) > maxValidatorLen {
+		return ""
+	}
+	return v
+}
+
+// toLinks maps feed items to links of the domain, repeated urls are dropped.
+func toLinks(domainID uint, items []feed.Item) []links.Link {
+	seen := make(map[string]bool, len(items))
+	res := make([]links.Link, 0, len(items))
+	for _, item := range items {
+		if seen[item.URL] {
+			continue
+		}
+		seen[item.URL] = true
+		res = append(res, links.Link{
+			URL:         item.URL,
+			DomainID:    domainID,
+			PublishedAt: item.PublishedAt,
+		})
+	}
+	return res
+}
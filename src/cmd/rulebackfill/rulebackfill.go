@@ -0,0 +1,36 @@
+package main
+
+import (
+	"oko/pkg/action"
+	"oko/pkg/db"
+	"oko/pkg/env"
+	"oko/pkg/log"
+	"oko/pkg/rule"
+	"oko/pkg/trigger"
+
+	"github.com/jinzhu/gorm"
+)
+
+// rules created before organizations go to the default organization of the migration
+const defOrganizationID = 1
+
+// rulebackfill assigns existing rules, triggers and actions of proxy service to the organization,
+// it runs once after the organization migrations and is safe to run again, assigned items are skipped.
+func main() {
+	orgID := uint(env.GetEnvIntOrDefault("RULE_BACKFILL_ORGANIZATION_ID", defOrganizationID))
+	for _, item := range []struct {
+		name     string
+		backfill func(db *gorm.DB, orgID uint) (int, error)
+	}{
+		{"rules", rule.Backfill},
+		{"triggers", trigger.Backfill},
+		{"actions", action.Backfill},
+	} {
+		log.Println("Assign", item.name, "to organization", orgID)
+		n, err := item.backfill(db.GetDB(), orgID)
+		if err != nil {
+			log.Fatal("Fail ", err)
+		}
+		log.Println("Done!", n, "assigned")
+	}
+}
@@ -3,10 +3,15 @@ package main
 import (
 	"oko/pkg/account"
 	"oko/pkg/db"
+	"oko/pkg/env"
 	"oko/pkg/log"
 	"oko/pkg/worker"
+	"time"
 )
 
+// deleted accounts are purged after the delay in seconds
+const defAccountPurgeDelay = 2592000
+
 func main() {
 	worker.StartScheduler(handler, "24h")
 }
@@ -25,5 +30,19 @@ func handler() {
 	} else {
 		log.Println("Done!")
 	}
+	log.Println("\tDelete from email_change_token...")
+	if err := db.GetDB().Where("is_used or expire_at < now()").Delete(&account.EmailChangeToken{}).Error; err != nil {
+		log.Errorln("Fail", err)
+	} else {
+		log.Println("Done!")
+	}
+	log.Println("\tPurge deleted accounts...")
+	delay := env.GetEnvIntOrDefault("ACCOUNT_PURGE_DELAY", defAccountPurgeDelay)
+	purgeBefore := time.Now().Add(-time.Duration(delay) * time.Second)
+	if n, err := account.PurgeDeletedAccounts(purgeBefore); err != nil {
+		log.Errorln("Fail", err)
+	} else {
+		log.Println("Done!", n, "purged")
+	}
 	log.Println("Finish it!")
 }
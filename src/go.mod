@@ -39,6 +39,7 @@ require (
 	github.com/iancoleman/strcase v0.0.0-20191112232945-16388991a334
 	github.com/jinzhu/gorm v1.9.10
 	github.com/kennygrant/sanitize v1.2.4 // indirect
+	github.com/lib/pq v1.2.0
 	github.com/mailru/easyjson v0.7.0 // indirect
 	github.com/micro/cli v0.2.0
 	github.com/micro/go-micro v1.16.0
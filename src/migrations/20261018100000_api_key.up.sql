@@ -0,0 +1,16 @@
+CREATE TABLE api_key
+(
+    id           SERIAL PRIMARY KEY,
+    account_id   INTEGER      NOT NULL REFERENCES account (id),
+    name         VARCHAR(255) NOT NULL,
+    prefix       VARCHAR(16)  NOT NULL,
+    key_hash     VARCHAR(64)  NOT NULL UNIQUE,
+    scopes       VARCHAR(64)[] NOT NULL DEFAULT '{}',
+    last_used_at TIMESTAMP WITH TIME ZONE,
+    expire_at    TIMESTAMP WITH TIME ZONE,
+    created_at   TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    updated_at   TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    deleted_at   TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX api_key_account_id_idx ON api_key (account_id);
@@ -0,0 +1,7 @@
+ALTER TABLE account_role
+    DROP CONSTRAINT account_role_role_fkey;
+
+DROP TABLE role_include;
+DROP TABLE role_permission;
+DROP TABLE permission;
+DROP TABLE role;
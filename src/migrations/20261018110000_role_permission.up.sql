@@ -0,0 +1,56 @@
+CREATE TABLE role
+(
+    id   SERIAL PRIMARY KEY,
+    name VARCHAR(64) NOT NULL UNIQUE
+);
+
+CREATE TABLE permission
+(
+    id          SERIAL PRIMARY KEY,
+    name        VARCHAR(64)  NOT NULL UNIQUE,
+    description VARCHAR(255) NOT NULL DEFAULT ''
+);
+
+CREATE TABLE role_permission
+(
+    role_id       INTEGER NOT NULL REFERENCES role (id) ON DELETE CASCADE,
+    permission_id INTEGER NOT NULL REFERENCES permission (id) ON DELETE CASCADE,
+    PRIMARY KEY (role_id, permission_id)
+);
+
+CREATE TABLE role_include
+(
+    role_id          INTEGER NOT NULL REFERENCES role (id) ON DELETE CASCADE,
+    included_role_id INTEGER NOT NULL REFERENCES role (id) ON DELETE CASCADE,
+    PRIMARY KEY (role_id, included_role_id),
+    CHECK (role_id <> included_role_id)
+);
+
+-- ids of seeded roles match AccRoleAdmin and AccRoleOper
+INSERT INTO role (id, name)
+VALUES (1, 'admin'),
+       (2, 'operator');
+SELECT setval('role_id_seq', (SELECT max(id) FROM role));
+
+INSERT INTO role_include (role_id, included_role_id)
+VALUES (1, 2);
+
+INSERT INTO permission (name, description)
+VALUES ('account:manage', 'Manage accounts and their roles'),
+       ('api-key:manage', 'Manage service accounts and api keys'),
+       ('repost:export', 'Export repost requests'),
+       ('proxy:write', 'Create, update and delete proxies'),
+       ('rule:write', 'Create, update and delete rules, triggers and actions');
+
+INSERT INTO role_permission (role_id, permission_id)
+SELECT 1, id
+FROM permission
+WHERE name IN ('account:manage', 'api-key:manage');
+
+INSERT INTO role_permission (role_id, permission_id)
+SELECT 2, id
+FROM permission
+WHERE name IN ('repost:export', 'proxy:write', 'rule:write');
+
+ALTER TABLE account_role
+    ADD CONSTRAINT account_role_role_fkey FOREIGN KEY (role) REFERENCES role (id) ON DELETE CASCADE;
@@ -0,0 +1,15 @@
+ALTER TABLE account
+    ADD COLUMN totp_secret    VARCHAR(64) NOT NULL DEFAULT '',
+    ADD COLUMN totp_enabled   BOOLEAN     NOT NULL DEFAULT FALSE,
+    ADD COLUMN totp_last_step BIGINT      NOT NULL DEFAULT 0;
+
+CREATE TABLE recovery_code
+(
+    id         SERIAL PRIMARY KEY,
+    account_id INTEGER     NOT NULL REFERENCES account (id) ON DELETE CASCADE,
+    code_hash  VARCHAR(64) NOT NULL,
+    used_at    TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+);
+
+CREATE INDEX recovery_code_account_id_idx ON recovery_code (account_id);
@@ -0,0 +1,15 @@
+DROP INDEX account_repost_request_organization_id_idx;
+ALTER TABLE account_repost_request
+    DROP COLUMN organization_id;
+
+DROP INDEX rss_links_organization_id_idx;
+ALTER TABLE rss_links
+    DROP COLUMN organization_id;
+
+DROP INDEX domains_organization_id_idx;
+ALTER TABLE domains
+    DROP COLUMN organization_id;
+
+DROP TABLE organization_rule;
+DROP TABLE organization_member;
+DROP TABLE organization;
@@ -0,0 +1,59 @@
+CREATE TABLE organization
+(
+    id         SERIAL PRIMARY KEY,
+    name       VARCHAR(255)             NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE TABLE organization_member
+(
+    organization_id INTEGER     NOT NULL REFERENCES organization (id) ON DELETE CASCADE,
+    account_id      INTEGER     NOT NULL REFERENCES account (id) ON DELETE CASCADE,
+    role            VARCHAR(16) NOT NULL DEFAULT 'member',
+    created_at      TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    PRIMARY KEY (organization_id, account_id)
+);
+
+CREATE INDEX organization_member_account_id_idx ON organization_member (account_id);
+
+-- rules are stored by proxy service, the table only assigns them to organizations
+CREATE TABLE organization_rule
+(
+    organization_id INTEGER      NOT NULL REFERENCES organization (id) ON DELETE CASCADE,
+    rule_id         INTEGER      NOT NULL UNIQUE,
+    host            VARCHAR(255) NOT NULL DEFAULT '',
+    PRIMARY KEY (organization_id, rule_id)
+);
+
+-- existing accounts and data are moved to the default organization
+INSERT INTO organization (id, name)
+VALUES (1, 'Default');
+SELECT setval('organization_id_seq', (SELECT max(id) FROM organization));
+
+INSERT INTO organization_member (organization_id, account_id, role)
+SELECT 1,
+       id,
+       CASE WHEN exists(SELECT 1 FROM account_role ar WHERE ar.account_id = account.id AND ar.role = 1)
+                THEN 'owner'
+            ELSE 'member' END
+FROM account;
+
+ALTER TABLE domains
+    ADD COLUMN organization_id INTEGER NOT NULL DEFAULT 1 REFERENCES organization (id);
+ALTER TABLE domains
+    ALTER COLUMN organization_id DROP DEFAULT;
+CREATE INDEX domains_organization_id_idx ON domains (organization_id);
+
+ALTER TABLE rss_links
+    ADD COLUMN organization_id INTEGER NOT NULL DEFAULT 1 REFERENCES organization (id);
+ALTER TABLE rss_links
+    ALTER COLUMN organization_id DROP DEFAULT;
+CREATE INDEX rss_links_organization_id_idx ON rss_links (organization_id);
+
+ALTER TABLE account_repost_request
+    ADD COLUMN organization_id INTEGER NOT NULL DEFAULT 1 REFERENCES organization (id);
+ALTER TABLE account_repost_request
+    ALTER COLUMN organization_id DROP DEFAULT;
+CREATE INDEX account_repost_request_organization_id_idx ON account_repost_request (organization_id);
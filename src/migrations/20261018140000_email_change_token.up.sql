@@ -0,0 +1,13 @@
+CREATE TABLE email_change_token
+(
+    id         SERIAL PRIMARY KEY,
+    account_id INTEGER      NOT NULL REFERENCES account (id) ON DELETE CASCADE,
+    email      VARCHAR(255) NOT NULL,
+    token      VARCHAR(255) NOT NULL UNIQUE,
+    is_used    BOOLEAN      NOT NULL DEFAULT FALSE,
+    expire_at  TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+);
+
+CREATE INDEX email_change_token_account_id_idx ON email_change_token (account_id);
@@ -0,0 +1,16 @@
+CREATE TABLE invite
+(
+    id              SERIAL PRIMARY KEY,
+    email           VARCHAR(255) NOT NULL,
+    token           VARCHAR(255) NOT NULL UNIQUE,
+    roles           INTEGER[]    NOT NULL DEFAULT '{}',
+    organization_id INTEGER REFERENCES organization (id) ON DELETE CASCADE,
+    org_role        VARCHAR(16)  NOT NULL DEFAULT '',
+    invited_by      INTEGER      NOT NULL REFERENCES account (id) ON DELETE CASCADE,
+    is_used         BOOLEAN      NOT NULL DEFAULT FALSE,
+    expire_at       TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at      TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    updated_at      TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+);
+
+CREATE INDEX invite_email_idx ON invite (email);
@@ -0,0 +1,20 @@
+-- plain tokens can't be restored, issued tokens are revoked
+DELETE FROM sign_up_token;
+ALTER TABLE sign_up_token
+    DROP COLUMN token_hash,
+    ADD COLUMN token VARCHAR(255) NOT NULL DEFAULT '';
+
+DELETE FROM recover_token;
+ALTER TABLE recover_token
+    DROP COLUMN token_hash,
+    ADD COLUMN token VARCHAR(255) NOT NULL DEFAULT '';
+
+DELETE FROM email_change_token;
+ALTER TABLE email_change_token
+    DROP COLUMN token_hash,
+    ADD COLUMN token VARCHAR(255) NOT NULL UNIQUE;
+
+DELETE FROM invite;
+ALTER TABLE invite
+    DROP COLUMN token_hash,
+    ADD COLUMN token VARCHAR(255) NOT NULL UNIQUE;
@@ -0,0 +1,36 @@
+-- tokens are stored by sha-256 hash only, issued tokens stay valid
+ALTER TABLE sign_up_token
+    ADD COLUMN token_hash VARCHAR(64);
+UPDATE sign_up_token
+SET token_hash = encode(sha256(token::bytea), 'hex');
+ALTER TABLE sign_up_token
+    ALTER COLUMN token_hash SET NOT NULL,
+    DROP COLUMN token;
+CREATE UNIQUE INDEX sign_up_token_token_hash_idx ON sign_up_token (token_hash);
+
+ALTER TABLE recover_token
+    ADD COLUMN token_hash VARCHAR(64);
+UPDATE recover_token
+SET token_hash = encode(sha256(token::bytea), 'hex');
+ALTER TABLE recover_token
+    ALTER COLUMN token_hash SET NOT NULL,
+    DROP COLUMN token;
+CREATE UNIQUE INDEX recover_token_token_hash_idx ON recover_token (token_hash);
+
+ALTER TABLE email_change_token
+    ADD COLUMN token_hash VARCHAR(64);
+UPDATE email_change_token
+SET token_hash = encode(sha256(token::bytea), 'hex');
+ALTER TABLE email_change_token
+    ALTER COLUMN token_hash SET NOT NULL,
+    DROP COLUMN token;
+CREATE UNIQUE INDEX email_change_token_token_hash_idx ON email_change_token (token_hash);
+
+ALTER TABLE invite
+    ADD COLUMN token_hash VARCHAR(64);
+UPDATE invite
+SET token_hash = encode(sha256(token::bytea), 'hex');
+ALTER TABLE invite
+    ALTER COLUMN token_hash SET NOT NULL,
+    DROP COLUMN token;
+CREATE UNIQUE INDEX invite_token_hash_idx ON invite (token_hash);
@@ -0,0 +1,13 @@
+CREATE TABLE account_identity
+(
+    id         SERIAL PRIMARY KEY,
+    account_id INTEGER      NOT NULL REFERENCES account (id) ON DELETE CASCADE,
+    issuer     VARCHAR(255) NOT NULL,
+    subject    VARCHAR(255) NOT NULL,
+    email      VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    UNIQUE (issuer, subject)
+);
+
+CREATE INDEX account_identity_account_id_idx ON account_identity (account_id);
@@ -0,0 +1,6 @@
+DELETE
+FROM permission
+WHERE name = 'audit:read';
+
+DROP TABLE audit_log;
+DROP FUNCTION audit_log_append_only();
@@ -0,0 +1,46 @@
+CREATE TABLE audit_log
+(
+    id              BIGSERIAL PRIMARY KEY,
+    actor_id        INTEGER,
+    organization_id INTEGER,
+    action          VARCHAR(64)  NOT NULL,
+    target_type     VARCHAR(32)  NOT NULL DEFAULT '',
+    target_id       VARCHAR(64)  NOT NULL DEFAULT '',
+    ip              VARCHAR(64)  NOT NULL DEFAULT '',
+    user_agent      VARCHAR(512) NOT NULL DEFAULT '',
+    details         JSONB,
+    created_at      TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+);
+
+CREATE INDEX audit_log_created_at_idx ON audit_log (created_at);
+CREATE INDEX audit_log_actor_id_idx ON audit_log (actor_id, created_at);
+CREATE INDEX audit_log_action_idx ON audit_log (action, created_at);
+CREATE INDEX audit_log_target_idx ON audit_log (target_type, target_id);
+
+-- the log is append-only, entries outlive accounts so there is no foreign key to account
+CREATE FUNCTION audit_log_append_only() RETURNS TRIGGER AS
+$$
+BEGIN
+    RAISE EXCEPTION 'audit_log is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_log_no_update
+    BEFORE UPDATE OR DELETE
+    ON audit_log
+    FOR EACH ROW
+EXECUTE PROCEDURE audit_log_append_only();
+
+CREATE TRIGGER audit_log_no_truncate
+    BEFORE TRUNCATE
+    ON audit_log
+    FOR EACH STATEMENT
+EXECUTE PROCEDURE audit_log_append_only();
+
+INSERT INTO permission (name, description)
+VALUES ('audit:read', 'Read audit log');
+
+INSERT INTO role_permission (role_id, permission_id)
+SELECT 1, id
+FROM permission
+WHERE name = 'audit:read';
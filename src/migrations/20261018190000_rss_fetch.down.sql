@@ -0,0 +1,4 @@
+ALTER TABLE rss_links
+    DROP COLUMN error,
+    DROP COLUMN item_count,
+    DROP COLUMN fetched_at;
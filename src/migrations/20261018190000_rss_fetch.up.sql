@@ -0,0 +1,4 @@
+ALTER TABLE rss_links
+    ADD COLUMN fetched_at TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN item_count INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN error      TEXT;
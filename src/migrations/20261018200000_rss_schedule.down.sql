@@ -0,0 +1,7 @@
+DROP INDEX rss_links_next_fetch_at_idx;
+ALTER TABLE rss_links
+    DROP COLUMN next_fetch_at,
+    DROP COLUMN failure_count,
+    DROP COLUMN avg_item_gap,
+    DROP COLUMN last_modified,
+    DROP COLUMN etag;
@@ -0,0 +1,8 @@
+ALTER TABLE rss_links
+    ADD COLUMN etag          VARCHAR(255) NOT NULL DEFAULT '',
+    ADD COLUMN last_modified VARCHAR(64)  NOT NULL DEFAULT '',
+    ADD COLUMN avg_item_gap  BIGINT       NOT NULL DEFAULT 0,
+    ADD COLUMN failure_count INTEGER      NOT NULL DEFAULT 0,
+    ADD COLUMN next_fetch_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX rss_links_next_fetch_at_idx ON rss_links (next_fetch_at);
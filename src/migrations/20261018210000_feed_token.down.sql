@@ -0,0 +1,2 @@
+ALTER TABLE account
+    DROP COLUMN feed_version;
@@ -0,0 +1,2 @@
+ALTER TABLE account
+    ADD COLUMN feed_version INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,2 @@
+DROP TABLE organization_action;
+DROP TABLE organization_trigger;
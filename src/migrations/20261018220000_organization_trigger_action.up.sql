@@ -0,0 +1,13 @@
+CREATE TABLE organization_trigger
+(
+    organization_id INTEGER NOT NULL REFERENCES organization (id) ON DELETE CASCADE,
+    trigger_id      INTEGER NOT NULL UNIQUE,
+    PRIMARY KEY (organization_id, trigger_id)
+);
+
+CREATE TABLE organization_action
+(
+    organization_id INTEGER NOT NULL REFERENCES organization (id) ON DELETE CASCADE,
+    action_id       INTEGER NOT NULL UNIQUE,
+    PRIMARY KEY (organization_id, action_id)
+);
@@ -2,9 +2,13 @@ package account
 
 import (
 	"errors"
+	"oko/pkg/cfg"
 	"oko/pkg/db"
+	"oko/pkg/mail"
+	"oko/pkg/rndstr"
 	"time"
 
+	"github.com/jinzhu/gorm"
 	"golang.org/x/crypto/bcrypt"
 )
 
@@ -21,6 +25,10 @@ type Account struct {
 	Email        string        `json:"email"`
 	PasswordHash string        `json:"password_hash"`
 	Status       uint          `json:"status"`
+	TotpSecret   string        `json:"-"`
+	TotpEnabled  bool          `json:"totp_enabled"`
+	TotpLastStep int64         `json:"-"`
+	FeedVersion  int           `json:"-"`
 	CreatedAt    time.Time     `json:"created_at"`
 	UpdatedAt    time.Time     `json:"updated_at"`
 	DeletedAt    *time.Time    `json:"deleted_at"`
@@ -47,6 +55,38 @@ func (a *Account) CheckPassword(password string) error {
 	return bcrypt.CompareHashAndPassword(byteHashedPassword, bytePassword)
 }
 
+func changePassword(dbt *gorm.DB, acc *Account, password string) error {
+	if err := acc.SetPassword(password); err != nil {
+		return err
+	}
+	return dbt.Model(acc).Update("password_hash", acc.PasswordHash).Error
+}
+
+// ForceResetPassword replaces account password with random one, revokes all account sessions
+// and sends recover token, so the owner has to set new password.
+func ForceResetPassword(acc Account) error {
+	rt := NewRecoverToken(acc.ID)
+	dbt := db.GetDB().Begin()
+	if err := changePassword(dbt, &acc, rndstr.RandString(cfg.App.AuthTokenLength)); err != nil {
+		dbt.Rollback()
+		return err
+	}
+	if err := dbt.Create(&rt).Error; err != nil {
+		dbt.Rollback()
+		return err
+	}
+	if err := dbt.Commit().Error; err != nil {
+		return err
+	}
+
+	if err := DropAccountTokens(acc.ID); err != nil {
+		return err
+	}
+
+	rt.Account = acc
+	return rt.SendRecoverToken(acc.Email)
+}
+
 func FindAccount(condition interface{}) (Account, error) {
 	var model Account
 	err := db.GetDB().Where(condition).First(&model).Error
@@ -63,6 +103,10 @@ func (a Account) GetStrStatus() string {
 }
 
 func (a Account) SendSignUpConfirmed() error {
-	// TODO: Send message
-	return nil
+	return mail.Send(mail.Message{
+		To:       a.Email,
+		Subject:  SignUpConfirmedSubject,
+		Template: SignUpConfirmedTmpl,
+		Data:     map[string]interface{}{"Name": a.Name},
+	})
 }
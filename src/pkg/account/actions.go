@@ -2,20 +2,21 @@ package account
 
 import (
 	"net/http"
+	"oko/pkg/audit"
 	"oko/pkg/cfg"
 	"oko/pkg/db"
 	"oko/pkg/e"
 	"oko/pkg/ginapp/types"
 	"oko/pkg/log"
 	"strings"
-	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
 // SignUp godoc
 // @Summary Sign up
-// @Description Sing up
+// @Description Sing up, account signed up with invite is active at once and gets roles of the invite.
+// @Description Without open sign up the invite is required.
 // @ID post-account-sign-up
 // @Tags Account
 // @Accept json
@@ -23,6 +24,7 @@ import (
 // @Param object body account.SignUpForm true "Sign up fields"
 // @Success 200 {object} types.StdResponse
 // @Failure 400 {object} types.ResponseErrorSwg
+// @Failure 403 {object} types.ResponseErrorSwg
 // @Failure 500 {object} types.ResponseErrorSwg
 // @Router /account/sign-up [post]
 func SignUp(c *gin.Context) {
@@ -32,39 +34,87 @@ func SignUp(c *gin.Context) {
 		e.ErrorResponse(c, http.StatusBadRequest, err)
 		return
 	}
-
-	dbt := db.GetDB().Begin()
+	if !cfg.App.OpenSignUp && form.Invite == "" {
+		e.ErrorResponse(c, http.StatusForbidden, "Sign up is available by invite only")
+		return
+	}
 
 	acc := Account{
 		Email:  strings.ToLower(form.Email),
 		Name:   form.Name,
 		Status: AccStatusUnconfirmed,
 	}
+
+	var inv *Invite
+	if form.Invite != "" {
+		var err error
+		if inv, err = GetByInviteToken(form.Invite); err != nil || inv.Email != acc.Email {
+			e.ErrorResponse(c, http.StatusBadRequest, e.CustomFieldError{
+				Name:    "invite",
+				Tag:     "Invite",
+				Param:   "",
+				Value:   form.Invite,
+				Message: "Invite is issued for another e-mail",
+			})
+			return
+		}
+		// the invite confirms the e-mail
+		acc.Status = AccStatusActive
+	}
+
+	dbt := db.GetDB().Begin()
+
 	if err := acc.SetPassword(form.Password); err != nil {
+		dbt.Rollback()
 		e.ErrorResponse(c, http.StatusBadRequest, err)
 		return
 	}
 	if err := dbt.Create(&acc).Error; err != nil {
+		dbt.Rollback()
 		panic(err)
 	}
+	// invited accounts join the organization of the invite instead
+	if inv == nil || inv.OrganizationID == nil {
+		if err := createPersonalOrganization(dbt, acc); err != nil {
+			dbt.Rollback()
+			panic(err)
+		}
+	}
 
-	token := GenerateSignUpToken(acc.ID)
-	sut := SignUpToken{
-		AccountID: acc.ID,
-		Token:     token,
-		ExpireAt:  time.Now().Add(time.Second * time.Duration(cfg.App.SignUpTokenLifetime)),
+	var sut SignUpToken
+	if inv != nil {
+		if err := inv.Use(dbt); err != nil {
+			dbt.Rollback()
+			e.ErrorResponse(c, http.StatusBadRequest, "Invite already used")
+			return
+		}
+		if err := inv.Accept(dbt, acc.ID); err != nil {
+			dbt.Rollback()
+			panic(err)
+		}
+	} else {
+		sut = NewSignUpToken(acc.ID)
+		if err := dbt.Create(&sut).Error; err != nil {
+			dbt.Rollback()
+			panic(err)
+		}
 	}
-	if err := dbt.Create(&sut).Error; err != nil {
-		dbt.Rollback()
+
+	if err := dbt.Commit().Error; err != nil {
 		panic(err)
 	}
 
-	if err := acc.SendSignUpConfirmed(); err != nil {
-		log.Println("Fail to send registration confirmation", err)
+	if inv != nil {
+		if err := acc.SendSignUpConfirmed(); err != nil {
+			log.Println("Fail to send sign up confirmed notice", err)
+		}
+	} else {
+		sut.Account = acc
+		if err := sut.SendToken(acc.Email); err != nil {
+			log.Println("Fail to send registration confirmation", err)
+		}
 	}
 
-	dbt.Commit()
-
 	c.JSON(
 		http.StatusCreated,
 		types.StdResponse{
@@ -74,9 +124,132 @@ func SignUp(c *gin.Context) {
 	)
 }
 
+// ConfirmSignUp godoc
+// @Summary Confirm sign up
+// @Description Confirm e-mail by sign up token and activate account
+// @ID post-account-confirm-sign-up
+// @Tags Account
+// @Accept json
+// @Produce json
+// @Param object body account.ConfirmSignUpForm true "Confirm sign up fields"
+// @Success 200 {object} types.ResponseSignIn
+// @Failure 400 {object} types.ResponseErrorSwg
+// @Failure 500 {object} types.ResponseErrorSwg
+// @Router /account/confirm-sign-up [post]
+func ConfirmSignUp(c *gin.Context) {
+	var form ConfirmSignUpForm
+
+	if err := c.ShouldBind(&form); err != nil {
+		e.ErrorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	sut, err := GetBySignUpToken(form.Token)
+	if err != nil {
+		e.ErrorResponse(c, http.StatusBadRequest, "Invalid sign up token")
+		return
+	}
+	acc := sut.Account
+
+	dbt := db.GetDB().Begin()
+	if err := sut.Use(dbt); err != nil {
+		dbt.Rollback()
+		e.ErrorResponse(c, http.StatusBadRequest, "Invalid sign up token")
+		return
+	}
+	// banned account keeps its status, only unconfirmed one is activated
+	res := dbt.Model(&acc).Where("status = ?", AccStatusUnconfirmed).Update("status", AccStatusActive)
+	if res.Error != nil {
+		dbt.Rollback()
+		panic(res.Error)
+	}
+	if res.RowsAffected == 0 {
+		dbt.Rollback()
+		e.ErrorResponse(c, http.StatusBadRequest, "Invalid sign up token")
+		return
+	}
+	if err := dbt.Commit().Error; err != nil {
+		panic(err)
+	}
+
+	if err := acc.SendSignUpConfirmed(); err != nil {
+		log.Println("Fail to send sign up confirmed notice", err)
+	}
+
+	var data types.ResponseAccessToken
+	if form.SignIn {
+		if data, err = IssueTokens(acc, c); err != nil {
+			panic(err)
+		}
+	}
+
+	c.JSON(
+		http.StatusOK,
+		types.ResponseSignIn{
+			StdResponse: types.StdResponse{
+				Status:  e.Success,
+				Message: e.GetMsg(e.Success),
+			},
+			Data: data,
+		},
+	)
+}
+
+// ResendConfirmation godoc
+// @Summary Resend sign up confirmation
+// @Description Send new sign up token to unconfirmed account, could be called once per SIGN_UP_RESEND_INTERVAL
+// @ID post-account-resend-confirmation
+// @Tags Account
+// @Accept json
+// @Produce json
+// @Param object body account.ResendConfirmationForm true "Resend confirmation fields"
+// @Success 200 {object} types.StdResponse
+// @Failure 400 {object} types.ResponseErrorSwg
+// @Failure 429 {object} types.ResponseErrorSwg
+// @Failure 500 {object} types.ResponseErrorSwg
+// @Router /account/resend-confirmation [post]
+func ResendConfirmation(c *gin.Context) {
+	var form ResendConfirmationForm
+
+	if err := c.ShouldBind(&form); err != nil {
+		e.ErrorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+	email := strings.ToLower(form.Email)
+
+	if !throttleEmail(c, resendThrottleKey, email, "Confirmation was sent recently, try again later") {
+		return
+	}
+
+	acc, err := FindAccount(Account{Email: email, Status: AccStatusUnconfirmed})
+	if err == nil && acc.ID != 0 {
+		dbt := db.GetDB().Begin()
+		if err := DropAccountSignUpTokens(dbt, acc.ID); err != nil {
+			dbt.Rollback()
+			panic(err)
+		}
+		sut := NewSignUpToken(acc.ID)
+		if err := dbt.Create(&sut).Error; err != nil {
+			dbt.Rollback()
+			panic(err)
+		}
+		if err := dbt.Commit().Error; err != nil {
+			panic(err)
+		}
+
+		sut.Account = acc
+		if err := sut.SendToken(acc.Email); err != nil {
+			log.Println("Fail to resend registration confirmation", err)
+		}
+	}
+
+	// The response doesn't depend on the account existence, so e-mails can't be enumerated
+	types.SuccessEmptyResponse(c)
+}
+
 // SignIn godoc
 // @Summary Sign in
-// @Description Sing in
+// @Description Sing in, if two-factor authentication is enabled challenge token is returned instead of access token
 // @ID post-account-sign-in
 // @Tags Account
 // @Accept json
@@ -84,6 +257,7 @@ func SignUp(c *gin.Context) {
 // @Param object body account.SignInForm true "Sign up fields"
 // @Success 200 {object} types.ResponseSignIn
 // @Failure 401 {object} types.ResponseErrorSwg
+// @Failure 429 {object} types.ResponseErrorSwg
 // @Router /account/sign-in [post]
 func SignIn(c *gin.Context) {
 	var form SignInForm
@@ -92,24 +266,47 @@ func SignIn(c *gin.Context) {
 		e.ErrorResponse(c, http.StatusBadRequest, err)
 		return
 	}
-	acc, err := FindAccount(Account{Email: form.Email})
-	if err != nil {
-		loginErrorResponse(c, form.Email)
+	if retryAfter := signInRetryAfter(form.Email, c.ClientIP()); retryAfter > 0 {
+		lockedResponse(c, retryAfter)
 		return
 	}
-	if acc.ID == 0 {
+	acc, err := FindAccount(Account{Email: form.Email})
+	if err == nil && acc.ID != 0 {
+		err = acc.CheckPassword(form.Password)
+	}
+	if err != nil || acc.ID == 0 {
+		auditSignInFailed(c, acc.ID, form.Email, signInMethodPassword)
+		if lockout := registerSignInFailure(form.Email, c.ClientIP()); lockout > 0 {
+			lockedResponse(c, lockout)
+			return
+		}
 		loginErrorResponse(c, form.Email)
 		return
 	}
-	err = acc.CheckPassword(form.Password)
-	if err != nil {
-		loginErrorResponse(c, form.Email)
+	resetSignInFailures(form.Email)
+
+	signInResponse(c, acc, signInMethodPassword)
+}
+
+// signInResponse issues tokens of authenticated account or the challenge of two-factor sign in.
+func signInResponse(c *gin.Context, acc Account, method string) {
+	if acc.TotpEnabled {
+		challenge, err := newSignInChallenge(acc.ID)
+		if err != nil {
+			panic(err)
+		}
+		types.SuccessResponse(c, types.SignInChallenge{
+			ChallengeToken: challenge,
+			ExpiresIn:      cfg.App.ChallengeLifetime,
+		})
 		return
 	}
-	token, err := SetToken(acc.ID)
+
+	tokens, err := IssueTokens(acc, c)
 	if err != nil {
 		panic(err)
 	}
+	auditSignIn(c, acc.ID, method)
 
 	c.JSON(
 		http.StatusOK,
@@ -118,7 +315,7 @@ func SignIn(c *gin.Context) {
 				Status:  e.Success,
 				Message: e.GetMsg(e.Success),
 			},
-			Data: types.ResponseAccessToken{AccessToken: token},
+			Data: tokens,
 		},
 	)
 }
@@ -140,6 +337,9 @@ func SignOut(c *gin.Context) {
 		e.ErrorResponse(c, http.StatusInternalServerError, err)
 		return
 	}
+	if accID := GetContextAccID(c); accID != 0 {
+		audit.Record(c, audit.Event{Action: audit.AccountSignOut, TargetType: audit.TargetAccount, TargetID: accID})
+	}
 	c.JSON(http.StatusOK, types.StdResponse{Status: http.StatusOK, Message: e.GetMsg(http.StatusOK)})
 }
 
@@ -160,15 +360,7 @@ func Profile(c *gin.Context) {
 		e.ErrorResponse(c, http.StatusBadRequest, "Something went wrong")
 		return
 	}
-	profile := types.Profile{
-		ID:        acc.ID,
-		Email:     acc.Email,
-		Name:      acc.Name,
-		Status:    acc.Status,
-		StatusStr: acc.GetStrStatus(),
-		CreatedAt: acc.CreatedAt,
-		UpdatedAt: acc.UpdatedAt,
-	}
+	profile := toProfileView(acc)
 	c.JSON(
 		http.StatusOK,
 		gin.H{
@@ -0,0 +1,203 @@
+package account
+
+import (
+	"net/http"
+	"oko/pkg/db"
+	"oko/pkg/e"
+	"oko/pkg/ginapp/types"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+func toAPIKeyView(ak APIKey) types.APIKey {
+	return types.APIKey{
+		ID:         ak.ID,
+		AccountID:  ak.AccountID,
+		Name:       ak.Name,
+		Prefix:     ak.Prefix,
+		Scopes:     ak.Scopes,
+		LastUsedAt: ak.LastUsedAt,
+		ExpireAt:   ak.ExpireAt,
+		CreatedAt:  ak.CreatedAt,
+	}
+}
+
+// CreateServiceAccount godoc
+// @Summary Create service account
+// @Description Create service account, it can't sign in and is authenticated with api keys only.
+// @Description The account joins active organization of the creator as member.
+// @ID post-account-service-account
+// @Tags Account
+// @Accept json
+// @Produce json
+// @Param object body account.ServiceAccountForm true "Service account fields"
+// @Success 200 {object} types.ResponseProfile
+// @Failure 400 {object} types.ResponseErrorSwg
+// @Failure 403 {object} types.ResponseErrorSwg
+// @Failure 500 {object} types.ResponseErrorSwg
+// @Router /account/service-accounts [post]
+// @Security ApiKeyAuth
+func CreateServiceAccount(c *gin.Context) {
+	var form ServiceAccountForm
+
+	if err := c.ShouldBind(&form); err != nil {
+		e.ErrorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	orgID, err := ActiveOrganizationID(c)
+	if err != nil {
+		e.ErrorResponse(c, http.StatusForbidden, "You are not a member of the organization")
+		return
+	}
+
+	acc := Account{
+		Email:  strings.ToLower(form.Email),
+		Name:   form.Name,
+		Status: AccStatusService,
+	}
+	dbt := db.GetDB().Begin()
+	if err := dbt.Create(&acc).Error; err != nil {
+		dbt.Rollback()
+		panic(err)
+	}
+	if err := addMember(dbt, orgID, acc.ID, orgRoleMember); err != nil {
+		dbt.Rollback()
+		panic(err)
+	}
+	if err := dbt.Commit().Error; err != nil {
+		panic(err)
+	}
+
+	types.SuccessResponse(c, types.Profile{
+		ID:        acc.ID,
+		Email:     acc.Email,
+		Name:      acc.Name,
+		Status:    acc.Status,
+		StatusStr: acc.GetStrStatus(),
+		CreatedAt: acc.CreatedAt,
+		UpdatedAt: acc.UpdatedAt,
+	})
+}
+
+// APIKeys godoc
+// @Summary List api keys
+// @Description List api keys of service account
+// @ID get-account-api-keys
+// @Tags Account
+// @Accept json
+// @Produce json
+// @Param object query account.APIKeyListForm true "Api keys list request"
+// @Success 200 {object} types.ResponseAPIKeys
+// @Failure 400 {object} types.ResponseErrorSwg
+// @Failure 500 {object} types.ResponseErrorSwg
+// @Router /account/api-keys [get]
+// @Security ApiKeyAuth
+func APIKeys(c *gin.Context) {
+	var form APIKeyListForm
+
+	if err := c.ShouldBindQuery(&form); err != nil {
+		e.ErrorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	keys, err := ListAPIKeys(form.AccountID)
+	if err != nil {
+		e.ErrorResponse(c, http.StatusInternalServerError, "Something went wrong")
+		return
+	}
+
+	data := make([]types.APIKey, 0, len(keys))
+	for _, ak := range keys {
+		data = append(data, toAPIKeyView(ak))
+	}
+	types.SuccessResponse(c, data)
+}
+
+// CreateAPIKey godoc
+// @Summary Create api key
+// @Description Create api key for service account, the key is shown only once.
+// @Description Scopes are names like rss:read or rule:write, unknown scopes are rejected
+// @ID post-account-api-keys
+// @Tags Account
+// @Accept json
+// @Produce json
+// @Param object body account.APIKeyCreateForm true "Api key fields"
+// @Success 200 {object} types.ResponseAPIKeyCreated
+// @Failure 400 {object} types.ResponseErrorSwg
+// @Failure 500 {object} types.ResponseErrorSwg
+// @Router /account/api-keys [post]
+// @Security ApiKeyAuth
+func CreateAPIKey(c *gin.Context) {
+	var form APIKeyCreateForm
+
+	if err := c.ShouldBind(&form); err != nil {
+		e.ErrorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	acc, err := FindAccount(Account{ID: form.AccountID})
+	if err != nil || acc.Status != AccStatusService {
+		e.ErrorResponse(c, http.StatusBadRequest, e.CustomFieldError{
+			Name:    "account_id",
+			Tag:     "account_id",
+			Param:   "",
+			Value:   form.AccountID,
+			Message: "Service account not found",
+		})
+		return
+	}
+
+	key, prefix := GenerateAPIKey()
+	ak := APIKey{
+		AccountID: acc.ID,
+		Name:      form.Name,
+		Prefix:    prefix,
+		KeyHash:   HashAPIKey(key),
+		Scopes:    form.Scopes,
+		ExpireAt:  form.ExpireAt,
+	}
+	if err := db.GetDB().Create(&ak).Error; err != nil {
+		panic(err)
+	}
+
+	types.SuccessResponse(c, types.APIKeyCreated{
+		APIKey: toAPIKeyView(ak),
+		Key:    key,
+	})
+}
+
+// DropAPIKey godoc
+// @Summary Revoke api key
+// @Description Revoke api key
+// @ID delete-account-api-key
+// @Tags Account
+// @Accept json
+// @Produce json
+// @Param id path int true "Api key ID"
+// @Success 200 {object} types.StdResponse
+// @Failure 400 {object} types.ResponseErrorSwg
+// @Failure 404 {object} types.ResponseErrorSwg
+// @Router /account/api-keys/{id} [delete]
+// @Security ApiKeyAuth
+func DropAPIKey(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		e.ErrorResponse(c, http.StatusBadRequest, "Something went wrong")
+		return
+	}
+
+	res := db.GetDB().Delete(&APIKey{ID: id})
+	if res.Error != nil {
+		e.ErrorResponse(c, http.StatusInternalServerError, "Something went wrong")
+		return
+	}
+	if res.RowsAffected == 0 {
+		e.ErrorResponse(c, http.StatusNotFound, "Api key not found")
+		return
+	}
+
+	types.SuccessEmptyResponse(c)
+}
@@ -0,0 +1,105 @@
+package account
+
+import (
+	"oko/pkg/cfg"
+	"oko/pkg/db"
+	"oko/pkg/rndstr"
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"github.com/lib/pq"
+)
+
+const (
+	apiKeyPrefixLength = 8
+	apiKeyTouchPeriod  = time.Minute
+)
+
+// APIKeyScopes are scopes acts require from api keys, keys are created with these scopes only.
+var APIKeyScopes = map[string]bool{
+	ScopeRepostRead:   true,
+	ScopeRepostWrite:  true,
+	ScopeRepostExport: true,
+	ScopeRssRead:      true,
+	ScopeRssWrite:     true,
+	ScopeProxyRead:    true,
+	ScopeProxyWrite:   true,
+	ScopeRuleRead:     true,
+	ScopeRuleWrite:    true,
+	ScopeDomainRead:   true,
+	ScopeDomainWrite:  true,
+	ScopeLinkRead:     true,
+	ScopeProfileRead:  true,
+}
+
+// APIKey is a long-lived application key of service account, only hash of the key is stored.
+type APIKey struct {
+	ID         int            `json:"id"`
+	AccountID  int            `json:"account_id"`
+	Name       string         `json:"name"`
+	Prefix     string         `json:"prefix"`
+	KeyHash    string         `json:"-"`
+	Scopes     pq.StringArray `json:"scopes" gorm:"type:varchar(64)[]"`
+	LastUsedAt *time.Time     `json:"last_used_at"`
+	ExpireAt   *time.Time     `json:"expire_at"`
+	CreatedAt  time.Time      `json:"created_at"`
+	UpdatedAt  time.Time      `json:"updated_at"`
+	DeletedAt  *time.Time     `json:"deleted_at"`
+	Account    Account        `json:"-" gorm:"foreignkey:AccountID"`
+}
+
+func (APIKey) TableName() string {
+	return "api_key"
+}
+
+// GenerateAPIKey returns new key and its public prefix, e.g. oko_live_ak_<prefix>.<random>.
+func GenerateAPIKey() (key, prefix string) {
+	prefix = rndstr.RandString(apiKeyPrefixLength)
+	key = cfg.App.TokenPrefix + tokenKindAPIKey + "_" + prefix + "." + rndstr.RandString(cfg.App.AuthTokenLength)
+	return key, prefix
+}
+
+func HashAPIKey(key string) string {
+	return hashToken(key)
+}
+
+// GetByAPIKey returns not expired key with service account preloaded.
+func GetByAPIKey(key string) (*APIKey, error) {
+	ak := new(APIKey)
+	if err := db.GetDB().
+		Preload("Account").
+		Preload("Account.Roles").
+		Where("key_hash = ? and (expire_at is null or expire_at >= now())", HashAPIKey(key)).
+		First(ak).Error; err != nil {
+		return nil, err
+	}
+	if !matchToken(key, ak.KeyHash) {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return ak, nil
+}
+
+func ListAPIKeys(accID int) ([]APIKey, error) {
+	var keys []APIKey
+	err := db.GetDB().Where("account_id = ?", accID).Order("created_at").Find(&keys).Error
+	return keys, err
+}
+
+func (ak APIKey) HasScope(scope string) bool {
+	for _, s := range ak.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// Touch updates last usage time, it's written at most once per apiKeyTouchPeriod.
+func (ak *APIKey) Touch() error {
+	now := time.Now()
+	if ak.LastUsedAt != nil && now.Sub(*ak.LastUsedAt) < apiKeyTouchPeriod {
+		return nil
+	}
+	ak.LastUsedAt = &now
+	return db.GetDB().Model(ak).UpdateColumn("last_used_at", now).Error
+}
@@ -1,24 +1,32 @@
 package account
 
 import (
-	"fmt"
+	"oko/pkg/audit"
 	"oko/pkg/cfg"
 	"oko/pkg/db"
 	"oko/pkg/e"
+	"oko/pkg/ginapp/controller"
+	"oko/pkg/ginapp/types"
+	"oko/pkg/log"
 	"oko/pkg/redis"
-	"oko/pkg/rndstr"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
-func GenerateToken(accID int) string {
-	return fmt.Sprintf("%d-%s", accID, rndstr.RandString(cfg.App.AuthTokenLength))
+func GenerateToken() string {
+	return newToken(tokenKindAuth)
+}
+
+// authKey returns redis key of the token, tokens are stored by hash only.
+func authKey(tokenHash string) string {
+	return authTokenKey + tokenHash
 }
 
 func GetUserID(token string) (int, error) {
 	var intID int
-	strID, err := redis.Get(token)
+	strID, err := redis.Get(authKey(hashToken(token)))
 	if err != nil || len(strID) == 0 {
 		return 0, err
 	}
@@ -29,23 +37,121 @@ func GetUserID(token string) (int, error) {
 	return intID, nil
 }
 
-func SetToken(accID int) (string, error) {
-	token := GenerateToken(accID)
-	err := redis.SetEx(token, []byte(strconv.Itoa(accID)), int32(cfg.App.AuthTokenLifetime))
+// IssueTokens starts new session of the account, in jwt mode access token is returned
+// with refresh token.
+func IssueTokens(acc Account, c *gin.Context) (types.ResponseAccessToken, error) {
+	if isJWTMode() {
+		return startTokenFamily(acc, c)
+	}
+	token, err := SetToken(acc.ID, c)
+	return types.ResponseAccessToken{AccessToken: token}, err
+}
+
+// SetToken issues new auth token and registers the session of the request owner.
+func SetToken(accID int, c *gin.Context) (string, error) {
+	token := GenerateToken()
+	hash := hashToken(token)
+	err := redis.SetEx(authKey(hash), []byte(strconv.Itoa(accID)), int32(cfg.App.AuthTokenLifetime))
+	if err != nil {
+		return "", err
+	}
+	now := time.Now()
+	err = redis.SaveSession(redis.Session{
+		ID:         redis.SessionID(hash),
+		TokenHash:  hash,
+		AccountID:  accID,
+		CreatedAt:  now,
+		LastSeenAt: now,
+		IP:         c.ClientIP(),
+		UserAgent:  c.Request.UserAgent(),
+	}, int32(cfg.App.AuthTokenLifetime))
 	if err != nil {
 		return "", err
 	}
 	return token, nil
 }
 
-func RefreshToken(accID int, token string) bool {
-	err := redis.SetEx(token, []byte(strconv.Itoa(accID)), int32(cfg.App.AuthTokenLifetime))
-	return err != nil
+func RefreshToken(accID int, token string, c *gin.Context) bool {
+	hash := hashToken(token)
+	err := redis.SetEx(authKey(hash), []byte(strconv.Itoa(accID)), int32(cfg.App.AuthTokenLifetime))
+	if err != nil {
+		return false
+	}
+	s, err := redis.GetSession(accID, redis.SessionID(hash))
+	if err != nil {
+		return false
+	}
+	if s == nil {
+		s = &redis.Session{
+			ID:        redis.SessionID(hash),
+			TokenHash: hash,
+			AccountID: accID,
+			CreatedAt: time.Now(),
+		}
+	}
+	s.LastSeenAt = time.Now()
+	s.IP = c.ClientIP()
+	s.UserAgent = c.Request.UserAgent()
+	return redis.SaveSession(*s, int32(cfg.App.AuthTokenLifetime)) == nil
 }
 
 func DropToken(token string) error {
-	err := redis.Delete(token)
-	return err
+	return dropTokenHash(hashToken(token))
+}
+
+func dropTokenHash(hash string) error {
+	var accID int
+	strID, err := redis.Get(authKey(hash))
+	if err != nil {
+		return err
+	}
+	if len(strID) > 0 {
+		if accID, err = strconv.Atoi(string(strID)); err != nil {
+			return err
+		}
+	}
+	if err = redis.Delete(authKey(hash)); err != nil {
+		return err
+	}
+	if accID != 0 {
+		return redis.DeleteSession(accID, redis.SessionID(hash))
+	}
+	return nil
+}
+
+// dropSession revokes token of the session or refresh token family of jwt mode.
+func dropSession(s redis.Session) error {
+	if s.Family != "" {
+		return revokeTokenFamily(s.AccountID, s.Family)
+	}
+	return dropTokenHash(s.TokenHash)
+}
+
+// sessionLifetime is the period the session is kept without activity.
+func sessionLifetime() int {
+	if isJWTMode() {
+		return cfg.App.RefreshTokenLifetime
+	}
+	return cfg.App.AuthTokenLifetime
+}
+
+// DropAccountTokens revokes all auth tokens of the account.
+func DropAccountTokens(accID int) error {
+	sessions, err := redis.GetSessions(accID, sessionLifetime())
+	if err != nil {
+		return err
+	}
+	for _, s := range sessions {
+		if s.Family != "" {
+			err = redis.Delete(refreshFamilyKey + s.Family)
+		} else {
+			err = redis.Delete(authKey(s.TokenHash))
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return redis.DeleteSessions(accID)
 }
 
 func SetContextAccount(c *gin.Context, acc Account) {
@@ -53,13 +159,30 @@ func SetContextAccount(c *gin.Context, acc Account) {
 	c.Set("account_model", acc)
 }
 
-//nolint
+// GetContextAccID returns id of the authenticated account without loading it.
+func GetContextAccID(c *gin.Context) int {
+	return c.GetInt("account_id")
+}
+
+// GetContextAcc returns the authenticated account, account restored from access token claims
+// is loaded on first use.
 func GetContextAcc(c *gin.Context) Account {
-	if acc, ok := c.Get("account_model"); !ok {
+	val, ok := c.Get("account_model")
+	if !ok {
 		return Account{}
-	} else {
-		return acc.(Account)
 	}
+	acc := val.(Account)
+	if c.GetBool("account_partial") {
+		var full Account
+		if err := db.GetDB().Preload("Roles").First(&full, acc.ID).Error; err != nil {
+			log.Println("Fail to load account", err)
+			return acc
+		}
+		SetContextAccount(c, full)
+		c.Set("account_partial", false)
+		return full
+	}
+	return acc
 }
 
 func Auth(required bool, roles []int) gin.HandlerFunc {
@@ -70,8 +193,13 @@ func Auth(required bool, roles []int) gin.HandlerFunc {
 		code = e.Success
 
 		token := c.GetHeader(cfg.App.AuthTokenKey)
-		if token == "" {
+		appKey := c.GetHeader(cfg.App.APIKeyKey)
+		if token == "" && appKey != "" {
+			acc, code = authByAPIKey(c, appKey)
+		} else if token == "" {
 			code = e.ErrorAuthToken
+		} else if isJWTMode() {
+			acc, code = authByAccessToken(c, token)
 		} else {
 			accID, err := GetUserID(token)
 			if err != nil {
@@ -100,29 +228,124 @@ func Auth(required bool, roles []int) gin.HandlerFunc {
 			}
 		}
 
+		act, _ := controller.GetAct(c)
+		if code == e.Success && act.Permission != "" && !acc.HasPermission(act.Permission) {
+			code = e.ErrorAuthRole
+		}
+
 		if code != e.Success {
-			if required {
+			if required || act.Permission != "" {
 				e.ErrorResponse(c, code, "Authentication is required to perform this action")
 				c.Abort()
 				return
 			}
 			SetContextAccount(c, acc)
-		} else {
+		} else if token != "" && !isJWTMode() {
 			accID := c.GetInt("account_id")
-			RefreshToken(accID, token)
+			RefreshToken(accID, token, c)
 		}
 
 		c.Next()
 	}
 }
 
+// authByAccessToken restores the account from claims of jwt access token, storages aren't queried.
+func authByAccessToken(c *gin.Context, token string) (Account, int) {
+	claims, err := parseAccessToken(token)
+	if err != nil {
+		return Account{}, e.ErrorAuthCheckTokenFail
+	}
+	id, err := strconv.Atoi(claims.Subject)
+	if err != nil {
+		return Account{}, e.ErrorAuthCheckTokenFail
+	}
+	acc := Account{ID: id, Status: claims.Status}
+	for _, r := range claims.Roles {
+		acc.Roles = append(acc.Roles, AccountRole{AccountID: id, Role: r})
+	}
+	switch acc.Status {
+	case AccStatusUnconfirmed:
+		return acc, e.ErrorAuthUnconfirmed
+	case AccStatusBaned:
+		return acc, e.ErrorAuthBanned
+	case AccStatusActive:
+		SetContextAccount(c, acc)
+		c.Set("account_partial", true)
+		c.Set("token_family", claims.Family)
+		return acc, e.Success
+	}
+	return acc, e.ErrorAuth
+}
+
+func authByAPIKey(c *gin.Context, key string) (Account, int) {
+	ak, err := GetByAPIKey(key)
+	if err != nil {
+		return Account{}, e.ErrorAuthCheckTokenFail
+	}
+	if ak.Account.ID == 0 || ak.Account.Status != AccStatusService {
+		return Account{}, e.ErrorAuth
+	}
+	if act, ok := controller.GetAct(c); ok {
+		if act.HumanOnly {
+			return Account{}, e.ErrorAuthAppKeyNotAllowed
+		}
+		// acts without declared scope are not available for api keys
+		if act.Scope == "" || !ak.HasScope(act.Scope) {
+			return Account{}, e.ErrorAuthRole
+		}
+	}
+	if err = ak.Touch(); err != nil {
+		log.Println("Fail to update api key usage time", err)
+	}
+	SetContextAccount(c, ak.Account)
+	c.Set("api_key_id", ak.ID)
+	return ak.Account, e.Success
+}
+
+// IsAppKeyAuth reports if the request is authenticated with application key.
+func IsAppKeyAuth(c *gin.Context) bool {
+	_, ok := c.Get("api_key_id")
+	return ok
+}
+
 func GetToken(c *gin.Context) string {
 	return c.GetHeader(cfg.App.AuthTokenKey)
 }
 
+// currentSessionID returns id of the session of the request.
+func currentSessionID(c *gin.Context) string {
+	if isJWTMode() {
+		return redis.SessionID(c.GetString("token_family"))
+	}
+	return redis.SessionID(hashToken(GetToken(c)))
+}
+
+// freshSessionAge is the period after sign in the session confirms actions of account without password.
+const freshSessionAge = 10 * time.Minute
+
+// isFreshSession reports if the session of the request was started recently, api keys have no session.
+func isFreshSession(c *gin.Context) bool {
+	if IsAppKeyAuth(c) {
+		return false
+	}
+	s, err := redis.GetSession(GetContextAccID(c), currentSessionID(c))
+	if err != nil {
+		log.Println("Fail to get session", err)
+		return false
+	}
+	return s != nil && time.Since(s.CreatedAt) < freshSessionAge
+}
+
 func DropCurrentToken(c *gin.Context) error {
 	token := GetToken(c)
-	if token != "" {
+	if token != "" && isJWTMode() {
+		if family := c.GetString("token_family"); family != "" {
+			if err := revokeTokenFamily(GetContextAccID(c), family); err != nil {
+				return err
+			}
+		}
+		c.Header(cfg.App.AuthTokenKey, "")
+	} else if token != "" {
 		err := DropToken(GetToken(c))
 		if err != nil {
 			return err
@@ -153,3 +376,33 @@ func CheckRole(acc Account, roles []int) bool {
 	}
 	return roleAccessed
 }
+
+// Methods of sign in recorded to audit log.
+const (
+	signInMethodPassword  = "password"
+	signInMethodTwoFactor = "2fa"
+	signInMethodOIDC      = "oidc"
+)
+
+func auditSignIn(c *gin.Context, accID int, method string) {
+	audit.Record(c, audit.Event{
+		Action:     audit.AccountSignIn,
+		ActorID:    accID,
+		TargetType: audit.TargetAccount,
+		TargetID:   accID,
+		Details:    map[string]string{"method": method},
+	})
+}
+
+// auditSignInFailed records failed attempt, accID is 0 for unknown e-mail.
+func auditSignInFailed(c *gin.Context, accID int, email, method string) {
+	ev := audit.Event{
+		Action:     audit.AccountSignInFailed,
+		TargetType: audit.TargetAccount,
+		Details:    map[string]string{"email": email, "method": method},
+	}
+	if accID != 0 {
+		ev.TargetID = accID
+	}
+	audit.Record(c, ev)
+}
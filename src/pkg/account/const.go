@@ -8,13 +8,51 @@ const (
 	SignUpConfirmedSubject = "Sign Up confirmed"
 	SignUpConfirmedTmpl    = "sign-up-confirmed"
 
+	resendThrottleKey  = "sign-up-resend:"
+	recoverThrottleKey = "recover-resend:"
+	authTokenKey       = "auth-token:"
+	refreshTokenKey    = "refresh-token:"
+	refreshUsedKey     = "refresh-used:"
+	refreshFamilyKey   = "refresh-family:"
+
 	RecoverLink           = "/recover"
 	RecoverSubject        = "Password recover"
 	ChangePasswordSubject = "Change password"
 	RecoverTmpl           = "recover"
 
+	ChangeEmailLink     = "/change-email"
+	ChangeEmailSubject  = "E-mail change confirmation"
+	ChangeEmailTmpl     = "change-email"
+	EmailChangedSubject = "E-mail changed"
+	EmailChangedTmpl    = "email-changed"
+
+	InviteLink    = "/invite"
+	InviteSubject = "Invitation"
+	InviteTmpl    = "invite"
+
 	AccStatusUnconfirmed uint = 1
 	AccStatusActive      uint = 2
 	AccStatusBaned       uint = 3
 	AccStatusService     uint = 4
+
+	ScopeRepostRead   = "repost:read"
+	ScopeRepostWrite  = "repost:write"
+	ScopeRepostExport = "repost:export"
+	ScopeRssRead      = "rss:read"
+	ScopeRssWrite     = "rss:write"
+	ScopeProxyRead    = "proxy:read"
+	ScopeProxyWrite   = "proxy:write"
+	ScopeRuleRead     = "rule:read"
+	ScopeRuleWrite    = "rule:write"
+	ScopeDomainRead   = "domain:read"
+	ScopeDomainWrite  = "domain:write"
+	ScopeLinkRead     = "link:read"
+	ScopeProfileRead  = "profile:read"
+
+	PermAccountManage = "account:manage"
+	PermAPIKeyManage  = "api-key:manage"
+	PermRepostExport  = "repost:export"
+	PermProxyWrite    = "proxy:write"
+	PermRuleWrite     = "rule:write"
+	PermAuditRead     = "audit:read"
 )
@@ -12,9 +12,68 @@ func NewController() controller.Ctrl {
 		Handlers: nil,
 		Acts: []controller.Act{
 			{Method: "POST", Route: "/sign-up/", Handlers: []gin.HandlerFunc{SignUp}},
+			{Method: "POST", Route: "/confirm-sign-up/", Handlers: []gin.HandlerFunc{ConfirmSignUp}},
+			{Method: "POST", Route: "/resend-confirmation/", Handlers: []gin.HandlerFunc{ResendConfirmation}},
 			{Method: "POST", Route: "/sign-in/", Handlers: []gin.HandlerFunc{SignIn}},
-			{Method: "POST", Route: "/sign-out/", Handlers: []gin.HandlerFunc{Auth(false, []int{}), SignOut}},
-			{Method: "GET", Route: "/profile/", Handlers: []gin.HandlerFunc{Auth(true, []int{}), Profile}},
+			{Method: "POST", Route: "/sign-in/2fa/", Handlers: []gin.HandlerFunc{SignInTwoFactor}},
+			{Method: "POST", Route: "/refresh/", Handlers: []gin.HandlerFunc{Refresh}},
+			{Method: "GET", Route: "/oidc/login/", Handlers: []gin.HandlerFunc{OIDCLogin}},
+			{Method: "POST", Route: "/oidc/callback/", Handlers: []gin.HandlerFunc{OIDCCallback}},
+			{Method: "GET", Route: "/oidc/link/", Handlers: []gin.HandlerFunc{Auth(true, []int{}), OIDCLink},
+				HumanOnly: true},
+			{Method: "POST", Route: "/oidc/link/callback/", Handlers: []gin.HandlerFunc{Auth(true, []int{}), OIDCLinkCallback},
+				HumanOnly: true},
+			{Method: "GET", Route: "/oidc/identities/", Handlers: []gin.HandlerFunc{Auth(true, []int{}), Identities},
+				HumanOnly: true},
+			{Method: "DELETE", Route: "/oidc/identities/:id",
+				Handlers: []gin.HandlerFunc{Auth(true, []int{}), DropIdentity}, HumanOnly: true},
+			{Method: "POST", Route: "/sign-out/", Handlers: []gin.HandlerFunc{Auth(false, []int{}), SignOut},
+				HumanOnly: true},
+			{Method: "POST", Route: "/sign-out-all/", Handlers: []gin.HandlerFunc{Auth(true, []int{}), SignOutAll},
+				HumanOnly: true},
+			{Method: "GET", Route: "/sessions/", Handlers: []gin.HandlerFunc{Auth(true, []int{}), Sessions},
+				HumanOnly: true},
+			{Method: "DELETE", Route: "/sessions/:id", Handlers: []gin.HandlerFunc{Auth(true, []int{}), DropSession},
+				HumanOnly: true},
+			{Method: "POST", Route: "/recover/", Handlers: []gin.HandlerFunc{Recover}},
+			{Method: "POST", Route: "/recover/confirm/", Handlers: []gin.HandlerFunc{RecoverConfirm}},
+			{Method: "POST", Route: "/change-password/", Handlers: []gin.HandlerFunc{Auth(true, []int{}), ChangePassword},
+				HumanOnly: true},
+			{Method: "POST", Route: "/2fa/enroll/", Handlers: []gin.HandlerFunc{Auth(true, []int{}), TwoFactorEnroll},
+				HumanOnly: true},
+			{Method: "POST", Route: "/2fa/confirm/", Handlers: []gin.HandlerFunc{Auth(true, []int{}), TwoFactorConfirm},
+				HumanOnly: true},
+			{Method: "POST", Route: "/2fa/recovery-codes/",
+				Handlers: []gin.HandlerFunc{Auth(true, []int{}), TwoFactorRecoveryCodes}, HumanOnly: true},
+			{Method: "POST", Route: "/2fa/disable/", Handlers: []gin.HandlerFunc{Auth(true, []int{}), TwoFactorDisable},
+				HumanOnly: true},
+			{Method: "GET", Route: "/profile/", Handlers: []gin.HandlerFunc{Auth(true, []int{}), Profile},
+				Scope: ScopeProfileRead},
+			{Method: "PUT", Route: "/profile/", Handlers: []gin.HandlerFunc{Auth(true, []int{}), UpdateProfile},
+				HumanOnly: true},
+			{Method: "POST", Route: "/change-email/", Handlers: []gin.HandlerFunc{Auth(true, []int{}), ChangeEmail},
+				HumanOnly: true},
+			{Method: "POST", Route: "/change-email/confirm/", Handlers: []gin.HandlerFunc{ChangeEmailConfirm}},
+			{Method: "GET", Route: "/feed-token/", Handlers: []gin.HandlerFunc{Auth(true, []int{}), GetFeedToken},
+				HumanOnly: true},
+			{Method: "POST", Route: "/feed-token/reset/", Handlers: []gin.HandlerFunc{Auth(true, []int{}), ResetFeedToken},
+				HumanOnly: true},
+			{Method: "GET", Route: "/export/", Handlers: []gin.HandlerFunc{Auth(true, []int{}), Export},
+				HumanOnly: true},
+			{Method: "DELETE", Route: "/", Handlers: []gin.HandlerFunc{Auth(true, []int{}), Delete},
+				HumanOnly: true},
+			{Method: "POST", Route: "/service-accounts/",
+				Handlers: []gin.HandlerFunc{Auth(true, []int{}), CreateServiceAccount}, HumanOnly: true,
+				Permission: PermAPIKeyManage},
+			{Method: "GET", Route: "/api-keys/",
+				Handlers: []gin.HandlerFunc{Auth(true, []int{}), APIKeys}, HumanOnly: true,
+				Permission: PermAPIKeyManage},
+			{Method: "POST", Route: "/api-keys/",
+				Handlers: []gin.HandlerFunc{Auth(true, []int{}), CreateAPIKey}, HumanOnly: true,
+				Permission: PermAPIKeyManage},
+			{Method: "DELETE", Route: "/api-keys/:id",
+				Handlers: []gin.HandlerFunc{Auth(true, []int{}), DropAPIKey}, HumanOnly: true,
+				Permission: PermAPIKeyManage},
 		},
 	}
 }
@@ -0,0 +1,102 @@
+package account
+
+import (
+	"errors"
+	"oko/pkg/cfg"
+	"oko/pkg/db"
+	"oko/pkg/mail"
+	"time"
+
+	"github.com/jinzhu/gorm"
+)
+
+// EmailChangeToken confirms the new e-mail of the account, e-mail is switched only when the token is used.
+type EmailChangeToken struct {
+	ID        int       `json:"id"`
+	AccountID int       `json:"account_id"`
+	Email     string    `json:"email"`
+	Token     string    `json:"-" gorm:"-"`
+	TokenHash string    `json:"-"`
+	IsUsed    bool      `json:"is_used"`
+	ExpireAt  time.Time `json:"expire_at"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Account   Account   `json:"account" gorm:"foreignkey:AccountID;PRELOAD:true"`
+}
+
+func (EmailChangeToken) TableName() string {
+	return "email_change_token"
+}
+
+func GenerateEmailChangeToken() string {
+	return newToken(tokenKindEmailChange)
+}
+
+// NewEmailChangeToken returns token with plain value to be sent, only hash of the value is stored.
+func NewEmailChangeToken(accID int, email string) EmailChangeToken {
+	t := GenerateEmailChangeToken()
+	return EmailChangeToken{
+		AccountID: accID,
+		Email:     email,
+		Token:     t,
+		TokenHash: hashToken(t),
+		ExpireAt:  time.Now().Add(time.Second * time.Duration(cfg.App.EmailTokenLifetime)),
+	}
+}
+
+func GetByEmailChangeToken(token string) (*EmailChangeToken, error) {
+	ect := new(EmailChangeToken)
+	if err := db.GetDB().
+		Preload("Account").
+		Where("token_hash=? and expire_at >= now() and not is_used", hashToken(token)).
+		First(ect).Error; err != nil {
+		return nil, err
+	}
+	if !matchToken(token, ect.TokenHash) {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return ect, nil
+}
+
+// Use marks token as used, fails if token was used concurrently.
+func (ect *EmailChangeToken) Use(dbt *gorm.DB) error {
+	res := dbt.Model(&EmailChangeToken{}).Where("id = ? and not is_used", ect.ID).Update("is_used", true)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return errors.New("email change token already used")
+	}
+	ect.IsUsed = true
+	return nil
+}
+
+// DropAccountEmailChangeTokens marks all unused e-mail change tokens of the account as used,
+// so only the latest requested e-mail can be confirmed.
+func DropAccountEmailChangeTokens(dbt *gorm.DB, accID int) error {
+	return dbt.Model(&EmailChangeToken{}).Where("account_id = ? and not is_used", accID).Update("is_used", true).Error
+}
+
+// SendToken sends confirmation link to the new e-mail.
+func (ect EmailChangeToken) SendToken() error {
+	return mail.Send(mail.Message{
+		To:       ect.Email,
+		Subject:  ChangeEmailSubject,
+		Template: ChangeEmailTmpl,
+		Data:     map[string]interface{}{"Name": ect.Account.Name, "Link": ect.getLink()},
+	})
+}
+
+func (ect EmailChangeToken) getLink() string {
+	return cfg.App.FrontURL + ChangeEmailLink + "/" + ect.Token
+}
+
+// SendEmailChanged notifies the previous e-mail of the account about the change.
+func (a Account) SendEmailChanged(oldEmail string) error {
+	return mail.Send(mail.Message{
+		To:       oldEmail,
+		Subject:  EmailChangedSubject,
+		Template: EmailChangedTmpl,
+		Data:     map[string]interface{}{"Name": a.Name, "Email": a.Email},
+	})
+}
@@ -0,0 +1,144 @@
+package account
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"oko/pkg/cfg"
+	"oko/pkg/e"
+	"oko/pkg/ginapp/types"
+	"oko/pkg/redis"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Export godoc
+// @Summary Export account data
+// @Description Export profile, roles, repost requests and sessions of current account as zip of json files
+// @ID get-account-export
+// @Tags Account
+// @Produce application/zip
+// @Success 200
+// @Failure 500 {object} types.ResponseErrorSwg
+// @Router /account/export [get]
+// @Security ApiKeyAuth
+func Export(c *gin.Context) {
+	acc := GetContextAcc(c)
+
+	roles := make([]types.RoleItem, 0, len(acc.Roles))
+	for _, r := range acc.Roles {
+		roles = append(roles, types.RoleItem{Role: r.Role, StrRole: r.GetStrRole()})
+	}
+
+	reposts, err := ListExportReposts(acc.ID)
+	if err != nil {
+		e.ErrorResponse(c, http.StatusInternalServerError, "Something went wrong")
+		return
+	}
+
+	idents, err := ListIdentities(acc.ID)
+	if err != nil {
+		e.ErrorResponse(c, http.StatusInternalServerError, "Something went wrong")
+		return
+	}
+	identities := make([]types.Identity, 0, len(idents))
+	for _, ident := range idents {
+		identities = append(identities, toIdentityView(ident))
+	}
+
+	list, err := redis.GetSessions(acc.ID, sessionLifetime())
+	if err != nil {
+		e.ErrorResponse(c, http.StatusInternalServerError, "Something went wrong")
+		return
+	}
+	sessions := make([]types.Session, 0, len(list))
+	for _, s := range list {
+		sessions = append(sessions, types.Session{
+			ID:         s.ID,
+			CreatedAt:  s.CreatedAt,
+			LastSeenAt: s.LastSeenAt,
+			IP:         s.IP,
+			UserAgent:  s.UserAgent,
+		})
+	}
+
+	buff, err := writeToZip(map[string]interface{}{
+		"profile.json":         toProfileView(acc),
+		"roles.json":           roles,
+		"repost_requests.json": reposts,
+		"sessions.json":        sessions,
+		"identities.json":      identities,
+	})
+	if err != nil {
+		e.ErrorResponse(c, http.StatusInternalServerError, "Something went wrong")
+		return
+	}
+	extraHeaders := map[string]string{
+		"Content-Disposition": `attachment; filename="account.zip"`,
+	}
+	c.DataFromReader(http.StatusOK, int64(buff.Len()), "application/zip", buff, extraHeaders)
+}
+
+func writeToZip(files map[string]interface{}) (*bytes.Buffer, error) {
+	buffer := bytes.NewBuffer([]byte{})
+	writer := zip.NewWriter(buffer)
+	for name, data := range files {
+		w, err := writer.Create(name)
+		if err != nil {
+			return nil, err
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err = enc.Encode(data); err != nil {
+			return nil, err
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return buffer, nil
+}
+
+// Delete godoc
+// @Summary Delete account
+// @Description Delete current account, personal data is anonymised and the account is purged after a grace period.
+// @Description Organizations owned by the account alone are deleted, the only owner of organization
+// @Description with other members has to transfer the ownership first.
+// @Description Account without password, provisioned by identity provider, has to sign in again instead.
+// @ID delete-account
+// @Tags Account
+// @Accept json
+// @Produce json
+// @Param object body account.DeleteAccountForm true "Delete account fields"
+// @Success 200 {object} types.StdResponse
+// @Failure 400 {object} types.ResponseErrorSwg
+// @Failure 500 {object} types.ResponseErrorSwg
+// @Router /account [delete]
+// @Security ApiKeyAuth
+func Delete(c *gin.Context) {
+	var form DeleteAccountForm
+
+	if err := c.ShouldBind(&form); err != nil {
+		e.ErrorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	acc := GetContextAcc(c)
+	if !confirmAccount(c, acc, form.Password) {
+		return
+	}
+
+	err := acc.Erase()
+	if err == ErrSoleOwner {
+		e.ErrorResponse(c, http.StatusBadRequest, "Transfer ownership of your organizations with members first")
+		return
+	}
+	if err != nil {
+		e.ErrorResponse(c, http.StatusInternalServerError, "Something went wrong")
+		return
+	}
+	c.Header(cfg.App.AuthTokenKey, "")
+
+	types.SuccessEmptyResponse(c)
+}
@@ -0,0 +1,149 @@
+package account
+
+import (
+	"errors"
+	"fmt"
+	"oko/pkg/db"
+	"time"
+
+	"github.com/jinzhu/gorm"
+)
+
+// ErrSoleOwner is returned on erasing the only owner of organization with other members.
+var ErrSoleOwner = errors.New("account is the only owner of organization with members")
+
+// tables referring to account which are cleaned before the account is purged
+var accountRefTables = []string{ //nolint
+	"account_repost_request",
+	"account_role",
+	"sign_up_token",
+	"recover_token",
+	"email_change_token",
+	"recovery_code",
+	"api_key",
+	"organization_member",
+	"account_identity",
+}
+
+// ExportRepost is a repost request the account subscribed to.
+type ExportRepost struct {
+	ID             uint      `json:"id"`
+	URL            string    `json:"url"`
+	Level          uint      `json:"level"`
+	OrganizationID uint      `json:"organization_id"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// ListExportReposts returns repost requests of the account for the data export.
+func ListExportReposts(accID int) ([]ExportRepost, error) {
+	var list []ExportRepost
+	err := db.GetDB().
+		Table("account_repost_request").
+		Select(`repost_request.id, repost_request.url, repost_request.level,
+			account_repost_request.organization_id, repost_request.created_at`).
+		Joins("join repost_request on repost_request.id = account_repost_request.request_id").
+		Where("account_repost_request.account_id = ? and repost_request.deleted_at is null", accID).
+		Order("repost_request.id").
+		Scan(&list).Error
+	return list, err
+}
+
+// soleOwnedOrganization is an organization without other owners than the account.
+type soleOwnedOrganization struct {
+	OrganizationID uint
+	Members        int
+}
+
+func listSoleOwnedOrganizations(dbt *gorm.DB, accID int) ([]soleOwnedOrganization, error) {
+	var list []soleOwnedOrganization
+	err := dbt.Raw(`select m.organization_id,
+			(select count(*) from organization_member x where x.organization_id = m.organization_id) as members
+		from organization_member m
+		join organization on organization.id = m.organization_id
+		where organization.deleted_at is null and m.account_id = ? and m.role = ?
+			and not exists (select 1 from organization_member x
+				where x.organization_id = m.organization_id and x.account_id <> m.account_id and x.role = ?)`,
+		accID, orgRoleOwner, orgRoleOwner).
+		Scan(&list).Error
+	return list, err
+}
+
+// Erase soft-deletes the account, personal data is anonymised, repost subscriptions and identities
+// are detached and all auth tokens are revoked. The record is purged by yardman after the delay.
+// Organizations owned by the account alone are deleted with it, ErrSoleOwner is returned
+// if any of them has other members.
+func (a *Account) Erase() error {
+	dbt := db.GetDB().Begin()
+	owned, err := listSoleOwnedOrganizations(dbt, a.ID)
+	if err != nil {
+		dbt.Rollback()
+		return err
+	}
+	orgIDs := make([]uint, 0, len(owned))
+	for _, o := range owned {
+		if o.Members > 1 {
+			dbt.Rollback()
+			return ErrSoleOwner
+		}
+		orgIDs = append(orgIDs, o.OrganizationID)
+	}
+	if len(orgIDs) > 0 {
+		if err := dbt.Exec("update organization set deleted_at = now() where id in (?)", orgIDs).Error; err != nil {
+			dbt.Rollback()
+			return err
+		}
+	}
+	if err := dbt.Model(a).Updates(map[string]interface{}{
+		"email":         fmt.Sprintf("deleted-%d@deleted.invalid", a.ID),
+		"name":          "",
+		"password_hash": "",
+		"totp_secret":   "",
+		"totp_enabled":  false,
+	}).Error; err != nil {
+		dbt.Rollback()
+		return err
+	}
+	if err := dbt.Exec("delete from account_repost_request where account_id = ?", a.ID).Error; err != nil {
+		dbt.Rollback()
+		return err
+	}
+	if err := dbt.Where("account_id = ?", a.ID).Delete(&Identity{}).Error; err != nil {
+		dbt.Rollback()
+		return err
+	}
+	if err := dbt.Delete(a).Error; err != nil {
+		dbt.Rollback()
+		return err
+	}
+	if err := dbt.Commit().Error; err != nil {
+		return err
+	}
+
+	return DropAccountTokens(a.ID)
+}
+
+// PurgeDeletedAccounts removes accounts deleted before the time with all their records.
+func PurgeDeletedAccounts(before time.Time) (int, error) {
+	var ids []int
+	if err := db.GetDB().Unscoped().Model(&Account{}).
+		Where("deleted_at is not null and deleted_at < ?", before).
+		Pluck("id", &ids).Error; err != nil {
+		return 0, err
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	dbt := db.GetDB().Begin()
+	for _, table := range accountRefTables {
+		if err := dbt.Exec("delete from "+table+" where account_id in (?)", ids).Error; err != nil {
+			dbt.Rollback()
+			return 0, err
+		}
+	}
+	if err := dbt.Unscoped().Where("id in (?)", ids).Delete(&Account{}).Error; err != nil {
+		dbt.Rollback()
+		return 0, err
+	}
+	return len(ids), dbt.Commit().Error
+}
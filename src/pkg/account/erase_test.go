@@ -0,0 +1,70 @@
+package account
+
+import (
+	"errors"
+	"oko/pkg/db"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jinzhu/gorm"
+	"github.com/stretchr/testify/require"
+)
+
+func mockDB(t *testing.T) sqlmock.Sqlmock {
+	sqlDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	gdb, err := gorm.Open("postgres", sqlDB)
+	require.NoError(t, err)
+	db.SetDB(gdb)
+	return mock
+}
+
+func TestEraseSoleOwner(t *testing.T) {
+	mock := mockDB(t)
+	mock.ExpectBegin()
+	mock.ExpectQuery(`select m.organization_id`).WithArgs(7, orgRoleOwner, orgRoleOwner).
+		WillReturnRows(sqlmock.NewRows([]string{"organization_id", "members"}).AddRow(3, 1).AddRow(5, 2))
+	mock.ExpectRollback()
+
+	acc := &Account{ID: 7}
+	require.Equal(t, ErrSoleOwner, acc.Erase())
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestEraseOwnOrganization(t *testing.T) {
+	mock := mockDB(t)
+	mock.ExpectBegin()
+	mock.ExpectQuery(`select m.organization_id`).
+		WillReturnRows(sqlmock.NewRows([]string{"organization_id", "members"}).AddRow(3, 1))
+	mock.ExpectExec(`update organization set deleted_at = now\(\) where id in \(\$1\)`).WithArgs(3).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`UPDATE "account"`).WillReturnError(errors.New("stop"))
+	mock.ExpectRollback()
+
+	acc := &Account{ID: 7}
+	require.EqualError(t, acc.Erase(), "stop")
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestListSoleOwnedOrganizations(t *testing.T) {
+	mock := mockDB(t)
+	// organizations with another owner and deleted ones are filtered out by the query
+	mock.ExpectQuery(`where organization.deleted_at is null and m.account_id = \$1 and m.role = \$2\s+`+
+		`and not exists \(select 1 from organization_member x\s+`+
+		`where x.organization_id = m.organization_id and x.account_id <> m.account_id and x.role = \$3\)`).
+		WithArgs(7, orgRoleOwner, orgRoleOwner).
+		WillReturnRows(sqlmock.NewRows([]string{"organization_id", "members"}).AddRow(3, 1).AddRow(5, 4))
+
+	list, err := listSoleOwnedOrganizations(db.GetDB(), 7)
+	require.NoError(t, err)
+	require.Equal(t, []soleOwnedOrganization{{OrganizationID: 3, Members: 1}, {OrganizationID: 5, Members: 4}}, list)
+	require.NoError(t, mock.ExpectationsWereMet())
+
+	mock = mockDB(t)
+	mock.ExpectQuery(`select m.organization_id`).WithArgs(8, orgRoleOwner, orgRoleOwner).
+		WillReturnRows(sqlmock.NewRows([]string{"organization_id", "members"}))
+	list, err = listSoleOwnedOrganizations(db.GetDB(), 8)
+	require.NoError(t, err)
+	require.Empty(t, list)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
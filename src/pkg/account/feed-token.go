@@ -0,0 +1,162 @@
+package account
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"oko/pkg/audit"
+	"oko/pkg/cfg"
+	"oko/pkg/db"
+	"oko/pkg/e"
+	"oko/pkg/ginapp/types"
+	"oko/pkg/token"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jinzhu/gorm"
+)
+
+// FeedTokenParam is the query parameter of feed urls, feed readers can't send Authorization header.
+const FeedTokenParam = "token"
+
+var (
+	errFeedDisabled = errors.New("feed tokens are disabled")
+	errFeedToken    = errors.New("invalid feed token")
+)
+
+// feedTokenData is the signed part of feed token, the version is bumped to revoke issued tokens.
+func feedTokenData(accID, version int) string {
+	return strconv.Itoa(accID) + "." + strconv.Itoa(version)
+}
+
+// FeedToken returns signed read-only token of the account for feed urls, e.g. oko_live_fd_<id>.<version>.<sig>.
+// The token is not stored, it stays the same until the account resets it.
+func FeedToken(acc Account) (string, error) {
+	if cfg.App.FeedTokenSecret == "" {
+		return "", errFeedDisabled
+	}
+	data := feedTokenData(acc.ID, acc.FeedVersion)
+	return cfg.App.TokenPrefix + tokenKindFeed + "_" + data + "." + token.Sign([]byte(cfg.App.FeedTokenSecret), data), nil
+}
+
+// parseFeedToken returns account id and version of the token with valid signature.
+func parseFeedToken(t string) (accID, version int, err error) {
+	if cfg.App.FeedTokenSecret == "" {
+		return 0, 0, errFeedDisabled
+	}
+	prefix := cfg.App.TokenPrefix + tokenKindFeed + "_"
+	if !strings.HasPrefix(t, prefix) {
+		return 0, 0, errFeedToken
+	}
+	parts := strings.Split(strings.TrimPrefix(t, prefix), ".")
+	if len(parts) != 3 {
+		return 0, 0, errFeedToken
+	}
+	if accID, err = strconv.Atoi(parts[0]); err != nil {
+		return 0, 0, errFeedToken
+	}
+	if version, err = strconv.Atoi(parts[1]); err != nil {
+		return 0, 0, errFeedToken
+	}
+	if !token.Verify([]byte(cfg.App.FeedTokenSecret), feedTokenData(accID, version), parts[2]) {
+		return 0, 0, errFeedToken
+	}
+	return accID, version, nil
+}
+
+// FeedAuth authenticates feed requests by FeedTokenParam, it's used instead of Auth on feed routes.
+func FeedAuth() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		accID, version, err := parseFeedToken(c.Query(FeedTokenParam))
+		if err != nil {
+			e.ErrorResponse(c, e.ErrorAuthCheckTokenFail, "Invalid feed token")
+			c.Abort()
+			return
+		}
+
+		var acc Account
+		db.GetDB().Preload("Roles").First(&acc, accID)
+		if acc.ID == 0 || acc.FeedVersion != version || acc.Status != AccStatusActive {
+			e.ErrorResponse(c, e.ErrorAuthCheckTokenFail, "Invalid feed token")
+			c.Abort()
+			return
+		}
+		SetContextAccount(c, acc)
+		c.Next()
+	}
+}
+
+func feedTokenView(acc Account) (types.FeedToken, error) {
+	t, err := FeedToken(acc)
+	if err != nil {
+		return types.FeedToken{}, err
+	}
+	base := cfg.App.APISchema + "://" + cfg.App.APIHost + "/api/link/"
+	query := "?" + url.Values{FeedTokenParam: {t}}.Encode()
+	return types.FeedToken{
+		Token:   t,
+		AtomURL: base + "feed.atom" + query,
+		RssURL:  base + "feed.rss" + query,
+	}, nil
+}
+
+func feedTokenResponse(c *gin.Context, acc Account) {
+	view, err := feedTokenView(acc)
+	if err == errFeedDisabled {
+		e.ErrorResponse(c, http.StatusBadRequest, "Feeds are disabled")
+		return
+	}
+	if err != nil {
+		panic(err)
+	}
+	types.SuccessResponse(c, view)
+}
+
+// GetFeedToken godoc
+// @Summary Get feed token
+// @Description Get token and urls of link search feeds, add query and domain_id parameters to the urls.
+// @Description The token gives read access to link search, it's valid until reset
+// @ID get-account-feed-token
+// @Tags Account
+// @Accept json
+// @Produce json
+// @Success 200 {object} types.ResponseFeedToken
+// @Failure 400 {object} types.ResponseErrorSwg
+// @Router /account/feed-token [get]
+// @Security ApiKeyAuth
+func GetFeedToken(c *gin.Context) {
+	feedTokenResponse(c, GetContextAcc(c))
+}
+
+// ResetFeedToken godoc
+// @Summary Reset feed token
+// @Description Revoke feed token of the account and issue new one
+// @ID post-account-feed-token-reset
+// @Tags Account
+// @Accept json
+// @Produce json
+// @Success 200 {object} types.ResponseFeedToken
+// @Failure 400 {object} types.ResponseErrorSwg
+// @Failure 500 {object} types.ResponseErrorSwg
+// @Router /account/feed-token/reset [post]
+// @Security ApiKeyAuth
+func ResetFeedToken(c *gin.Context) {
+	acc := GetContextAcc(c)
+	if cfg.App.FeedTokenSecret == "" {
+		e.ErrorResponse(c, http.StatusBadRequest, "Feeds are disabled")
+		return
+	}
+
+	err := db.GetDB().Model(&Account{ID: acc.ID}).
+		UpdateColumn("feed_version", gorm.Expr("feed_version + 1")).Error
+	if err != nil {
+		panic(err)
+	}
+	if err = db.GetDB().Select("feed_version").Where("id = ?", acc.ID).First(&acc).Error; err != nil {
+		panic(err)
+	}
+
+	audit.Record(c, audit.Event{Action: audit.AccountFeedReset, TargetType: audit.TargetAccount, TargetID: acc.ID})
+	feedTokenResponse(c, acc)
+}
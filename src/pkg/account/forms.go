@@ -1,5 +1,7 @@
 package account
 
+import "time"
+
 type SignInForm struct {
 	Email    string `json:"email" form:"Email" binding:"required,email"`
 	Password string `json:"password" form:"Password" binding:"required"`
@@ -10,4 +12,89 @@ type SignUpForm struct {
 	Email           string `json:"email" form:"email" binding:"required,email,UniqueEmail"`
 	Password        string `json:"password" form:"password" binding:"required,StrongPass"`
 	PasswordConfirm string `json:"password_confirm" form:"password_confirm" binding:"required,eqfield=Password" `
+	Invite          string `json:"invite" form:"invite" binding:"omitempty,Invite"`
+}
+
+type ConfirmSignUpForm struct {
+	Token  string `json:"token" form:"token" binding:"required,SignUpToken"`
+	SignIn bool   `json:"sign_in" form:"sign_in"`
+}
+
+type ResendConfirmationForm struct {
+	Email string `json:"email" form:"email" binding:"required,email"`
+}
+
+type RecoverForm struct {
+	Email string `json:"email" form:"email" binding:"required,email"`
+}
+
+type RecoverConfirmForm struct {
+	Token           string `json:"token" form:"token" binding:"required,RecoverTokenNotExp,RecoverTokenNotUsed,RecoverToken"` //nolint
+	Password        string `json:"password" form:"password" binding:"required,StrongPass"`
+	PasswordConfirm string `json:"password_confirm" form:"password_confirm" binding:"required,eqfield=Password"`
+}
+
+type ChangePasswordForm struct {
+	OldPassword     string `json:"old_password" form:"old_password" binding:"required"`
+	Password        string `json:"password" form:"password" binding:"required,StrongPass"`
+	PasswordConfirm string `json:"password_confirm" form:"password_confirm" binding:"required,eqfield=Password"`
+}
+
+type ProfileForm struct {
+	Name string `json:"name" form:"name" binding:"required,max=255"`
+}
+
+// ChangeEmailForm password is not required from account without password, the session must be fresh instead.
+type ChangeEmailForm struct {
+	Email    string `json:"email" form:"email" binding:"required,email,UniqueEmail"`
+	Password string `json:"password" form:"password"`
+}
+
+type ChangeEmailConfirmForm struct {
+	Token string `json:"token" form:"token" binding:"required"`
+}
+
+// DeleteAccountForm password is not required from account without password, the session must be fresh instead.
+type DeleteAccountForm struct {
+	Password string `json:"password" form:"password"`
+}
+
+type RefreshForm struct {
+	RefreshToken string `json:"refresh_token" form:"refresh_token" binding:"required"`
+}
+
+type OIDCCallbackForm struct {
+	Code  string `json:"code" form:"code" binding:"required"`
+	State string `json:"state" form:"state" binding:"required"`
+}
+
+type SignInTwoFactorForm struct {
+	ChallengeToken string `json:"challenge_token" form:"challenge_token" binding:"required"`
+	Code           string `json:"code" form:"code" binding:"required_without=RecoveryCode"`
+	RecoveryCode   string `json:"recovery_code" form:"recovery_code"`
+}
+
+type TwoFactorCodeForm struct {
+	Code string `json:"code" form:"code" binding:"required,numeric"`
+}
+
+type TwoFactorDisableForm struct {
+	Password string `json:"password" form:"password" binding:"required"`
+	Code     string `json:"code" form:"code" binding:"required,numeric"`
+}
+
+type ServiceAccountForm struct {
+	Name  string `json:"name" form:"name" binding:"required"`
+	Email string `json:"email" form:"email" binding:"required,email,UniqueEmail"`
+}
+
+type APIKeyListForm struct {
+	AccountID int `json:"account_id" form:"account_id" binding:"required"`
+}
+
+type APIKeyCreateForm struct {
+	AccountID int        `json:"account_id" form:"account_id" binding:"required"`
+	Name      string     `json:"name" form:"name" binding:"required"`
+	Scopes    []string   `json:"scopes" form:"scopes" binding:"UniqueList,dive,APIKeyScope"`
+	ExpireAt  *time.Time `json:"expire_at" form:"expire_at"`
 }
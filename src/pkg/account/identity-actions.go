@@ -0,0 +1,220 @@
+package account
+
+import (
+	"net/http"
+	"oko/pkg/db"
+	"oko/pkg/e"
+	"oko/pkg/ginapp/types"
+	"oko/pkg/log"
+	"oko/pkg/oidc"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+func toIdentityView(ident Identity) types.Identity {
+	return types.Identity{
+		ID:        ident.ID,
+		Issuer:    ident.Issuer,
+		Subject:   ident.Subject,
+		Email:     ident.Email,
+		CreatedAt: ident.CreatedAt,
+	}
+}
+
+func oidcLoginResponse(c *gin.Context, accID int) {
+	url, err := newOIDCLogin(accID)
+	switch err {
+	case nil:
+	case errOIDCDisabled:
+		e.ErrorResponse(c, http.StatusNotFound, "Single sign-on is not configured")
+		return
+	default:
+		log.Println("Fail to start single sign-on", err)
+		e.ErrorResponse(c, http.StatusInternalServerError, "Something went wrong")
+		return
+	}
+
+	types.SuccessResponse(c, types.OIDCLogin{URL: url})
+}
+
+// OIDCLogin godoc
+// @Summary Start single sign-on
+// @Description Get url of identity provider to sign in with, the provider redirects back
+// @Description with code and state to be passed to callback
+// @ID get-account-oidc-login
+// @Tags Account
+// @Accept json
+// @Produce json
+// @Success 200 {object} types.ResponseOIDCLogin
+// @Failure 404 {object} types.ResponseErrorSwg
+// @Failure 500 {object} types.ResponseErrorSwg
+// @Router /account/oidc/login [get]
+func OIDCLogin(c *gin.Context) {
+	oidcLoginResponse(c, 0)
+}
+
+// OIDCLink godoc
+// @Summary Link identity
+// @Description Get url of identity provider, the provider redirects back with code and state
+// @Description to be passed to link callback by current account
+// @ID get-account-oidc-link
+// @Tags Account
+// @Accept json
+// @Produce json
+// @Success 200 {object} types.ResponseOIDCLogin
+// @Failure 404 {object} types.ResponseErrorSwg
+// @Failure 500 {object} types.ResponseErrorSwg
+// @Router /account/oidc/link [get]
+// @Security ApiKeyAuth
+func OIDCLink(c *gin.Context) {
+	oidcLoginResponse(c, GetContextAccID(c))
+}
+
+// oidcErrorResponse writes response of failed sign on, false is returned if err isn't nil.
+func oidcErrorResponse(c *gin.Context, err error) bool {
+	switch err {
+	case nil:
+		return true
+	case errOIDCDisabled:
+		e.ErrorResponse(c, http.StatusNotFound, "Single sign-on is not configured")
+	case errOIDCState:
+		e.ErrorResponse(c, http.StatusBadRequest, "Sign-on session expired, try again")
+	case errIdentityTaken:
+		e.ErrorResponse(c, http.StatusBadRequest, "Identity is linked to another account")
+	case errIdentityEmail:
+		e.ErrorResponse(c, http.StatusBadRequest, "Account with the e-mail exists, sign in and link the identity")
+	case errIdentityNotActive:
+		e.ErrorResponse(c, e.ErrorAuth, "Account is not active")
+	case oidc.ErrMalformed, oidc.ErrUnknownKey, oidc.ErrSignature, oidc.ErrInvalidClaims, oidc.ErrExpired:
+		log.Println("Invalid id token", err)
+		e.ErrorResponse(c, e.ErrorAuth, "Sign-on failed")
+	default:
+		log.Println("Fail to finish single sign-on", err)
+		e.ErrorResponse(c, http.StatusInternalServerError, "Something went wrong")
+	}
+	return false
+}
+
+// OIDCCallback godoc
+// @Summary Finish single sign-on
+// @Description Exchange authorization code of identity provider for access token.
+// @Description Unknown identity is linked to the account with the same verified e-mail
+// @Description or new account is provisioned, roles are granted by groups of the identity.
+// @Description State of identity link is refused, it's finished by link callback
+// @ID post-account-oidc-callback
+// @Tags Account
+// @Accept json
+// @Produce json
+// @Param object body account.OIDCCallbackForm true "Authorization response"
+// @Success 200 {object} types.ResponseSignIn
+// @Failure 400 {object} types.ResponseErrorSwg
+// @Failure 401 {object} types.ResponseErrorSwg
+// @Failure 404 {object} types.ResponseErrorSwg
+// @Failure 500 {object} types.ResponseErrorSwg
+// @Router /account/oidc/callback [post]
+func OIDCCallback(c *gin.Context) {
+	var form OIDCCallbackForm
+
+	if err := c.ShouldBind(&form); err != nil {
+		e.ErrorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	acc, err := oidcSignIn(c, form.Code, form.State, 0)
+	if !oidcErrorResponse(c, err) {
+		return
+	}
+
+	signInResponse(c, acc, signInMethodOIDC)
+}
+
+// OIDCLinkCallback godoc
+// @Summary Finish identity link
+// @Description Exchange authorization code of identity provider and link the identity to current account,
+// @Description the state is only accepted from the account which started the link
+// @ID post-account-oidc-link-callback
+// @Tags Account
+// @Accept json
+// @Produce json
+// @Param object body account.OIDCCallbackForm true "Authorization response"
+// @Success 200 {object} types.StdResponse
+// @Failure 400 {object} types.ResponseErrorSwg
+// @Failure 401 {object} types.ResponseErrorSwg
+// @Failure 404 {object} types.ResponseErrorSwg
+// @Failure 500 {object} types.ResponseErrorSwg
+// @Router /account/oidc/link/callback [post]
+// @Security ApiKeyAuth
+func OIDCLinkCallback(c *gin.Context) {
+	var form OIDCCallbackForm
+
+	if err := c.ShouldBind(&form); err != nil {
+		e.ErrorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	_, err := oidcSignIn(c, form.Code, form.State, GetContextAccID(c))
+	if !oidcErrorResponse(c, err) {
+		return
+	}
+
+	types.SuccessEmptyResponse(c)
+}
+
+// Identities godoc
+// @Summary List identities
+// @Description List identities of identity providers linked to current account
+// @ID get-account-oidc-identities
+// @Tags Account
+// @Accept json
+// @Produce json
+// @Success 200 {object} types.ResponseIdentities
+// @Failure 500 {object} types.ResponseErrorSwg
+// @Router /account/oidc/identities [get]
+// @Security ApiKeyAuth
+func Identities(c *gin.Context) {
+	idents, err := ListIdentities(GetContextAccID(c))
+	if err != nil {
+		e.ErrorResponse(c, http.StatusInternalServerError, "Something went wrong")
+		return
+	}
+
+	data := make([]types.Identity, 0, len(idents))
+	for _, ident := range idents {
+		data = append(data, toIdentityView(ident))
+	}
+	types.SuccessResponse(c, data)
+}
+
+// DropIdentity godoc
+// @Summary Unlink identity
+// @Description Unlink identity from current account
+// @ID delete-account-oidc-identity
+// @Tags Account
+// @Accept json
+// @Produce json
+// @Param id path int true "Identity ID"
+// @Success 200 {object} types.StdResponse
+// @Failure 400 {object} types.ResponseErrorSwg
+// @Failure 404 {object} types.ResponseErrorSwg
+// @Router /account/oidc/identities/{id} [delete]
+// @Security ApiKeyAuth
+func DropIdentity(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		e.ErrorResponse(c, http.StatusBadRequest, "Something went wrong")
+		return
+	}
+
+	res := db.GetDB().Where("id = ? and account_id = ?", id, GetContextAccID(c)).Delete(&Identity{})
+	if res.Error != nil {
+		e.ErrorResponse(c, http.StatusInternalServerError, "Something went wrong")
+		return
+	}
+	if res.RowsAffected == 0 {
+		e.ErrorResponse(c, http.StatusNotFound, "Identity not found")
+		return
+	}
+
+	types.SuccessEmptyResponse(c)
+}
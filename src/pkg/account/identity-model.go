@@ -0,0 +1,318 @@
+package account
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"oko/pkg/audit"
+	"oko/pkg/cfg"
+	"oko/pkg/db"
+	"oko/pkg/log"
+	"oko/pkg/oidc"
+	"oko/pkg/redis"
+	"oko/pkg/rndstr"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jinzhu/gorm"
+)
+
+const (
+	oidcStateKey    = "oidc-state:"
+	oidcStateLength = 32
+	oidcHTTPTimeout = 10 * time.Second
+)
+
+var (
+	errOIDCDisabled      = errors.New("single sign-on is not configured")
+	errOIDCState         = errors.New("invalid sign-on state")
+	errIdentityTaken     = errors.New("identity is linked to another account")
+	errIdentityEmail     = errors.New("account with the e-mail exists, sign in and link the identity")
+	errIdentityNotActive = errors.New("account is not active")
+)
+
+// Identity links account to the user of identity provider, an account can have both password
+// and several identities.
+type Identity struct {
+	ID        int       `json:"id"`
+	AccountID int       `json:"account_id"`
+	Issuer    string    `json:"issuer"`
+	Subject   string    `json:"subject"`
+	Email     string    `json:"email"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (Identity) TableName() string {
+	return "account_identity"
+}
+
+// oidcState is kept until the user returns from identity provider, AccountID is set
+// when signed in user links new identity.
+type oidcState struct {
+	Nonce     string `json:"nonce"`
+	Verifier  string `json:"verifier"`
+	AccountID int    `json:"account_id"`
+}
+
+var (
+	providerMu sync.Mutex
+	provider   *oidc.Provider
+)
+
+// oidcProvider discovers identity provider on first use, failed discovery is retried on the next call.
+func oidcProvider() (*oidc.Provider, error) {
+	if cfg.App.OIDCIssuer == "" {
+		return nil, errOIDCDisabled
+	}
+	providerMu.Lock()
+	defer providerMu.Unlock()
+
+	if provider != nil {
+		return provider, nil
+	}
+	p, err := oidc.Discover(oidc.Config{
+		Issuer:       cfg.App.OIDCIssuer,
+		ClientID:     cfg.App.OIDCClientID,
+		ClientSecret: cfg.App.OIDCClientSecret,
+		RedirectURL:  cfg.App.OIDCRedirectURL,
+		Scopes:       cfg.App.OIDCScopes,
+		GroupsClaim:  cfg.App.OIDCGroupsClaim,
+	}, &http.Client{Timeout: oidcHTTPTimeout}, oidc.SystemClock{})
+	if err != nil {
+		return nil, err
+	}
+	provider = p
+	return provider, nil
+}
+
+// newOIDCLogin returns url of identity provider to start sign on, accID is set to link identity
+// to signed in account.
+func newOIDCLogin(accID int) (string, error) {
+	p, err := oidcProvider()
+	if err != nil {
+		return "", err
+	}
+	verifier, challenge := oidc.NewPKCE()
+	state := rndstr.RandString(oidcStateLength)
+	st := oidcState{
+		Nonce:     rndstr.RandString(oidcStateLength),
+		Verifier:  verifier,
+		AccountID: accID,
+	}
+	data, err := json.Marshal(st)
+	if err != nil {
+		return "", err
+	}
+	if err = redis.SetEx(oidcStateKey+hashToken(state), data, int32(cfg.App.ChallengeLifetime)); err != nil {
+		return "", err
+	}
+	return p.AuthCodeURL(state, st.Nonce, challenge), nil
+}
+
+// takeOIDCState returns the state once, so the authorization response can't be replayed.
+// accID is id of signed in account finishing the link, state started by link is only taken
+// by the account which started it and state of sign in is only taken without account.
+func takeOIDCState(state string, accID int) (*oidcState, error) {
+	key := oidcStateKey + hashToken(state)
+	data, err := redis.Get(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		return nil, errOIDCState
+	}
+	st := new(oidcState)
+	if err = json.Unmarshal(data, st); err != nil {
+		return nil, err
+	}
+	if st.AccountID != accID {
+		return nil, errOIDCState
+	}
+	if err = redis.Delete(key); err != nil {
+		return nil, err
+	}
+	return st, nil
+}
+
+// oidcSignIn exchanges authorization code and returns account of the identity with roles loaded,
+// linkAccID is id of signed in account linking the identity.
+func oidcSignIn(c *gin.Context, code, state string, linkAccID int) (Account, error) {
+	st, err := takeOIDCState(state, linkAccID)
+	if err != nil {
+		return Account{}, err
+	}
+	p, err := oidcProvider()
+	if err != nil {
+		return Account{}, err
+	}
+	idt, err := p.Exchange(code, st.Verifier, st.Nonce)
+	if err != nil {
+		return Account{}, err
+	}
+
+	acc, err := resolveIdentity(p.Issuer, idt, st.AccountID)
+	if err != nil {
+		return Account{}, err
+	}
+	if err = syncMappedRoles(c, acc.ID, idt.Groups); err != nil {
+		return Account{}, err
+	}
+	err = db.GetDB().Where("account_id = ?", acc.ID).Find(&acc.Roles).Error
+	return acc, err
+}
+
+// resolveIdentity finds account of the identity. Unknown identity is linked to the account
+// being linked, to the account with the same verified e-mail or to just provisioned account.
+func resolveIdentity(issuer string, idt *oidc.IDToken, linkAccID int) (Account, error) {
+	var ident Identity
+	err := db.GetDB().Where("issuer = ? and subject = ?", issuer, idt.Subject).First(&ident).Error
+	switch {
+	case err == nil:
+		if linkAccID != 0 && ident.AccountID != linkAccID {
+			return Account{}, errIdentityTaken
+		}
+		return activeAccount(ident.AccountID)
+	case !gorm.IsRecordNotFoundError(err):
+		return Account{}, err
+	}
+
+	ident = Identity{Issuer: issuer, Subject: idt.Subject, Email: strings.ToLower(idt.Email)}
+	if linkAccID != 0 {
+		ident.AccountID = linkAccID
+		if err = db.GetDB().Create(&ident).Error; err != nil {
+			return Account{}, err
+		}
+		return activeAccount(linkAccID)
+	}
+
+	if ident.Email == "" {
+		return Account{}, errIdentityEmail
+	}
+	acc, err := FindAccount(Account{Email: ident.Email})
+	if err != nil && !gorm.IsRecordNotFoundError(err) {
+		return Account{}, err
+	}
+	if acc.ID != 0 && acc.Status != AccStatusActive && acc.Status != AccStatusUnconfirmed {
+		return Account{}, errIdentityNotActive
+	}
+
+	dbt := db.GetDB().Begin()
+	if acc.ID != 0 {
+		// unverified e-mail of identity provider can't take over existing account
+		if !idt.EmailVerified {
+			dbt.Rollback()
+			return Account{}, errIdentityEmail
+		}
+		// the provider confirms the e-mail, password of unconfirmed sign up could be set by anyone
+		// who typed the e-mail, so it is dropped with pending sign up tokens
+		if acc.Status == AccStatusUnconfirmed {
+			acc.Status = AccStatusActive
+			acc.PasswordHash = ""
+			err = dbt.Model(&acc).Updates(map[string]interface{}{"status": acc.Status, "password_hash": ""}).Error
+			if err == nil {
+				err = DropAccountSignUpTokens(dbt, acc.ID)
+			}
+			if err != nil {
+				dbt.Rollback()
+				return Account{}, err
+			}
+		}
+	} else {
+		acc = Account{Email: ident.Email, Name: idt.Name, Status: AccStatusActive}
+		if err = dbt.Create(&acc).Error; err != nil {
+			dbt.Rollback()
+			return Account{}, err
+		}
+		if err = createPersonalOrganization(dbt, acc); err != nil {
+			dbt.Rollback()
+			return Account{}, err
+		}
+	}
+	ident.AccountID = acc.ID
+	if err = dbt.Create(&ident).Error; err != nil {
+		dbt.Rollback()
+		return Account{}, err
+	}
+	err = dbt.Commit().Error
+	return acc, err
+}
+
+func activeAccount(accID int) (Account, error) {
+	acc, err := FindAccount(Account{ID: accID})
+	if err != nil {
+		return Account{}, err
+	}
+	if acc.Status != AccStatusActive {
+		return Account{}, errIdentityNotActive
+	}
+	return acc, nil
+}
+
+// syncMappedRoles grants roles mapped from groups of the identity and revokes other mapped roles,
+// roles missing in the mapping are left as is.
+func syncMappedRoles(c *gin.Context, accID int, groups []string) error {
+	if len(cfg.App.OIDCRoleMap) == 0 {
+		return nil
+	}
+	member := make(map[string]bool, len(groups))
+	for _, g := range groups {
+		member[g] = true
+	}
+
+	granted := map[int]bool{}
+	mapped := map[int]bool{}
+	for group, name := range cfg.App.OIDCRoleMap {
+		role := GetRole(name)
+		if role == 0 {
+			log.Println("Unknown role in OIDC role map", name)
+			continue
+		}
+		mapped[role] = true
+		if member[group] {
+			granted[role] = true
+		}
+	}
+
+	var current []AccountRole
+	if err := db.GetDB().Where("account_id = ?", accID).Find(&current).Error; err != nil {
+		return err
+	}
+	has := make(map[int]bool, len(current))
+	for _, r := range current {
+		has[r.Role] = true
+	}
+
+	for role := range mapped {
+		action := ""
+		switch {
+		case granted[role] && !has[role]:
+			if err := GrantRole(accID, role); err != nil {
+				return err
+			}
+			action = audit.AccountRoleGrant
+		case !granted[role] && has[role]:
+			if _, err := RevokeRole(accID, role); err != nil {
+				return err
+			}
+			action = audit.AccountRoleRevoke
+		default:
+			continue
+		}
+		audit.Record(c, audit.Event{
+			Action:     action,
+			TargetType: audit.TargetAccount,
+			TargetID:   accID,
+			Details:    map[string]interface{}{"role": AccountRole{Role: role}.GetStrRole(), "source": "oidc"},
+		})
+	}
+	return nil
+}
+
+func ListIdentities(accID int) ([]Identity, error) {
+	var idents []Identity
+	err := db.GetDB().Where("account_id = ?", accID).Order("created_at").Find(&idents).Error
+	return idents, err
+}
@@ -0,0 +1,128 @@
+package account
+
+import (
+	"errors"
+	"oko/pkg/cfg"
+	"oko/pkg/db"
+	"oko/pkg/ginapp/types"
+	"oko/pkg/log"
+	"oko/pkg/mail"
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"github.com/lib/pq"
+)
+
+// Invite allows to sign up with the e-mail, the account is active at once and gets preset roles.
+// Invite of organization owner adds the account to the organization instead of granting roles.
+type Invite struct {
+	ID             int           `json:"id"`
+	Email          string        `json:"email"`
+	Token          string        `json:"-" gorm:"-"`
+	TokenHash      string        `json:"-"`
+	Roles          pq.Int64Array `json:"roles" gorm:"type:integer[]"`
+	OrganizationID *uint         `json:"organization_id"`
+	OrgRole        string        `json:"org_role"`
+	InvitedBy      int           `json:"invited_by"`
+	IsUsed         bool          `json:"is_used"`
+	ExpireAt       time.Time     `json:"expire_at"`
+	CreatedAt      time.Time     `json:"created_at"`
+	UpdatedAt      time.Time     `json:"updated_at"`
+}
+
+func (Invite) TableName() string {
+	return "invite"
+}
+
+// NewInvite returns invite with plain token to be sent, only hash of the token is stored.
+func NewInvite(email string, invitedBy int) Invite {
+	t := newToken(tokenKindInvite)
+	return Invite{
+		Email:     email,
+		Roles:     pq.Int64Array{},
+		Token:     t,
+		TokenHash: hashToken(t),
+		InvitedBy: invitedBy,
+		ExpireAt:  time.Now().Add(time.Second * time.Duration(cfg.App.InviteTokenLifetime)),
+	}
+}
+
+// CreateInvite stores the invite and sends it by e-mail on behalf of the inviter.
+func CreateInvite(inv *Invite, inviter Account) error {
+	if err := db.GetDB().Create(inv).Error; err != nil {
+		return err
+	}
+	if err := inv.SendInvite(inviter.Name); err != nil {
+		log.Println("Fail to send invite", err)
+	}
+	return nil
+}
+
+func ToInviteView(inv Invite) types.Invite {
+	roles := make([]types.RoleItem, 0, len(inv.Roles))
+	for _, r := range inv.Roles {
+		role := AccountRole{Role: int(r)}
+		roles = append(roles, types.RoleItem{Role: role.Role, StrRole: role.GetStrRole()})
+	}
+	return types.Invite{
+		ID:             inv.ID,
+		Email:          inv.Email,
+		Roles:          roles,
+		OrganizationID: inv.OrganizationID,
+		OrgRole:        inv.OrgRole,
+		ExpireAt:       inv.ExpireAt,
+		CreatedAt:      inv.CreatedAt,
+	}
+}
+
+func GetByInviteToken(token string) (*Invite, error) {
+	inv := new(Invite)
+	if err := db.GetDB().
+		Where("token_hash=? and expire_at >= now() and not is_used", hashToken(token)).
+		First(inv).Error; err != nil {
+		return nil, err
+	}
+	if !matchToken(token, inv.TokenHash) {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return inv, nil
+}
+
+// Use marks invite as used, fails if invite was used concurrently.
+func (inv *Invite) Use(dbt *gorm.DB) error {
+	res := dbt.Model(&Invite{}).Where("id = ? and not is_used", inv.ID).Update("is_used", true)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return errors.New("invite already used")
+	}
+	inv.IsUsed = true
+	return nil
+}
+
+// Accept grants preset roles and organization membership of the invite to the account.
+func (inv Invite) Accept(dbt *gorm.DB, accID int) error {
+	for _, role := range inv.Roles {
+		if err := dbt.Create(&AccountRole{AccountID: accID, Role: int(role)}).Error; err != nil {
+			return err
+		}
+	}
+	if inv.OrganizationID != nil {
+		return addMember(dbt, *inv.OrganizationID, accID, inv.OrgRole)
+	}
+	return nil
+}
+
+func (inv Invite) SendInvite(inviterName string) error {
+	return mail.Send(mail.Message{
+		To:       inv.Email,
+		Subject:  InviteSubject,
+		Template: InviteTmpl,
+		Data:     map[string]interface{}{"Inviter": inviterName, "Link": inv.getLink()},
+	})
+}
+
+func (inv Invite) getLink() string {
+	return cfg.App.FrontURL + InviteLink + "/" + inv.Token
+}
@@ -0,0 +1,204 @@
+package account
+
+import (
+	"encoding/json"
+	"errors"
+	"oko/pkg/cfg"
+	"oko/pkg/db"
+	"oko/pkg/ginapp/types"
+	"oko/pkg/jwt"
+	"oko/pkg/redis"
+	"oko/pkg/rndstr"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const refreshFamilyLength = 32
+
+var (
+	errRefreshInvalid = errors.New("invalid refresh token")
+	errRefreshReused  = errors.New("refresh token reused")
+)
+
+// AccessClaims are claims of jwt access token, they carry everything Auth needs,
+// so the token is verified without storage lookups. Family is the session of the token.
+type AccessClaims struct {
+	jwt.StandardClaims
+	Status uint   `json:"status"`
+	Roles  []int  `json:"roles"`
+	Family string `json:"sid"`
+}
+
+// refreshRecord is stored by refresh token hash until the token expires, used tokens are kept
+// to detect their reuse.
+type refreshRecord struct {
+	AccountID int    `json:"account_id"`
+	Family    string `json:"family"`
+}
+
+var (
+	signerOnce sync.Once
+	signer     *jwt.Signer
+)
+
+func isJWTMode() bool {
+	return cfg.App.AuthMode == cfg.AuthModeJWT
+}
+
+func jwtSigner() *jwt.Signer {
+	signerOnce.Do(func() {
+		keys := make([]jwt.Key, 0, len(cfg.App.JWTKeys))
+		for _, k := range cfg.App.JWTKeys {
+			keys = append(keys, jwt.Key{ID: k.ID, Secret: []byte(k.Secret)})
+		}
+		signer = jwt.NewSigner(keys, jwt.SystemClock{})
+	})
+	return signer
+}
+
+func parseAccessToken(token string) (*AccessClaims, error) {
+	claims := &AccessClaims{}
+	if err := jwtSigner().Parse(token, claims); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+func newAccessToken(acc Account, family string) (string, error) {
+	now := time.Now()
+	roles := make([]int, 0, len(acc.Roles))
+	for _, r := range acc.Roles {
+		roles = append(roles, r.Role)
+	}
+	return jwtSigner().Sign(AccessClaims{
+		StandardClaims: jwt.StandardClaims{
+			Subject:   strconv.Itoa(acc.ID),
+			IssuedAt:  now.Unix(),
+			ExpiresAt: now.Add(time.Duration(cfg.App.AccessTokenLifetime) * time.Second).Unix(),
+		},
+		Status: acc.Status,
+		Roles:  roles,
+		Family: family,
+	})
+}
+
+// issueTokenPair returns access token and the next refresh token of the family.
+func issueTokenPair(acc Account, family string) (types.ResponseAccessToken, error) {
+	access, err := newAccessToken(acc, family)
+	if err != nil {
+		return types.ResponseAccessToken{}, err
+	}
+
+	refresh := newToken(tokenKindRefresh)
+	data, err := json.Marshal(refreshRecord{AccountID: acc.ID, Family: family})
+	if err != nil {
+		return types.ResponseAccessToken{}, err
+	}
+	lifetime := int32(cfg.App.RefreshTokenLifetime)
+	if err = redis.SetEx(refreshTokenKey+hashToken(refresh), data, lifetime); err != nil {
+		return types.ResponseAccessToken{}, err
+	}
+	if err = redis.SetEx(refreshFamilyKey+family, []byte(strconv.Itoa(acc.ID)), lifetime); err != nil {
+		return types.ResponseAccessToken{}, err
+	}
+
+	return types.ResponseAccessToken{
+		AccessToken:  access,
+		RefreshToken: refresh,
+		ExpiresIn:    cfg.App.AccessTokenLifetime,
+	}, nil
+}
+
+// startTokenFamily starts new session of jwt mode.
+func startTokenFamily(acc Account, c *gin.Context) (types.ResponseAccessToken, error) {
+	// roles are put into access token, so they are loaded even if the caller didn't preload them
+	if err := db.GetDB().Where("account_id = ?", acc.ID).Find(&acc.Roles).Error; err != nil {
+		return types.ResponseAccessToken{}, err
+	}
+	family := rndstr.RandString(refreshFamilyLength)
+	res, err := issueTokenPair(acc, family)
+	if err != nil {
+		return res, err
+	}
+	now := time.Now()
+	err = redis.SaveSession(redis.Session{
+		ID:         redis.SessionID(family),
+		Family:     family,
+		AccountID:  acc.ID,
+		CreatedAt:  now,
+		LastSeenAt: now,
+		IP:         c.ClientIP(),
+		UserAgent:  c.Request.UserAgent(),
+	}, int32(cfg.App.RefreshTokenLifetime))
+	return res, err
+}
+
+// rotateRefreshToken exchanges refresh token for new token pair, each refresh token is accepted once.
+// Reuse of the token means it was stolen, so the whole family is revoked.
+func rotateRefreshToken(refresh string, c *gin.Context) (types.ResponseAccessToken, error) {
+	hash := hashToken(refresh)
+	data, err := redis.Get(refreshTokenKey + hash)
+	if err != nil {
+		return types.ResponseAccessToken{}, err
+	}
+	if len(data) == 0 {
+		return types.ResponseAccessToken{}, errRefreshInvalid
+	}
+	var rec refreshRecord
+	if err = json.Unmarshal(data, &rec); err != nil {
+		return types.ResponseAccessToken{}, err
+	}
+
+	first, err := redis.SetNX(refreshUsedKey+hash, []byte("1"), int32(cfg.App.RefreshTokenLifetime))
+	if err != nil {
+		return types.ResponseAccessToken{}, err
+	}
+	if !first {
+		if err = revokeTokenFamily(rec.AccountID, rec.Family); err != nil {
+			return types.ResponseAccessToken{}, err
+		}
+		return types.ResponseAccessToken{}, errRefreshReused
+	}
+
+	// the family is missing after sign out or revocation of the session
+	alive, err := redis.Exists(refreshFamilyKey + rec.Family)
+	if err != nil {
+		return types.ResponseAccessToken{}, err
+	}
+	if !alive {
+		return types.ResponseAccessToken{}, errRefreshInvalid
+	}
+
+	var acc Account
+	err = db.GetDB().Preload("Roles").First(&acc, rec.AccountID).Error
+	if err != nil || acc.Status != AccStatusActive {
+		if err = revokeTokenFamily(rec.AccountID, rec.Family); err != nil {
+			return types.ResponseAccessToken{}, err
+		}
+		return types.ResponseAccessToken{}, errRefreshInvalid
+	}
+
+	res, err := issueTokenPair(acc, rec.Family)
+	if err != nil {
+		return res, err
+	}
+	s, err := redis.GetSession(acc.ID, redis.SessionID(rec.Family))
+	if err != nil || s == nil {
+		return res, err
+	}
+	s.LastSeenAt = time.Now()
+	s.IP = c.ClientIP()
+	s.UserAgent = c.Request.UserAgent()
+	return res, redis.SaveSession(*s, int32(cfg.App.RefreshTokenLifetime))
+}
+
+// revokeTokenFamily drops the session of jwt mode, issued access tokens are valid till they expire.
+func revokeTokenFamily(accID int, family string) error {
+	if err := redis.Delete(refreshFamilyKey + family); err != nil {
+		return err
+	}
+	return redis.DeleteSession(accID, redis.SessionID(family))
+}
@@ -0,0 +1,109 @@
+package account
+
+import (
+	"oko/pkg/cfg"
+	"oko/pkg/log"
+	"oko/pkg/redis"
+	"strings"
+)
+
+const (
+	signInFailKey = "sign-in-fail:"
+	signInLockKey = "sign-in-lock:"
+)
+
+func emailLockoutSubject(email string) string {
+	return "email:" + strings.ToLower(email)
+}
+
+func ipLockoutSubject(ip string) string {
+	return "ip:" + ip
+}
+
+// signInRetryAfter returns seconds left until sign in is allowed for the e-mail from the ip,
+// 0 means sign in is not locked.
+func signInRetryAfter(email, ip string) int {
+	retryAfter := 0
+	for _, subject := range []string{emailLockoutSubject(email), ipLockoutSubject(ip)} {
+		ttl, err := redis.TTL(signInLockKey + subject)
+		if err != nil {
+			log.Println("Fail to check sign in lockout", err)
+			continue
+		}
+		if ttl > retryAfter {
+			retryAfter = ttl
+		}
+	}
+	return retryAfter
+}
+
+// registerSignInFailure counts failed sign in of the e-mail from the ip and locks sign in
+// when there are too many failures, returns lockout duration in seconds.
+func registerSignInFailure(email, ip string) int {
+	emailLock := registerFailure(emailLockoutSubject(email), cfg.App.SignInMaxFailures)
+	ipLock := registerFailure(ipLockoutSubject(ip), cfg.App.SignInMaxIPFailures)
+	if ipLock > emailLock {
+		return ipLock
+	}
+	return emailLock
+}
+
+// registerFailure increments failures counter of the subject, each failure over the limit
+// doubles lockout duration until it reaches cfg.App.SignInMaxLockout.
+func registerFailure(subject string, limit int) int {
+	failKey := signInFailKey + subject
+	failures, err := redis.Incr(failKey)
+	if err != nil {
+		log.Println("Fail to count sign in failure", err)
+		return 0
+	}
+	if failures < limit {
+		if failures == 1 {
+			if err = redis.Expire(failKey, int32(cfg.App.SignInFailureWindow)); err != nil {
+				log.Println("Fail to count sign in failure", err)
+			}
+		}
+		return 0
+	}
+
+	lockout := cfg.App.SignInLockout
+	for i := limit; i < failures && lockout < cfg.App.SignInMaxLockout; i++ {
+		lockout *= 2
+	}
+	if lockout > cfg.App.SignInMaxLockout {
+		lockout = cfg.App.SignInMaxLockout
+	}
+
+	if err = redis.SetEx(signInLockKey+subject, []byte{1}, int32(lockout)); err != nil {
+		log.Println("Fail to lock sign in", err)
+		return 0
+	}
+	// counter outlives the lockout, so the next failure after it doubles the lockout
+	if err = redis.Expire(failKey, int32(lockout+cfg.App.SignInFailureWindow)); err != nil {
+		log.Println("Fail to count sign in failure", err)
+	}
+	return lockout
+}
+
+func resetSignInFailures(email string) {
+	if err := redis.Delete(signInFailKey + emailLockoutSubject(email)); err != nil {
+		log.Println("Fail to reset sign in failures", err)
+	}
+}
+
+// ClearLockout removes sign in lockout and failures counter of the e-mail and optionally of the ip.
+func ClearLockout(email, ip string) error {
+	subjects := []string{emailLockoutSubject(email)}
+	if ip != "" {
+		subjects = append(subjects, ipLockoutSubject(ip))
+	}
+	for _, subject := range subjects {
+		if err := redis.Delete(signInLockKey + subject); err != nil {
+			return err
+		}
+		if err := redis.Delete(signInFailKey + subject); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+package account
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/jinzhu/gorm"
+)
+
+// Organization roles granted to accounts created here, org package defines the roles.
+const (
+	orgRoleOwner  = "owner"
+	orgRoleMember = "member"
+)
+
+// ActiveOrganizationID returns id of active organization of the request account,
+// org package sets it since it depends on account package.
+var ActiveOrganizationID func(c *gin.Context) (uint, error)
+
+func addMember(dbt *gorm.DB, orgID uint, accID int, role string) error {
+	return dbt.Exec(
+		"insert into organization_member (organization_id, account_id, role) values (?, ?, ?)",
+		orgID, accID, role,
+	).Error
+}
+
+// createPersonalOrganization creates organization owned by the new account,
+// the account works in it until joining other organizations.
+func createPersonalOrganization(dbt *gorm.DB, acc Account) error {
+	name := acc.Name
+	if name == "" {
+		name = acc.Email
+	}
+	var org struct{ ID uint }
+	if err := dbt.Raw("insert into organization (name) values (?) returning id", name).Scan(&org).Error; err != nil {
+		return err
+	}
+	return addMember(dbt, org.ID, acc.ID, orgRoleOwner)
+}
@@ -0,0 +1,199 @@
+package account
+
+import (
+	"net/http"
+	"oko/pkg/cfg"
+	"oko/pkg/db"
+	"oko/pkg/e"
+	"oko/pkg/ginapp/types"
+	"oko/pkg/log"
+	"oko/pkg/password"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Recover godoc
+// @Summary Recover password
+// @Description Send password recover token by e-mail, the response is the same for unknown e-mail.
+// @Description Could be called once per SIGN_UP_RESEND_INTERVAL for the e-mail
+// @ID post-account-recover
+// @Tags Account
+// @Accept json
+// @Produce json
+// @Param object body account.RecoverForm true "Recover fields"
+// @Success 200 {object} types.StdResponse
+// @Failure 400 {object} types.ResponseErrorSwg
+// @Failure 429 {object} types.ResponseErrorSwg
+// @Failure 500 {object} types.ResponseErrorSwg
+// @Router /account/recover [post]
+func Recover(c *gin.Context) {
+	var form RecoverForm
+
+	if err := c.ShouldBind(&form); err != nil {
+		e.ErrorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	email := strings.ToLower(form.Email)
+	// unknown e-mail is throttled as well
+	if !throttleEmail(c, recoverThrottleKey, email, "Recover token was sent recently, try again later") {
+		return
+	}
+
+	// unknown e-mail isn't reported, so registered e-mails can't be enumerated
+	acc, err := FindAccount(Account{Email: email})
+	if err != nil || acc.ID == 0 {
+		types.SuccessEmptyResponse(c)
+		return
+	}
+
+	rt := NewRecoverToken(acc.ID)
+	if err := db.GetDB().Create(&rt).Error; err != nil {
+		panic(err)
+	}
+
+	rt.Account = acc
+	if err := rt.SendRecoverToken(acc.Email); err != nil {
+		log.Println("Fail to send recover token", err)
+	}
+
+	types.SuccessEmptyResponse(c)
+}
+
+// RecoverConfirm godoc
+// @Summary Confirm password recover
+// @Description Set new password by recover token, all account sessions are revoked
+// @ID post-account-recover-confirm
+// @Tags Account
+// @Accept json
+// @Produce json
+// @Param object body account.RecoverConfirmForm true "Recover confirm fields"
+// @Success 200 {object} types.StdResponse
+// @Failure 400 {object} types.ResponseErrorSwg
+// @Failure 500 {object} types.ResponseErrorSwg
+// @Router /account/recover/confirm [post]
+func RecoverConfirm(c *gin.Context) {
+	var form RecoverConfirmForm
+
+	if err := c.ShouldBind(&form); err != nil {
+		e.ErrorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	rt, err := GetByRecoverToken(form.Token)
+	if err != nil || rt.Account.ID == 0 {
+		e.ErrorResponse(c, http.StatusBadRequest, "Invalid recover token")
+		return
+	}
+	acc := rt.Account
+	strong, err := password.Check(form.Password, acc.Email, acc.Name)
+	if err != nil {
+		e.ErrorResponse(c, http.StatusInternalServerError, "Something went wrong")
+		return
+	}
+	if !strong {
+		weakPasswordResponse(c)
+		return
+	}
+
+	dbt := db.GetDB().Begin()
+	if err := rt.Use(dbt); err != nil {
+		dbt.Rollback()
+		e.ErrorResponse(c, http.StatusBadRequest, "Recover token already used")
+		return
+	}
+	if err := changePassword(dbt, &acc, form.Password); err != nil {
+		dbt.Rollback()
+		e.ErrorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+	if err := dbt.Commit().Error; err != nil {
+		panic(err)
+	}
+
+	if err := DropAccountTokens(acc.ID); err != nil {
+		panic(err)
+	}
+
+	types.SuccessEmptyResponse(c)
+}
+
+// weakPasswordResponse reports password rejected by the policy for the account identity,
+// the form validator checks it without identity.
+func weakPasswordResponse(c *gin.Context) {
+	e.ErrorResponse(c, http.StatusBadRequest, e.CustomFieldError{
+		Name:    "password",
+		Tag:     "strong_pass",
+		Param:   "",
+		Value:   "",
+		Message: cfg.App.Password.Describe(),
+	})
+}
+
+// ChangePassword godoc
+// @Summary Change password
+// @Description Change password of current account, all account sessions are revoked and new access token is issued
+// @ID post-account-change-password
+// @Tags Account
+// @Accept json
+// @Produce json
+// @Param object body account.ChangePasswordForm true "Change password fields"
+// @Success 200 {object} types.ResponseSignIn
+// @Failure 400 {object} types.ResponseErrorSwg
+// @Failure 500 {object} types.ResponseErrorSwg
+// @Router /account/change-password [post]
+// @Security ApiKeyAuth
+func ChangePassword(c *gin.Context) {
+	var form ChangePasswordForm
+
+	if err := c.ShouldBind(&form); err != nil {
+		e.ErrorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	acc := GetContextAcc(c)
+	if err := acc.CheckPassword(form.OldPassword); err != nil {
+		e.ErrorResponse(c, http.StatusBadRequest, e.CustomFieldError{
+			Name:    "old_password",
+			Tag:     "old_password",
+			Param:   "",
+			Value:   "",
+			Message: "Invalid password",
+		})
+		return
+	}
+	strong, err := password.Check(form.Password, acc.Email, acc.Name)
+	if err != nil {
+		e.ErrorResponse(c, http.StatusInternalServerError, "Something went wrong")
+		return
+	}
+	if !strong {
+		weakPasswordResponse(c)
+		return
+	}
+
+	if err := changePassword(db.GetDB(), &acc, form.Password); err != nil {
+		e.ErrorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := DropAccountTokens(acc.ID); err != nil {
+		panic(err)
+	}
+	tokens, err := IssueTokens(acc, c)
+	if err != nil {
+		panic(err)
+	}
+
+	c.JSON(
+		http.StatusOK,
+		types.ResponseSignIn{
+			StdResponse: types.StdResponse{
+				Status:  e.Success,
+				Message: e.GetMsg(e.Success),
+			},
+			Data: tokens,
+		},
+	)
+}
@@ -0,0 +1,171 @@
+package account
+
+import (
+	"net/http"
+	"oko/pkg/db"
+	"oko/pkg/e"
+	"oko/pkg/ginapp/types"
+	"oko/pkg/log"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+func toProfileView(acc Account) types.Profile {
+	return types.Profile{
+		ID:        acc.ID,
+		Email:     acc.Email,
+		Name:      acc.Name,
+		Status:    acc.Status,
+		StatusStr: acc.GetStrStatus(),
+		CreatedAt: acc.CreatedAt,
+		UpdatedAt: acc.UpdatedAt,
+
+		TwoFactorEnabled: acc.TotpEnabled,
+	}
+}
+
+// UpdateProfile godoc
+// @Summary Update account profile
+// @Description Update profile fields of current account, e-mail is changed with change-email flow
+// @ID put-account-profile
+// @Tags Account
+// @Accept json
+// @Produce json
+// @Param object body account.ProfileForm true "Profile fields"
+// @Success 200 {object} types.ResponseProfile
+// @Failure 400 {object} types.ResponseErrorSwg
+// @Failure 500 {object} types.ResponseErrorSwg
+// @Router /account/profile [put]
+// @Security ApiKeyAuth
+func UpdateProfile(c *gin.Context) {
+	var form ProfileForm
+
+	if err := c.ShouldBind(&form); err != nil {
+		e.ErrorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	acc := GetContextAcc(c)
+	if err := acc.Update(map[string]interface{}{"name": form.Name}); err != nil {
+		e.ErrorResponse(c, http.StatusInternalServerError, "Something went wrong")
+		return
+	}
+
+	types.SuccessResponse(c, toProfileView(acc))
+}
+
+// ChangeEmail godoc
+// @Summary Change e-mail
+// @Description Send confirmation token to the new e-mail, e-mail is changed after confirmation only.
+// @Description Account without password, provisioned by identity provider, has to sign in again instead.
+// @ID post-account-change-email
+// @Tags Account
+// @Accept json
+// @Produce json
+// @Param object body account.ChangeEmailForm true "Change e-mail fields"
+// @Success 200 {object} types.StdResponse
+// @Failure 400 {object} types.ResponseErrorSwg
+// @Failure 500 {object} types.ResponseErrorSwg
+// @Router /account/change-email [post]
+// @Security ApiKeyAuth
+func ChangeEmail(c *gin.Context) {
+	var form ChangeEmailForm
+
+	if err := c.ShouldBind(&form); err != nil {
+		e.ErrorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	acc := GetContextAcc(c)
+	if !confirmAccount(c, acc, form.Password) {
+		return
+	}
+
+	ect := NewEmailChangeToken(acc.ID, strings.ToLower(form.Email))
+	dbt := db.GetDB().Begin()
+	if err := DropAccountEmailChangeTokens(dbt, acc.ID); err != nil {
+		dbt.Rollback()
+		panic(err)
+	}
+	if err := dbt.Create(&ect).Error; err != nil {
+		dbt.Rollback()
+		panic(err)
+	}
+	if err := dbt.Commit().Error; err != nil {
+		panic(err)
+	}
+
+	ect.Account = acc
+	if err := ect.SendToken(); err != nil {
+		log.Println("Fail to send email change token", err)
+	}
+
+	types.SuccessEmptyResponse(c)
+}
+
+// ChangeEmailConfirm godoc
+// @Summary Confirm e-mail change
+// @Description Switch account e-mail to the confirmed one, the previous e-mail is notified
+// @ID post-account-change-email-confirm
+// @Tags Account
+// @Accept json
+// @Produce json
+// @Param object body account.ChangeEmailConfirmForm true "Confirm e-mail change fields"
+// @Success 200 {object} types.ResponseProfile
+// @Failure 400 {object} types.ResponseErrorSwg
+// @Failure 500 {object} types.ResponseErrorSwg
+// @Router /account/change-email/confirm [post]
+func ChangeEmailConfirm(c *gin.Context) {
+	var form ChangeEmailConfirmForm
+
+	if err := c.ShouldBind(&form); err != nil {
+		e.ErrorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	ect, err := GetByEmailChangeToken(form.Token)
+	if err != nil || ect.Account.ID == 0 {
+		e.ErrorResponse(c, http.StatusBadRequest, "Invalid email change token")
+		return
+	}
+	acc := ect.Account
+	oldEmail := acc.Email
+
+	dbt := db.GetDB().Begin()
+	if err := ect.Use(dbt); err != nil {
+		dbt.Rollback()
+		e.ErrorResponse(c, http.StatusBadRequest, "Invalid email change token")
+		return
+	}
+	// the e-mail could be taken by another account since the token was sent
+	var count int
+	if err := dbt.Model(&Account{}).Where("email = ? and id <> ?", ect.Email, acc.ID).Count(&count).Error; err != nil {
+		dbt.Rollback()
+		panic(err)
+	}
+	if count > 0 {
+		dbt.Rollback()
+		e.ErrorResponse(c, http.StatusBadRequest, e.CustomFieldError{
+			Name:    "email",
+			Tag:     "UniqueEmail",
+			Param:   "",
+			Value:   ect.Email,
+			Message: "Email already exist",
+		})
+		return
+	}
+	if err := dbt.Model(&acc).Update("email", ect.Email).Error; err != nil {
+		dbt.Rollback()
+		panic(err)
+	}
+	if err := dbt.Commit().Error; err != nil {
+		panic(err)
+	}
+
+	if err := acc.SendEmailChanged(oldEmail); err != nil {
+		log.Println("Fail to send email changed notice", err)
+	}
+
+	types.SuccessResponse(c, toProfileView(acc))
+}
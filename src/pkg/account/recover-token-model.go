@@ -1,17 +1,20 @@
 package account
 
 import (
-	"fmt"
+	"errors"
 	"oko/pkg/cfg"
 	"oko/pkg/db"
-	"oko/pkg/rndstr"
+	"oko/pkg/mail"
 	"time"
+
+	"github.com/jinzhu/gorm"
 )
 
 type RecoverToken struct {
 	ID        int       `json:"id"`
 	AccountID int       `json:"account_id"`
-	Token     string    `json:"token"`
+	Token     string    `json:"-" gorm:"-"`
+	TokenHash string    `json:"-"`
 	IsUsed    bool      `json:"is_used"`
 	ExpireAt  time.Time `json:"expire_at"`
 	UsedAt    time.Time `json:"used_at"`
@@ -24,31 +27,65 @@ func (RecoverToken) TableName() string {
 	return "recover_token"
 }
 
-func GenerateRecoverToken(accID int) string {
-	return fmt.Sprintf("rt%d-%s", accID, rndstr.RandString(cfg.App.AuthTokenLength))
+func GenerateRecoverToken() string {
+	return newToken(tokenKindRecover)
+}
+
+// NewRecoverToken returns token with plain value to be sent, only hash of the value is stored.
+func NewRecoverToken(accID int) RecoverToken {
+	t := GenerateRecoverToken()
+	return RecoverToken{
+		AccountID: accID,
+		Token:     t,
+		TokenHash: hashToken(t),
+		ExpireAt:  time.Now().Add(time.Second * time.Duration(cfg.App.RecoverTokenLifetime)),
+	}
 }
 
 func GetByRecoverToken(token string) (*RecoverToken, error) {
 	rt := new(RecoverToken)
 	if err := db.GetDB().
 		Preload("Account").
-		Where("token=? and expire_at >= now() and not is_used", token).
+		Where("token_hash=? and expire_at >= now() and not is_used", hashToken(token)).
 		First(rt).Error; err != nil {
 		return nil, err
 	}
+	if !matchToken(token, rt.TokenHash) {
+		return nil, gorm.ErrRecordNotFound
+	}
 	return rt, nil
 }
 
 func GetRecoverToken(token string) (*RecoverToken, error) {
 	rt := new(RecoverToken)
 	if err := db.GetDB().
-		Where("token=?", token).
+		Where("token_hash=?", hashToken(token)).
 		First(rt).Error; err != nil {
 		return nil, err
 	}
+	if !matchToken(token, rt.TokenHash) {
+		return nil, gorm.ErrRecordNotFound
+	}
 	return rt, nil
 }
 
+// Use marks token as used, fails if token was used concurrently.
+func (rt *RecoverToken) Use(dbt *gorm.DB) error {
+	now := time.Now()
+	res := dbt.Model(&RecoverToken{}).
+		Where("id = ? and not is_used", rt.ID).
+		Updates(map[string]interface{}{"is_used": true, "used_at": now})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return errors.New("recover token already used")
+	}
+	rt.IsUsed = true
+	rt.UsedAt = now
+	return nil
+}
+
 func (rt RecoverToken) SendChangeToken(email string) error {
 	return rt.sendToken(email, RecoverTmpl, ChangePasswordSubject)
 }
@@ -58,11 +95,14 @@ func (rt RecoverToken) SendRecoverToken(email string) error {
 }
 
 func (rt RecoverToken) sendToken(email, tmpl, subject string) error {
-	// TODO: Send message
-	return nil
+	return mail.Send(mail.Message{
+		To:       email,
+		Subject:  subject,
+		Template: tmpl,
+		Data:     map[string]interface{}{"Name": rt.Account.Name, "Link": rt.getLink()},
+	})
 }
 
-//nolint //TODO unused
 func (rt RecoverToken) getLink() string {
 	return cfg.App.FrontURL + RecoverLink + "/" + rt.Token
 }
@@ -1,23 +1,50 @@
 //nolint:unparam
 package account
 
+import (
+	"oko/pkg/db"
+	"oko/pkg/log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/patrickmn/go-cache"
+)
+
+// Ids of the roles seeded by migration.
 const (
 	AccRoleAdmin int = 1
 	AccRoleOper  int = 2
 )
 
-var RolesInclude = map[int][]int{
-	AccRoleAdmin: {AccRoleOper},
+// rolesCacheTTL limits how long changes of role definitions take to apply.
+const rolesCacheTTL = time.Minute
+
+const rolesCacheKey = "roles"
+
+var rolesCache = cache.New(rolesCacheTTL, 2*rolesCacheTTL)
+
+type Permission struct {
+	ID          int    `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+func (Permission) TableName() string {
+	return "permission"
 }
 
-var Roles = map[int]string{
-	AccRoleAdmin: "admin",
-	AccRoleOper:  "operator",
+// Role grants its permissions and the permissions of included roles.
+type Role struct {
+	ID          int          `json:"id"`
+	Name        string       `json:"name"`
+	Permissions []Permission `json:"permissions" gorm:"many2many:role_permission"`
+	Includes    []Role       `json:"includes" gorm:"many2many:role_include;association_jointable_foreignkey:included_role_id"` //nolint
 }
 
-var RevRoles = map[string]int{
-	"admin":    AccRoleAdmin,
-	"operator": AccRoleOper,
+func (Role) TableName() string {
+	return "role"
 }
 
 //nolint
@@ -31,21 +58,132 @@ func (AccountRole) TableName() string {
 }
 
 func (model AccountRole) GetStrRole() string {
-	return Roles[model.Role]
+	roles, err := ListRoles()
+	if err != nil {
+		return ""
+	}
+	for _, r := range roles {
+		if r.ID == model.Role {
+			return r.Name
+		}
+	}
+	return ""
+}
+
+// ListRoles returns all roles with their own permissions and included roles.
+func ListRoles() ([]Role, error) {
+	if roles, ok := rolesCache.Get(rolesCacheKey); ok {
+		return roles.([]Role), nil
+	}
+	var roles []Role
+	err := db.GetDB().Preload("Permissions").Preload("Includes").Order("id").Find(&roles).Error
+	if err != nil {
+		return nil, err
+	}
+	rolesCache.SetDefault(rolesCacheKey, roles)
+	return roles, nil
 }
 
+// GetRole returns id of the role by its name, 0 for unknown role.
 func GetRole(roleName string) int {
-	return RevRoles[roleName]
+	roles, err := ListRoles()
+	if err != nil {
+		log.Println("Fail to load roles", err)
+		return 0
+	}
+	for _, r := range roles {
+		if r.Name == roleName {
+			return r.ID
+		}
+	}
+	return 0
 }
 
+// GetIncludedRoles returns all roles included by the role directly or through other roles.
 func GetIncludedRoles(role int) []int {
-	var roles = RolesInclude[role]
+	key := "included:" + strconv.Itoa(role)
+	if roles, ok := rolesCache.Get(key); ok {
+		return roles.([]int)
+	}
+
 	var allRoles []int
-	allRoles = append(allRoles, roles...)
-	if len(roles) > 0 {
-		for _, r := range roles {
-			allRoles = append(allRoles, GetIncludedRoles(r)...)
-		}
+	err := db.GetDB().Raw(`
+		with recursive included(id) as (
+			select included_role_id from role_include where role_id = ?
+			union
+			select ri.included_role_id from role_include ri join included i on ri.role_id = i.id
+		)
+		select id from included`, role).
+		Pluck("id", &allRoles).Error
+	if err != nil {
+		log.Println("Fail to load included roles", err)
+		return allRoles
 	}
+	rolesCache.SetDefault(key, allRoles)
 	return allRoles
 }
+
+// GetRolesPermissions returns names of the permissions granted by the roles including inherited ones.
+func GetRolesPermissions(roles []int) ([]string, error) {
+	if len(roles) == 0 {
+		return nil, nil
+	}
+	ids := make([]string, 0, len(roles))
+	for _, r := range roles {
+		ids = append(ids, strconv.Itoa(r))
+	}
+	sort.Strings(ids)
+	key := "permissions:" + strings.Join(ids, ",")
+	if perms, ok := rolesCache.Get(key); ok {
+		return perms.([]string), nil
+	}
+
+	var perms []string
+	err := db.GetDB().Raw(`
+		with recursive granted(id) as (
+			select id from role where id in (?)
+			union
+			select ri.included_role_id from role_include ri join granted g on ri.role_id = g.id
+		)
+		select distinct p.name from permission p
+		join role_permission rp on rp.permission_id = p.id
+		join granted g on g.id = rp.role_id`, roles).
+		Pluck("name", &perms).Error
+	if err != nil {
+		return nil, err
+	}
+	rolesCache.SetDefault(key, perms)
+	return perms, nil
+}
+
+// HasPermission reports if any role of the account grants the permission.
+func (a Account) HasPermission(perm string) bool {
+	roles := make([]int, 0, len(a.Roles))
+	for _, r := range a.Roles {
+		roles = append(roles, r.Role)
+	}
+	perms, err := GetRolesPermissions(roles)
+	if err != nil {
+		log.Println("Fail to load account permissions", err)
+		return false
+	}
+	for _, p := range perms {
+		if p == perm {
+			return true
+		}
+	}
+	return false
+}
+
+// GrantRole adds the role to the account, granting already granted role is not an error.
+func GrantRole(accID, role int) error {
+	return db.GetDB().
+		Where(AccountRole{AccountID: accID, Role: role}).
+		FirstOrCreate(&AccountRole{}).Error
+}
+
+// RevokeRole removes the role from the account, returns false if the role wasn't granted.
+func RevokeRole(accID, role int) (bool, error) {
+	res := db.GetDB().Where("account_id = ? and role = ?", accID, role).Delete(&AccountRole{})
+	return res.RowsAffected > 0, res.Error
+}
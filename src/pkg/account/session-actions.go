@@ -0,0 +1,154 @@
+package account
+
+import (
+	"net/http"
+	"oko/pkg/audit"
+	"oko/pkg/cfg"
+	"oko/pkg/e"
+	"oko/pkg/ginapp/types"
+	"oko/pkg/redis"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Sessions godoc
+// @Summary List sessions
+// @Description List active sessions of current account
+// @ID get-account-sessions
+// @Tags Account
+// @Accept json
+// @Produce json
+// @Success 200 {object} types.ResponseSessions
+// @Failure 500 {object} types.ResponseErrorSwg
+// @Router /account/sessions [get]
+// @Security ApiKeyAuth
+func Sessions(c *gin.Context) {
+	accID := GetContextAccID(c)
+	sessions, err := redis.GetSessions(accID, sessionLifetime())
+	if err != nil {
+		e.ErrorResponse(c, http.StatusInternalServerError, "Something went wrong")
+		return
+	}
+
+	currentID := currentSessionID(c)
+	data := make([]types.Session, 0, len(sessions))
+	for _, s := range sessions {
+		data = append(data, types.Session{
+			ID:         s.ID,
+			CreatedAt:  s.CreatedAt,
+			LastSeenAt: s.LastSeenAt,
+			IP:         s.IP,
+			UserAgent:  s.UserAgent,
+			Current:    s.ID == currentID,
+		})
+	}
+
+	types.SuccessResponse(c, data)
+}
+
+// DropSession godoc
+// @Summary Revoke session
+// @Description Revoke session of current account
+// @ID delete-account-session
+// @Tags Account
+// @Accept json
+// @Produce json
+// @Param id path string true "Session ID"
+// @Success 200 {object} types.StdResponse
+// @Failure 404 {object} types.ResponseErrorSwg
+// @Failure 500 {object} types.ResponseErrorSwg
+// @Router /account/sessions/{id} [delete]
+// @Security ApiKeyAuth
+func DropSession(c *gin.Context) {
+	accID := GetContextAccID(c)
+	s, err := redis.GetSession(accID, c.Param("id"))
+	if err != nil {
+		e.ErrorResponse(c, http.StatusInternalServerError, "Something went wrong")
+		return
+	}
+	if s == nil {
+		e.ErrorResponse(c, http.StatusNotFound, "Session not found")
+		return
+	}
+	if err = dropSession(*s); err != nil {
+		e.ErrorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	types.SuccessEmptyResponse(c)
+}
+
+// SignOutAll godoc
+// @Summary Sign out everywhere
+// @Description Revoke all sessions of current account
+// @ID post-account-sign-out-all
+// @Tags Account
+// @Accept json
+// @Produce json
+// @Success 200 {object} types.StdResponse
+// @Failure 500 {object} types.ResponseErrorSwg
+// @Router /account/sign-out-all [post]
+// @Security ApiKeyAuth
+func SignOutAll(c *gin.Context) {
+	accID := GetContextAccID(c)
+	if err := DropAccountTokens(accID); err != nil {
+		e.ErrorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.Header(cfg.App.AuthTokenKey, "")
+	audit.Record(c, audit.Event{Action: audit.AccountSignOutAll, TargetType: audit.TargetAccount, TargetID: accID})
+
+	types.SuccessEmptyResponse(c)
+}
+
+// Refresh godoc
+// @Summary Refresh access token
+// @Description Exchange refresh token for new access and refresh tokens, available in jwt token mode.
+// @Description Each refresh token is accepted once, reuse of the token revokes the session.
+// @ID post-account-refresh
+// @Tags Account
+// @Accept json
+// @Produce json
+// @Param object body account.RefreshForm true "Refresh token"
+// @Success 200 {object} types.ResponseSignIn
+// @Failure 400 {object} types.ResponseErrorSwg
+// @Failure 401 {object} types.ResponseErrorSwg
+// @Failure 500 {object} types.ResponseErrorSwg
+// @Router /account/refresh [post]
+func Refresh(c *gin.Context) {
+	var form RefreshForm
+
+	if err := c.ShouldBind(&form); err != nil {
+		e.ErrorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+	if !isJWTMode() {
+		e.ErrorResponse(c, http.StatusBadRequest, "Refresh tokens are not used")
+		return
+	}
+
+	tokens, err := rotateRefreshToken(form.RefreshToken, c)
+	switch err {
+	case nil:
+	case errRefreshInvalid:
+		e.ErrorResponse(c, e.ErrorAuthCheckTokenFail, "Invalid refresh token")
+		return
+	case errRefreshReused:
+		e.ErrorResponse(c, e.ErrorAuthRefreshReused, "Refresh token reused")
+		return
+	default:
+		e.ErrorResponse(c, http.StatusInternalServerError, "Something went wrong")
+		return
+	}
+
+	c.JSON(
+		http.StatusOK,
+		types.ResponseSignIn{
+			StdResponse: types.StdResponse{
+				Status:  e.Success,
+				Message: e.GetMsg(e.Success),
+			},
+			Data: tokens,
+		},
+	)
+}
@@ -1,17 +1,20 @@
 package account
 
 import (
-	"fmt"
+	"errors"
 	"oko/pkg/cfg"
 	"oko/pkg/db"
-	"oko/pkg/rndstr"
+	"oko/pkg/mail"
 	"time"
+
+	"github.com/jinzhu/gorm"
 )
 
 type SignUpToken struct {
 	ID        int        `json:"id"`
 	AccountID int        `json:"account_id"`
-	Token     string     `json:"token"`
+	Token     string     `json:"-" gorm:"-"`
+	TokenHash string     `json:"-"`
 	IsUsed    bool       `json:"is_used"`
 	ExpireAt  time.Time  `json:"expire_at"`
 	CreatedAt time.Time  `json:"created_at"`
@@ -24,27 +27,62 @@ func (SignUpToken) TableName() string {
 	return "sign_up_token"
 }
 
-func GenerateSignUpToken(accID int) string {
-	return fmt.Sprintf("su%d-%s", accID, rndstr.RandString(cfg.App.AuthTokenLength))
+func GenerateSignUpToken() string {
+	return newToken(tokenKindSignUp)
+}
+
+// NewSignUpToken returns token with plain value to be sent, only hash of the value is stored.
+func NewSignUpToken(accID int) SignUpToken {
+	t := GenerateSignUpToken()
+	return SignUpToken{
+		AccountID: accID,
+		Token:     t,
+		TokenHash: hashToken(t),
+		ExpireAt:  time.Now().Add(time.Second * time.Duration(cfg.App.SignUpTokenLifetime)),
+	}
 }
 
 func GetBySignUpToken(token string) (*SignUpToken, error) {
 	var sut SignUpToken
 	if err := db.GetDB().
 		Preload("Account").
-		Where("token=? and expire_at >= now() and not is_used", token).
+		Where("token_hash=? and expire_at >= now() and not is_used", hashToken(token)).
 		First(&sut).Error; err != nil {
 		return nil, err
 	}
+	if !matchToken(token, sut.TokenHash) {
+		return nil, gorm.ErrRecordNotFound
+	}
 	return &sut, nil
 }
 
-func (sut SignUpToken) SendToken(email string) error {
-	//TODO: Send message
+// Use marks token as used, fails if token was used concurrently.
+func (sut *SignUpToken) Use(dbt *gorm.DB) error {
+	res := dbt.Model(&SignUpToken{}).Where("id = ? and not is_used", sut.ID).Update("is_used", true)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return errors.New("sign up token already used")
+	}
+	sut.IsUsed = true
 	return nil
 }
 
-//nolint //TODO unused
+// DropAccountSignUpTokens marks all unused sign up tokens of the account as used.
+func DropAccountSignUpTokens(dbt *gorm.DB, accID int) error {
+	return dbt.Model(&SignUpToken{}).Where("account_id = ? and not is_used", accID).Update("is_used", true).Error
+}
+
+func (sut SignUpToken) SendToken(email string) error {
+	return mail.Send(mail.Message{
+		To:       email,
+		Subject:  SignUpConfSubject,
+		Template: SignUpConfTmpl,
+		Data:     map[string]interface{}{"Name": sut.Account.Name, "Link": sut.getLink()},
+	})
+}
+
 func (sut SignUpToken) getLink() string {
 	return cfg.App.FrontURL + SignUpLink + "/" + sut.Token
 }
@@ -0,0 +1,32 @@
+package account
+
+import (
+	"oko/pkg/cfg"
+	"oko/pkg/token"
+)
+
+// Kinds of tokens, the kind is a part of token prefix.
+const (
+	tokenKindAuth        = "at"
+	tokenKindSignUp      = "su"
+	tokenKindRecover     = "rt"
+	tokenKindEmailChange = "ec"
+	tokenKindInvite      = "iv"
+	tokenKindChallenge   = "ch"
+	tokenKindAPIKey      = "ak"
+	tokenKindRefresh     = "rf"
+	tokenKindFeed        = "fd"
+)
+
+// newToken returns random token of the kind, e.g. oko_live_at_<random>.
+func newToken(kind string) string {
+	return token.New(cfg.App.TokenPrefix+kind+"_", cfg.App.AuthTokenLength)
+}
+
+func hashToken(t string) string {
+	return token.Hash(t)
+}
+
+func matchToken(t, hash string) bool {
+	return token.Match(t, hash)
+}
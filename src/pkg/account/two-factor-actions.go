@@ -0,0 +1,284 @@
+package account
+
+import (
+	"net/http"
+	"oko/pkg/cfg"
+	"oko/pkg/db"
+	"oko/pkg/e"
+	"oko/pkg/ginapp/types"
+	"oko/pkg/log"
+	"oko/pkg/totp"
+
+	"github.com/gin-gonic/gin"
+)
+
+func invalidCodeResponse(c *gin.Context, field string) {
+	e.ErrorResponse(c, http.StatusBadRequest, e.CustomFieldError{
+		Name:    field,
+		Tag:     field,
+		Param:   "",
+		Value:   "",
+		Message: "Invalid code",
+	})
+}
+
+// SignInTwoFactor godoc
+// @Summary Sign in second step
+// @Description Exchange sign in challenge token and authenticator app code or recovery code for access token
+// @ID post-account-sign-in-2fa
+// @Tags Account
+// @Accept json
+// @Produce json
+// @Param object body account.SignInTwoFactorForm true "Second step fields"
+// @Success 200 {object} types.ResponseSignIn
+// @Failure 400 {object} types.ResponseErrorSwg
+// @Failure 429 {object} types.ResponseErrorSwg
+// @Router /account/sign-in/2fa [post]
+func SignInTwoFactor(c *gin.Context) {
+	var form SignInTwoFactorForm
+
+	if err := c.ShouldBind(&form); err != nil {
+		e.ErrorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	accID, err := getSignInChallenge(form.ChallengeToken)
+	if err != nil || accID == 0 {
+		e.ErrorResponse(c, http.StatusBadRequest, e.CustomFieldError{
+			Name:    "challenge_token",
+			Tag:     "challenge_token",
+			Param:   "",
+			Value:   "",
+			Message: "Invalid or expired challenge token",
+		})
+		return
+	}
+	acc, err := FindAccount(Account{ID: accID})
+	if err != nil || !acc.TotpEnabled {
+		e.ErrorResponse(c, http.StatusBadRequest, "Something went wrong")
+		return
+	}
+
+	if retryAfter := signInRetryAfter(acc.Email, c.ClientIP()); retryAfter > 0 {
+		lockedResponse(c, retryAfter)
+		return
+	}
+
+	var ok bool
+	field := "code"
+	if form.Code != "" {
+		ok, err = acc.CheckTOTP(form.Code)
+	} else {
+		field = "recovery_code"
+		ok, err = useRecoveryCode(acc.ID, form.RecoveryCode)
+	}
+	if err != nil {
+		panic(err)
+	}
+	if !ok {
+		auditSignInFailed(c, acc.ID, acc.Email, signInMethodTwoFactor)
+		if lockout := registerSignInFailure(acc.Email, c.ClientIP()); lockout > 0 {
+			if err = dropSignInChallenge(form.ChallengeToken); err != nil {
+				log.Println("Fail to drop sign in challenge", err)
+			}
+			lockedResponse(c, lockout)
+			return
+		}
+		invalidCodeResponse(c, field)
+		return
+	}
+
+	if err = dropSignInChallenge(form.ChallengeToken); err != nil {
+		panic(err)
+	}
+	resetSignInFailures(acc.Email)
+	tokens, err := IssueTokens(acc, c)
+	if err != nil {
+		panic(err)
+	}
+	auditSignIn(c, acc.ID, signInMethodTwoFactor)
+
+	c.JSON(
+		http.StatusOK,
+		types.ResponseSignIn{
+			StdResponse: types.StdResponse{
+				Status:  e.Success,
+				Message: e.GetMsg(e.Success),
+			},
+			Data: tokens,
+		},
+	)
+}
+
+// TwoFactorEnroll godoc
+// @Summary Start two-factor enrolment
+// @Description Generate authenticator app secret, two-factor authentication is enabled after confirmation
+// @ID post-account-2fa-enroll
+// @Tags Account
+// @Accept json
+// @Produce json
+// @Success 200 {object} types.ResponseTwoFactorEnroll
+// @Failure 400 {object} types.ResponseErrorSwg
+// @Failure 500 {object} types.ResponseErrorSwg
+// @Router /account/2fa/enroll [post]
+// @Security ApiKeyAuth
+func TwoFactorEnroll(c *gin.Context) {
+	acc := GetContextAcc(c)
+	if acc.TotpEnabled {
+		e.ErrorResponse(c, http.StatusBadRequest, "Two-factor authentication is already enabled")
+		return
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		panic(err)
+	}
+	if err = acc.Update(map[string]interface{}{"totp_secret": secret}); err != nil {
+		panic(err)
+	}
+
+	types.SuccessResponse(c, types.TwoFactorEnroll{
+		Secret: secret,
+		URI:    totp.ProvisioningURI(cfg.App.TOTPIssuer, acc.Email, secret),
+	})
+}
+
+// TwoFactorConfirm godoc
+// @Summary Confirm two-factor enrolment
+// @Description Enable two-factor authentication by authenticator app code, recovery codes are shown once
+// @ID post-account-2fa-confirm
+// @Tags Account
+// @Accept json
+// @Produce json
+// @Param object body account.TwoFactorCodeForm true "Authenticator app code"
+// @Success 200 {object} types.StringArray
+// @Failure 400 {object} types.ResponseErrorSwg
+// @Failure 500 {object} types.ResponseErrorSwg
+// @Router /account/2fa/confirm [post]
+// @Security ApiKeyAuth
+func TwoFactorConfirm(c *gin.Context) {
+	var form TwoFactorCodeForm
+
+	if err := c.ShouldBind(&form); err != nil {
+		e.ErrorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	acc := GetContextAcc(c)
+	if acc.TotpEnabled || acc.TotpSecret == "" {
+		e.ErrorResponse(c, http.StatusBadRequest, "Two-factor enrolment is not started")
+		return
+	}
+	step, ok := totpVerifier.Verify(acc.TotpSecret, form.Code)
+	if !ok {
+		invalidCodeResponse(c, "code")
+		return
+	}
+
+	codes, err := acc.EnableTOTP(step)
+	if err != nil {
+		panic(err)
+	}
+
+	types.SuccessResponse(c, codes)
+}
+
+// TwoFactorRecoveryCodes godoc
+// @Summary Regenerate recovery codes
+// @Description Replace recovery codes, new codes are shown once
+// @ID post-account-2fa-recovery-codes
+// @Tags Account
+// @Accept json
+// @Produce json
+// @Param object body account.TwoFactorCodeForm true "Authenticator app code"
+// @Success 200 {object} types.StringArray
+// @Failure 400 {object} types.ResponseErrorSwg
+// @Failure 500 {object} types.ResponseErrorSwg
+// @Router /account/2fa/recovery-codes [post]
+// @Security ApiKeyAuth
+func TwoFactorRecoveryCodes(c *gin.Context) {
+	var form TwoFactorCodeForm
+
+	if err := c.ShouldBind(&form); err != nil {
+		e.ErrorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	acc := GetContextAcc(c)
+	if !acc.TotpEnabled {
+		e.ErrorResponse(c, http.StatusBadRequest, "Two-factor authentication is not enabled")
+		return
+	}
+	ok, err := acc.CheckTOTP(form.Code)
+	if err != nil {
+		panic(err)
+	}
+	if !ok {
+		invalidCodeResponse(c, "code")
+		return
+	}
+
+	dbt := db.GetDB().Begin()
+	codes, err := newRecoveryCodes(dbt, acc.ID)
+	if err != nil {
+		dbt.Rollback()
+		panic(err)
+	}
+	if err = dbt.Commit().Error; err != nil {
+		panic(err)
+	}
+
+	types.SuccessResponse(c, codes)
+}
+
+// TwoFactorDisable godoc
+// @Summary Disable two-factor authentication
+// @Description Disable two-factor authentication, password and authenticator app code are required
+// @ID post-account-2fa-disable
+// @Tags Account
+// @Accept json
+// @Produce json
+// @Param object body account.TwoFactorDisableForm true "Disable fields"
+// @Success 200 {object} types.StdResponse
+// @Failure 400 {object} types.ResponseErrorSwg
+// @Failure 500 {object} types.ResponseErrorSwg
+// @Router /account/2fa/disable [post]
+// @Security ApiKeyAuth
+func TwoFactorDisable(c *gin.Context) {
+	var form TwoFactorDisableForm
+
+	if err := c.ShouldBind(&form); err != nil {
+		e.ErrorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	acc := GetContextAcc(c)
+	if !acc.TotpEnabled {
+		e.ErrorResponse(c, http.StatusBadRequest, "Two-factor authentication is not enabled")
+		return
+	}
+	if err := acc.CheckPassword(form.Password); err != nil {
+		e.ErrorResponse(c, http.StatusBadRequest, e.CustomFieldError{
+			Name:    "password",
+			Tag:     "password",
+			Param:   "",
+			Value:   "",
+			Message: "Invalid password",
+		})
+		return
+	}
+	ok, err := acc.CheckTOTP(form.Code)
+	if err != nil {
+		panic(err)
+	}
+	if !ok {
+		invalidCodeResponse(c, "code")
+		return
+	}
+
+	if err = acc.DisableTOTP(); err != nil {
+		panic(err)
+	}
+
+	types.SuccessEmptyResponse(c)
+}
@@ -0,0 +1,142 @@
+package account
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"oko/pkg/cfg"
+	"oko/pkg/db"
+	"oko/pkg/redis"
+	"oko/pkg/rndstr"
+	"oko/pkg/totp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jinzhu/gorm"
+)
+
+const (
+	recoveryCodesCount = 10
+	recoveryCodeLength = 10
+
+	signInChallengeKey = "sign-in-2fa:"
+)
+
+// totpVerifier checks codes of authenticator apps, tests replace its clock.
+var totpVerifier = totp.NewVerifier(totp.SystemClock{}) //nolint
+
+// RecoveryCode is a single-use code to pass two-factor sign in without authenticator app,
+// only hash of the code is stored.
+type RecoveryCode struct {
+	ID        int        `json:"id"`
+	AccountID int        `json:"account_id"`
+	CodeHash  string     `json:"-"`
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+func (RecoveryCode) TableName() string {
+	return "recovery_code"
+}
+
+func hashRecoveryCode(code string) string {
+	code = strings.Replace(strings.TrimSpace(code), "-", "", -1)
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])
+}
+
+// newRecoveryCodes replaces recovery codes of the account, returns new codes to be shown once.
+func newRecoveryCodes(dbt *gorm.DB, accID int) ([]string, error) {
+	if err := dbt.Where("account_id = ?", accID).Delete(&RecoveryCode{}).Error; err != nil {
+		return nil, err
+	}
+	codes := make([]string, 0, recoveryCodesCount)
+	for i := 0; i < recoveryCodesCount; i++ {
+		code := rndstr.RandString(recoveryCodeLength)
+		rc := RecoveryCode{AccountID: accID, CodeHash: hashRecoveryCode(code)}
+		if err := dbt.Create(&rc).Error; err != nil {
+			return nil, err
+		}
+		codes = append(codes, code[:recoveryCodeLength/2]+"-"+code[recoveryCodeLength/2:])
+	}
+	return codes, nil
+}
+
+// useRecoveryCode marks the code as used, returns false for unknown or used code.
+func useRecoveryCode(accID int, code string) (bool, error) {
+	res := db.GetDB().Model(&RecoveryCode{}).
+		Where("account_id = ? and code_hash = ? and used_at is null", accID, hashRecoveryCode(code)).
+		UpdateColumn("used_at", time.Now())
+	return res.RowsAffected == 1, res.Error
+}
+
+// CheckTOTP verifies code of authenticator app, each code is accepted only once.
+func (a *Account) CheckTOTP(code string) (bool, error) {
+	step, ok := totpVerifier.Verify(a.TotpSecret, code)
+	if !ok || step <= a.TotpLastStep {
+		return false, nil
+	}
+	res := db.GetDB().Model(&Account{}).
+		Where("id = ? and totp_last_step < ?", a.ID, step).
+		UpdateColumn("totp_last_step", step)
+	if res.Error != nil {
+		return false, res.Error
+	}
+	a.TotpLastStep = step
+	return res.RowsAffected == 1, nil
+}
+
+// EnableTOTP turns two-factor authentication on and returns new recovery codes.
+func (a *Account) EnableTOTP(step int64) ([]string, error) {
+	dbt := db.GetDB().Begin()
+	err := dbt.Model(a).Updates(map[string]interface{}{"totp_enabled": true, "totp_last_step": step}).Error
+	if err != nil {
+		dbt.Rollback()
+		return nil, err
+	}
+	codes, err := newRecoveryCodes(dbt, a.ID)
+	if err != nil {
+		dbt.Rollback()
+		return nil, err
+	}
+	return codes, dbt.Commit().Error
+}
+
+// DisableTOTP turns two-factor authentication off and drops the secret and recovery codes.
+func (a *Account) DisableTOTP() error {
+	dbt := db.GetDB().Begin()
+	err := dbt.Model(a).Updates(map[string]interface{}{
+		"totp_enabled":   false,
+		"totp_secret":    "",
+		"totp_last_step": 0,
+	}).Error
+	if err != nil {
+		dbt.Rollback()
+		return err
+	}
+	if err = dbt.Where("account_id = ?", a.ID).Delete(&RecoveryCode{}).Error; err != nil {
+		dbt.Rollback()
+		return err
+	}
+	return dbt.Commit().Error
+}
+
+// newSignInChallenge issues short-lived token which is exchanged for access token
+// on the second step of sign in.
+func newSignInChallenge(accID int) (string, error) {
+	token := newToken(tokenKindChallenge)
+	err := redis.SetEx(signInChallengeKey+hashToken(token), []byte(strconv.Itoa(accID)), int32(cfg.App.ChallengeLifetime))
+	return token, err
+}
+
+func getSignInChallenge(token string) (int, error) {
+	data, err := redis.Get(signInChallengeKey + hashToken(token))
+	if err != nil || len(data) == 0 {
+		return 0, err
+	}
+	return strconv.Atoi(string(data))
+}
+
+func dropSignInChallenge(token string) error {
+	return redis.Delete(signInChallengeKey + hashToken(token))
+}
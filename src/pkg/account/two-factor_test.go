@@ -0,0 +1,285 @@
+package account
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"oko/pkg/cfg"
+	"oko/pkg/db"
+	"oko/pkg/redis"
+	"oko/pkg/totp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	redigo "github.com/gomodule/redigo/redis"
+	"github.com/jinzhu/gorm"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// fakeRedis keeps values in memory, it implements commands used by sign in.
+type fakeRedis struct {
+	mu     sync.Mutex
+	values map[string][]byte
+	hashes map[string]map[string][]byte
+}
+
+func newFakeRedis() *fakeRedis {
+	return &fakeRedis{values: map[string][]byte{}, hashes: map[string]map[string][]byte{}}
+}
+
+func (r *fakeRedis) Close() error { return nil }
+
+func (r *fakeRedis) Err() error { return nil }
+
+func (r *fakeRedis) Send(string, ...interface{}) error { return nil }
+
+func (r *fakeRedis) Flush() error { return nil }
+
+func (r *fakeRedis) Receive() (interface{}, error) { return nil, nil }
+
+func bytesArg(v interface{}) []byte {
+	if b, ok := v.([]byte); ok {
+		return b
+	}
+	return []byte(fmt.Sprint(v))
+}
+
+func (r *fakeRedis) Do(cmd string, args ...interface{}) (interface{}, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := ""
+	if len(args) > 0 {
+		key = fmt.Sprint(args[0])
+	}
+	switch strings.ToUpper(cmd) {
+	case "":
+		return nil, nil
+	case "GET":
+		if v, ok := r.values[key]; ok {
+			return v, nil
+		}
+		return nil, nil
+	case "SET":
+		if _, ok := r.values[key]; ok && fmt.Sprint(args[len(args)-1]) == "NX" {
+			return nil, nil
+		}
+		r.values[key] = bytesArg(args[1])
+		return "OK", nil
+	case "EXISTS":
+		if _, ok := r.values[key]; ok {
+			return int64(1), nil
+		}
+		return int64(0), nil
+	case "TTL":
+		if _, ok := r.values[key]; ok {
+			return int64(60), nil
+		}
+		return int64(-2), nil
+	case "EXPIRE":
+		return int64(1), nil
+	case "DEL":
+		delete(r.values, key)
+		delete(r.hashes, key)
+		return int64(1), nil
+	case "INCR":
+		var n int64
+		fmt.Sscan(string(r.values[key]), &n) //nolint
+		n++
+		r.values[key] = []byte(fmt.Sprint(n))
+		return n, nil
+	case "HSET":
+		if r.hashes[key] == nil {
+			r.hashes[key] = map[string][]byte{}
+		}
+		r.hashes[key][fmt.Sprint(args[1])] = bytesArg(args[2])
+		return int64(1), nil
+	case "HGET":
+		if v, ok := r.hashes[key][fmt.Sprint(args[1])]; ok {
+			return v, nil
+		}
+		return nil, nil
+	}
+	return nil, fmt.Errorf("fake redis: unsupported command %s", cmd)
+}
+
+type fixedClock time.Time
+
+func (c fixedClock) Now() time.Time {
+	return time.Time(c)
+}
+
+const (
+	testSecret   = "JBSWY3DPEHPK3PXP"
+	testPassword = "correct horse battery staple"
+)
+
+var accountColumns = []string{"id", "email", "password_hash", "status", "totp_secret", "totp_enabled", "totp_last_step"}
+
+type TwoFactorSuite struct {
+	suite.Suite
+	mock     sqlmock.Sqlmock
+	now      time.Time
+	passHash string
+}
+
+func TestTwoFactorFlow(t *testing.T) {
+	suite.Run(t, new(TwoFactorSuite))
+}
+
+func (s *TwoFactorSuite) SetupTest() {
+	sqlDB, mock, err := sqlmock.New()
+	s.Require().NoError(err)
+	gdb, err := gorm.Open("postgres", sqlDB)
+	s.Require().NoError(err)
+	db.SetDB(gdb)
+	s.mock = mock
+
+	fake := newFakeRedis()
+	redis.Pool = &redigo.Pool{Dial: func() (redigo.Conn, error) { return fake, nil }}
+
+	s.now = time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	totpVerifier = totp.NewVerifier(fixedClock(s.now))
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(testPassword), bcrypt.MinCost)
+	s.Require().NoError(err)
+	s.passHash = string(hash)
+
+	cfg.App.AuthMode = cfg.AuthModeSession
+	cfg.App.ChallengeLifetime = 300
+	cfg.App.AuthTokenLifetime = 3600
+	cfg.App.AuthTokenLength = 32
+	cfg.App.SignInMaxFailures = 5
+	cfg.App.SignInMaxIPFailures = 50
+	cfg.App.SignInFailureWindow = 900
+	gin.SetMode(gin.TestMode)
+}
+
+func (s *TwoFactorSuite) TearDownTest() {
+	s.Require().NoError(s.mock.ExpectationsWereMet())
+}
+
+func (s *TwoFactorSuite) expectAccount(lastStep int64) {
+	s.mock.ExpectQuery(`SELECT \* FROM "account"`).WillReturnRows(sqlmock.NewRows(accountColumns).
+		AddRow(7, "user@example.com", s.passHash, AccStatusActive, testSecret, true, lastStep))
+}
+
+func (s *TwoFactorSuite) expectAudit() {
+	s.mock.ExpectBegin()
+	s.mock.ExpectQuery(`INSERT INTO "audit_log"`).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	s.mock.ExpectCommit()
+}
+
+func (s *TwoFactorSuite) post(handler gin.HandlerFunc, body interface{}) (int, map[string]interface{}) {
+	data, err := json.Marshal(body)
+	s.Require().NoError(err)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(data))
+	c.Request.Header.Set("Content-Type", "application/json")
+	handler(c)
+
+	var res map[string]interface{}
+	s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &res))
+	return w.Code, res
+}
+
+func (s *TwoFactorSuite) challenge() string {
+	s.expectAccount(0)
+	code, res := s.post(SignIn, map[string]string{"email": "user@example.com", "password": testPassword})
+	s.Require().Equal(http.StatusOK, code)
+	token := res["data"].(map[string]interface{})["challenge_token"].(string)
+	s.Require().NotEmpty(token)
+	return token
+}
+
+func (s *TwoFactorSuite) code() string {
+	code, err := totp.Code(testSecret, s.now)
+	s.Require().NoError(err)
+	return code
+}
+
+func (s *TwoFactorSuite) TestCode() {
+	challenge := s.challenge()
+	step := totp.Step(s.now)
+
+	// wrong code keeps the challenge
+	s.expectAccount(0)
+	s.expectAudit()
+	code, _ := s.post(SignInTwoFactor, map[string]string{"challenge_token": challenge, "code": "000000"})
+	s.Equal(http.StatusBadRequest, code)
+
+	s.expectAccount(0)
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec(`UPDATE "account" SET "totp_last_step"`).
+		WithArgs(step, 7, step).WillReturnResult(sqlmock.NewResult(0, 1))
+	s.mock.ExpectCommit()
+	s.expectAudit()
+	code, res := s.post(SignInTwoFactor, map[string]string{"challenge_token": challenge, "code": s.code()})
+	s.Require().Equal(http.StatusOK, code)
+	s.NotEmpty(res["data"].(map[string]interface{})["access_token"])
+
+	// the challenge is single use
+	code, _ = s.post(SignInTwoFactor, map[string]string{"challenge_token": challenge, "code": s.code()})
+	s.Equal(http.StatusBadRequest, code)
+
+	// the code is rejected on replay with new challenge
+	challenge = s.challenge()
+	s.expectAccount(step)
+	s.expectAudit()
+	code, res = s.post(SignInTwoFactor, map[string]string{"challenge_token": challenge, "code": s.code()})
+	s.Equal(http.StatusBadRequest, code)
+	s.Equal("code", res["err_struct"].([]interface{})[0].(map[string]interface{})["name"])
+}
+
+func (s *TwoFactorSuite) TestConcurrentCodeUse() {
+	challenge := s.challenge()
+
+	// other request has stored the step between loading the account and the update
+	s.expectAccount(0)
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec(`UPDATE "account" SET "totp_last_step"`).WillReturnResult(sqlmock.NewResult(0, 0))
+	s.mock.ExpectCommit()
+	s.expectAudit()
+	code, _ := s.post(SignInTwoFactor, map[string]string{"challenge_token": challenge, "code": s.code()})
+	s.Equal(http.StatusBadRequest, code)
+}
+
+func (s *TwoFactorSuite) TestRecoveryCode() {
+	challenge := s.challenge()
+	recovery := "abcde-fghij"
+
+	s.expectAccount(0)
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec(`UPDATE "recovery_code" SET "used_at" = \$1 .*used_at is null`).
+		WithArgs(sqlmock.AnyArg(), 7, hashRecoveryCode(recovery)).WillReturnResult(sqlmock.NewResult(0, 1))
+	s.mock.ExpectCommit()
+	s.expectAudit()
+	code, _ := s.post(SignInTwoFactor, map[string]string{"challenge_token": challenge, "recovery_code": recovery})
+	s.Require().Equal(http.StatusOK, code)
+
+	// used code doesn't match the update any more
+	challenge = s.challenge()
+	s.expectAccount(0)
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec(`UPDATE "recovery_code" SET "used_at" = \$1 .*used_at is null`).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	s.mock.ExpectCommit()
+	s.expectAudit()
+	code, res := s.post(SignInTwoFactor, map[string]string{"challenge_token": challenge, "recovery_code": recovery})
+	s.Equal(http.StatusBadRequest, code)
+	s.Equal("recovery_code", res["err_struct"].([]interface{})[0].(map[string]interface{})["name"])
+}
+
+func TestHashRecoveryCode(t *testing.T) {
+	require.Equal(t, hashRecoveryCode("abcdefghij"), hashRecoveryCode(" abcde-fghij "))
+	require.NotEqual(t, hashRecoveryCode("abcdefghij"), hashRecoveryCode("abcdefghik"))
+}
@@ -2,7 +2,10 @@ package account
 
 import (
 	"net/http"
+	"oko/pkg/cfg"
 	"oko/pkg/e"
+	"oko/pkg/redis"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 )
@@ -16,3 +19,55 @@ func loginErrorResponse(c *gin.Context, email string) {
 		Message: "Invalid username or password",
 	})
 }
+
+// throttleEmail allows mail to the e-mail once per SIGN_UP_RESEND_INTERVAL, keyPrefix separates
+// kinds of mail. Error response with the message is written if the mail was sent recently.
+func throttleEmail(c *gin.Context, keyPrefix, email, message string) bool {
+	key := keyPrefix + email
+	if exists, err := redis.Exists(key); err != nil {
+		panic(err)
+	} else if exists {
+		c.Header("Retry-After", strconv.Itoa(cfg.App.SignUpResendInterval))
+		e.ErrorResponse(c, e.TooManyReqs, message)
+		return false
+	}
+	if err := redis.SetEx(key, []byte{1}, int32(cfg.App.SignUpResendInterval)); err != nil {
+		panic(err)
+	}
+	return true
+}
+
+func lockedResponse(c *gin.Context, retryAfter int) {
+	c.Header("Retry-After", strconv.Itoa(retryAfter))
+	e.ErrorResponse(c, e.ErrorAuthLocked, "Too many failed sign in attempts, try again later")
+}
+
+// confirmAccount checks password of the account before sensitive action. Account provisioned by
+// identity provider has no password, it confirms the action by signing in again, so the session
+// must be fresh. Error response is written if the check fails.
+func confirmAccount(c *gin.Context, acc Account, password string) bool {
+	if acc.PasswordHash == "" {
+		if isFreshSession(c) {
+			return true
+		}
+		e.ErrorResponse(c, http.StatusBadRequest, e.CustomFieldError{
+			Name:    "password",
+			Tag:     "password",
+			Param:   "",
+			Value:   "",
+			Message: "Sign in again to confirm the action",
+		})
+		return false
+	}
+	if err := acc.CheckPassword(password); err != nil {
+		e.ErrorResponse(c, http.StatusBadRequest, e.CustomFieldError{
+			Name:    "password",
+			Tag:     "password",
+			Param:   "",
+			Value:   "",
+			Message: "Invalid password",
+		})
+		return false
+	}
+	return true
+}
@@ -0,0 +1,33 @@
+package account
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfirmAccount(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	acc := Account{ID: 7}
+	require.NoError(t, acc.SetPassword("secret-password"))
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	require.True(t, confirmAccount(c, acc, "secret-password"))
+
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	require.False(t, confirmAccount(c, acc, ""))
+	require.Equal(t, http.StatusBadRequest, w.Code)
+
+	// account without password has no session when api key is used
+	acc.PasswordHash = ""
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Set("api_key_id", 1)
+	require.False(t, confirmAccount(c, acc, ""))
+	require.Equal(t, http.StatusBadRequest, w.Code)
+}
@@ -2,7 +2,9 @@ package action
 
 import (
 	"oko/pkg/account"
+	"oko/pkg/db"
 	"oko/pkg/ginapp/controller"
+	"oko/pkg/org"
 
 	"github.com/gin-gonic/gin"
 )
@@ -17,19 +19,24 @@ type iHandler interface { //nolint
 }
 
 func NewController() controller.Ctrl {
-	h := NewHandler()
+	h := NewHandler(db.GetDB())
 	return controller.Ctrl{
 		Name:     "action",
-		Handlers: controller.HandlerList{account.Auth(true, []int{})},
+		Handlers: controller.HandlerList{account.Auth(true, []int{}), org.Active()},
 		Acts: []controller.Act{
-			{Method: "GET", Route: "/:id", Handlers: controller.HandlerList{h.Get}},
-			{Method: "GET", Route: "/", Handlers: controller.HandlerList{h.List}},
-			{Method: "DELETE", Route: "/:id", Handlers: controller.HandlerList{h.Delete}},
-			{Method: "POST", Route: "/", Handlers: controller.HandlerList{h.Create}},
-			{Method: "PUT", Route: "/:id", Handlers: controller.HandlerList{h.Update}},
-			{Method: "HEAD", Route: "/:id", Handlers: controller.HandlerList{h.Exist}},
-			{Method: "POST", Route: "/:id/rule", Handlers: controller.HandlerList{h.AddRuleToAction}},
-			{Method: "DELETE", Route: "/:id/rule", Handlers: controller.HandlerList{h.DelRuleFormAction}},
+			{Method: "GET", Route: "/:id", Handlers: controller.HandlerList{h.Get}, Scope: account.ScopeRuleRead},
+			{Method: "GET", Route: "/", Handlers: controller.HandlerList{h.List}, Scope: account.ScopeRuleRead},
+			{Method: "DELETE", Route: "/:id", Handlers: controller.HandlerList{h.Delete},
+				Scope: account.ScopeRuleWrite, Permission: account.PermRuleWrite},
+			{Method: "POST", Route: "/", Handlers: controller.HandlerList{h.Create},
+				Scope: account.ScopeRuleWrite, Permission: account.PermRuleWrite},
+			{Method: "PUT", Route: "/:id", Handlers: controller.HandlerList{h.Update},
+				Scope: account.ScopeRuleWrite, Permission: account.PermRuleWrite},
+			{Method: "HEAD", Route: "/:id", Handlers: controller.HandlerList{h.Exist}, Scope: account.ScopeRuleRead},
+			{Method: "POST", Route: "/:id/rule", Handlers: controller.HandlerList{h.AddRuleToAction},
+				Scope: account.ScopeRuleWrite, Permission: account.PermRuleWrite},
+			{Method: "DELETE", Route: "/:id/rule", Handlers: controller.HandlerList{h.DelRuleFormAction},
+				Scope: account.ScopeRuleWrite, Permission: account.PermRuleWrite},
 		},
 	}
 }
@@ -2,17 +2,23 @@ package action
 
 import (
 	"context"
+	"math"
 	"net/http"
+	"oko/pkg/audit"
 	"oko/pkg/e"
 	"oko/pkg/env"
 	"oko/pkg/ginapp"
 	"oko/pkg/ginapp/types"
+	"oko/pkg/log"
+	"oko/pkg/org"
+	"oko/pkg/rule"
 	pb "oko/srv/proxy/proto"
 	"time"
 
 	"github.com/golang/protobuf/ptypes/wrappers"
 
 	"github.com/gin-gonic/gin"
+	"github.com/jinzhu/gorm"
 	"github.com/micro/go-micro"
 	"github.com/micro/go-micro/client"
 	"github.com/micro/go-micro/registry"
@@ -20,10 +26,11 @@ import (
 )
 
 type handler struct {
-	srv pb.ActionService
+	srv  pb.ActionService
+	repo *repository
 }
 
-func NewHandler() *handler { //nolint
+func NewHandler(db *gorm.DB) *handler { //nolint
 	reg := etcd.NewRegistry(
 		registry.Addrs(env.GetEnvOrPanic("ETCD_ADDRESS")),
 	)
@@ -38,10 +45,25 @@ func NewHandler() *handler { //nolint
 	actionSrv := pb.NewActionService("go.micro.srv.proxy", cl)
 
 	return &handler{
-		srv: actionSrv,
+		srv:  actionSrv,
+		repo: newRepository(db),
 	}
 }
 
+// checkOwner responds with not found if the action doesn't belong to active organization.
+func (h handler) checkOwner(c *gin.Context, id uint32) bool {
+	owns, err := h.repo.owns(org.GetID(c), id)
+	if err != nil {
+		e.ErrorResponse(c, http.StatusInternalServerError, "Something went wrong")
+		return false
+	}
+	if !owns {
+		e.ErrorResponse(c, http.StatusNotFound, "Action not found")
+		return false
+	}
+	return true
+}
+
 // Exist godoc
 // @Summary Exist action item
 // @Description Exist action item
@@ -61,6 +83,15 @@ func (h handler) Exist(c *gin.Context) {
 		e.ErrorResponse(c, http.StatusBadRequest, err)
 		return
 	}
+	owns, err := h.repo.owns(org.GetID(c), actionID)
+	if err != nil {
+		e.ErrorResponse(c, http.StatusInternalServerError, "Something went wrong")
+		return
+	}
+	if !owns {
+		c.Status(http.StatusNotFound)
+		return
+	}
 	exist, err := h.srv.Exist(context.Background(), &pb.ActionRequest{
 		Id: actionID,
 	})
@@ -78,7 +109,7 @@ func (h handler) Exist(c *gin.Context) {
 
 // List godoc
 // @Summary List action item
-// @Description List action item
+// @Description List actions of active organization, page size is limited to 25
 // @ID list-action
 // @Tags Action
 // @Accept json
@@ -105,44 +136,88 @@ func (h handler) List(c *gin.Context) {
 		return
 	}
 
-	req := &pb.ActionListRequest{
-		CurrentPage: form.CurrentPage,
-		PerPage:     form.PerPage,
-	}
+	form.BoundTo(types.MaxServicePerPage)
 
+	var (
+		views []View
+		meta  *types.PaginationResponse
+		ok    bool
+	)
 	if filter.RuleID != nil {
-		req.RuleId = &wrappers.UInt32Value{Value: *filter.RuleID}
+		views, meta, ok = h.listByRule(c, *filter.RuleID, form)
+	} else {
+		views, meta, ok = h.listByOrganization(c, form)
 	}
-	list, err := h.srv.List(context.Background(), req)
-	if err != nil || list == nil {
-		e.ErrorResponse(c, http.StatusInternalServerError, "Something went wrong")
+	if !ok {
 		return
 	}
-	views := []View{}
-	if list.Data != nil && len(list.Data) > 0 {
-		views = make([]View, 0, len(list.Data))
-		for _, item := range list.Data {
-			if item != nil {
-				views = append(views, toView(*item))
-			}
-		}
+
+	result := types.Response{
+		Data: views,
+		Meta: meta,
+	}
+
+	result.Success(c)
+}
+
+// listByRule returns page of actions of the rule, rules get actions of their organization only.
+func (h handler) listByRule(c *gin.Context, ruleID uint32, form types.PaginationRequest) (
+	[]View, *types.PaginationResponse, bool) {
+	if !rule.CheckOwner(c, ruleID) {
+		return nil, nil, false
+	}
+	list, err := h.srv.List(context.Background(), &pb.ActionListRequest{
+		CurrentPage: form.CurrentPage,
+		PerPage:     form.PerPage,
+		RuleId:      &wrappers.UInt32Value{Value: ruleID},
+	})
+	if err != nil || list == nil || list.Meta == nil {
+		e.ErrorResponse(c, http.StatusInternalServerError, "Something went wrong")
+		return nil, nil, false
 	}
 
-	meta := &types.PaginationResponse{
+	views := make([]View, 0, len(list.Data))
+	for _, item := range list.Data {
+		if item != nil {
+			views = append(views, toView(*item))
+		}
+	}
+	return views, &types.PaginationResponse{
 		PaginationRequest: types.PaginationRequest{
 			CurrentPage: list.Meta.CurrentPage,
 			PerPage:     list.Meta.PerPage,
 		},
 		TotalPages:   list.Meta.TotalPage,
 		TotalRecords: list.Meta.TotalRecord,
-	}
+	}, true
+}
 
-	result := types.Response{
-		Data: views,
-		Meta: meta,
+// listByOrganization returns page of actions of active organization, the service is asked
+// for the page items only.
+func (h handler) listByOrganization(c *gin.Context, form types.PaginationRequest) (
+	[]View, *types.PaginationResponse, bool) {
+	ids, count, err := h.repo.list(org.GetID(c), form.CurrentPage, form.PerPage)
+	if err != nil {
+		e.ErrorResponse(c, http.StatusInternalServerError, "Something went wrong")
+		return nil, nil, false
 	}
 
-	result.Success(c)
+	views := make([]View, 0, len(ids))
+	for _, id := range ids {
+		res, err := h.srv.Get(context.Background(), &pb.ActionRequest{Id: id})
+		if err != nil {
+			e.ErrorResponse(c, http.StatusInternalServerError, "Something went wrong")
+			return nil, nil, false
+		}
+		if res != nil && res.Data != nil {
+			views = append(views, toView(*res.Data))
+		}
+	}
+	return views, &types.PaginationResponse{
+		PaginationRequest: form,
+		TotalPages:        uint32(math.Ceil(float64(count) / float64(form.PerPage))),
+		TotalRecords:      count,
+	}, true
 }
 
 // Get godoc
@@ -164,6 +239,9 @@ func (h handler) Get(c *gin.Context) {
 		e.ErrorResponse(c, http.StatusBadRequest, err)
 		return
 	}
+	if !h.checkOwner(c, actID) {
+		return
+	}
 	act, err := h.srv.Get(context.Background(), &pb.ActionRequest{Id: actID})
 	if err != nil {
 		e.ErrorResponse(c, http.StatusInternalServerError, "Something went wrong")
@@ -171,6 +249,7 @@ func (h handler) Get(c *gin.Context) {
 	}
 	if act == nil || act.Data == nil {
 		e.ErrorResponse(c, http.StatusNotFound, "Something went wrong")
+		return
 	}
 
 	view := toView(*act.Data)
@@ -197,6 +276,9 @@ func (h handler) Create(c *gin.Context) {
 		e.ErrorResponse(c, http.StatusBadRequest, err)
 		return
 	}
+	if act.RuleID != 0 && !rule.CheckOwner(c, act.RuleID) {
+		return
+	}
 
 	res, err := h.srv.Create(context.Background(), &pb.ActionCreateRequest{
 		RuleId: act.RuleID,
@@ -207,6 +289,15 @@ func (h handler) Create(c *gin.Context) {
 		e.ErrorResponse(c, http.StatusInternalServerError, "Something went wrong")
 		return
 	}
+	if err = h.repo.assign(org.GetID(c), res.Data.Id); err != nil {
+		if _, delErr := h.srv.Delete(context.Background(), &pb.ActionRequest{Id: res.Data.Id}); delErr != nil {
+			log.Println("Fail to delete unassigned action", delErr)
+		}
+		e.ErrorResponse(c, http.StatusInternalServerError, "Something went wrong")
+		return
+	}
+	audit.Record(c, audit.Event{Action: audit.ActionCreate, TargetType: audit.TargetAction, TargetID: res.Data.Id,
+		Details: act})
 	types.SuccessResponse(c, toView(*res.Data))
 }
 
@@ -242,6 +333,9 @@ func (h handler) Update(c *gin.Context) {
 		})
 		return
 	}
+	if !h.checkOwner(c, act.ID) {
+		return
+	}
 
 	request := pb.ActionUpdateRequest{
 		Id: &wrappers.UInt32Value{Value: act.ID},
@@ -260,6 +354,8 @@ func (h handler) Update(c *gin.Context) {
 		e.ErrorResponse(c, http.StatusInternalServerError, "Something went wrong")
 		return
 	}
+	audit.Record(c, audit.Event{Action: audit.ActionUpdate, TargetType: audit.TargetAction, TargetID: act.ID,
+		Details: act})
 	types.SuccessResponse(c, res)
 }
 
@@ -283,6 +379,10 @@ func (h handler) Delete(c *gin.Context) {
 		return
 	}
 
+	if !h.checkOwner(c, actID) {
+		return
+	}
+
 	_, err = h.srv.Delete(context.Background(), &pb.ActionRequest{
 		Id: actID,
 	})
@@ -291,6 +391,11 @@ func (h handler) Delete(c *gin.Context) {
 		e.ErrorResponse(c, http.StatusInternalServerError, "Something went wrong")
 		return
 	}
+	if err = h.repo.unassign(actID); err != nil {
+		e.ErrorResponse(c, http.StatusInternalServerError, "Something went wrong")
+		return
+	}
+	audit.Record(c, audit.Event{Action: audit.ActionDelete, TargetType: audit.TargetAction, TargetID: actID})
 	types.SuccessEmptyResponse(c)
 }
 
@@ -326,6 +431,9 @@ func (h handler) AddRuleToAction(c *gin.Context) {
 		})
 		return
 	}
+	if !h.checkOwner(c, req.ID) || !rule.CheckOwner(c, req.RuleID) {
+		return
+	}
 
 	resp, err := h.srv.AddRule(context.Background(), &pb.AddRuleRequest{
 		ActionId: req.ID,
@@ -335,6 +443,8 @@ func (h handler) AddRuleToAction(c *gin.Context) {
 		e.ErrorResponse(c, http.StatusInternalServerError, "Something went wrong")
 		return
 	}
+	audit.Record(c, audit.Event{Action: audit.ActionRuleAdd, TargetType: audit.TargetAction, TargetID: req.ID,
+		Details: req})
 
 	types.SuccessResponse(c, toView(*resp.Data))
 }
@@ -371,6 +481,9 @@ func (h handler) DelRuleFormAction(c *gin.Context) {
 		})
 		return
 	}
+	if !h.checkOwner(c, req.ID) || !rule.CheckOwner(c, req.RuleID) {
+		return
+	}
 
 	resp, err := h.srv.DelRule(context.Background(), &pb.AddRuleRequest{
 		ActionId: req.ID,
@@ -380,6 +493,8 @@ func (h handler) DelRuleFormAction(c *gin.Context) {
 		e.ErrorResponse(c, http.StatusInternalServerError, "Something went wrong")
 		return
 	}
+	audit.Record(c, audit.Event{Action: audit.ActionRuleRemove, TargetType: audit.TargetAction, TargetID: req.ID,
+		Details: req})
 
 	types.SuccessResponse(c, toView(*resp.Data))
 }
@@ -0,0 +1,80 @@
+package action
+
+import (
+	"oko/pkg/log"
+
+	"github.com/jinzhu/gorm"
+)
+
+// organizationAction assigns action of proxy service to the organization owning it.
+type organizationAction struct {
+	OrganizationID uint   `gorm:"primary_key"`
+	ActionID       uint32 `gorm:"primary_key"`
+}
+
+func (organizationAction) TableName() string {
+	return "organization_action"
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+func newRepository(db *gorm.DB) *repository {
+	return &repository{db: db}
+}
+
+func (r repository) owns(orgID uint, actionID uint32) (bool, error) {
+	var count int
+	err := r.db.Model(&organizationAction{}).
+		Where("organization_id = ? and action_id = ?", orgID, actionID).
+		Count(&count).Error
+	if err != nil {
+		log.Println("Error in ActionRepository.owns", err)
+	}
+	return count > 0, err
+}
+
+func (r repository) assign(orgID uint, actionID uint32) error {
+	err := r.db.Create(&organizationAction{OrganizationID: orgID, ActionID: actionID}).Error
+	if err != nil {
+		log.Println("Error in ActionRepository.assign", err)
+	}
+	return err
+}
+
+// adopt assigns the action to the organization unless any organization owns it already.
+func (r repository) adopt(orgID uint, actionID uint32) (bool, error) {
+	res := r.db.Exec("INSERT INTO organization_action (organization_id, action_id) VALUES (?, ?) "+
+		"ON CONFLICT (action_id) DO NOTHING", orgID, actionID)
+	if res.Error != nil {
+		log.Println("Error in ActionRepository.adopt", res.Error)
+	}
+	return res.RowsAffected > 0, res.Error
+}
+
+func (r repository) unassign(actionID uint32) error {
+	err := r.db.Where("action_id = ?", actionID).Delete(&organizationAction{}).Error
+	if err != nil {
+		log.Println("Error in ActionRepository.unassign", err)
+	}
+	return err
+}
+
+// list returns page of action ids of the organization.
+func (r repository) list(orgID uint, page, perPage uint32) (ids []uint32, count uint32, err error) {
+	var offset uint32
+	if page > 1 {
+		offset = (page - 1) * perPage
+	}
+
+	q := r.db.Model(&organizationAction{}).Where("organization_id = ?", orgID)
+	if err = q.Count(&count).Error; err != nil {
+		log.Println("Error in ActionRepository.list", err)
+		return
+	}
+	if err = q.Order("action_id").Offset(offset).Limit(perPage).Pluck("action_id", &ids).Error; err != nil {
+		log.Println("Error in ActionRepository.list", err)
+	}
+	return
+}
@@ -0,0 +1,67 @@
+package action
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jinzhu/gorm"
+	"github.com/stretchr/testify/suite"
+)
+
+type RepositorySuite struct {
+	suite.Suite
+	mock sqlmock.Sqlmock
+	repo *repository
+}
+
+func TestActionRepository(t *testing.T) {
+	suite.Run(t, new(RepositorySuite))
+}
+
+func (s *RepositorySuite) SetupTest() {
+	sqlDB, mock, err := sqlmock.New()
+	s.Require().NoError(err)
+	gdb, err := gorm.Open("postgres", sqlDB)
+	s.Require().NoError(err)
+	s.mock = mock
+	s.repo = newRepository(gdb)
+}
+
+func (s *RepositorySuite) TearDownTest() {
+	s.Require().NoError(s.mock.ExpectationsWereMet())
+}
+
+func (s *RepositorySuite) TestOwns() {
+	s.mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "organization_action"  WHERE (organization_id = $1 and action_id = $2)`)).
+		WithArgs(1, 10).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	owns, err := s.repo.owns(1, 10)
+	s.NoError(err)
+	s.True(owns)
+
+	// action 10 belongs to organization 1 only
+	s.mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "organization_action"  WHERE (organization_id = $1 and action_id = $2)`)).
+		WithArgs(2, 10).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	owns, err = s.repo.owns(2, 10)
+	s.NoError(err)
+	s.False(owns)
+}
+
+func (s *RepositorySuite) TestListOtherOrganization() {
+	s.mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "organization_action"  WHERE (organization_id = $1)`)).
+		WithArgs(2).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	s.mock.ExpectQuery(regexp.QuoteMeta(`SELECT action_id FROM "organization_action"  WHERE (organization_id = $1) ORDER BY action_id LIMIT 25 OFFSET 0`)).
+		WithArgs(2).WillReturnRows(sqlmock.NewRows([]string{"action_id"}))
+	ids, count, err := s.repo.list(2, 1, 25)
+	s.NoError(err)
+	s.Empty(ids)
+	s.Zero(count)
+}
+
+func (s *RepositorySuite) TestAdoptOwned() {
+	s.mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO organization_action (organization_id, action_id) VALUES ($1, $2) ON CONFLICT (action_id) DO NOTHING`)).
+		WithArgs(2, 10).WillReturnResult(sqlmock.NewResult(0, 0))
+	adopted, err := s.repo.adopt(2, 10)
+	s.NoError(err)
+	s.False(adopted)
+}
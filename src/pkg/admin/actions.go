@@ -0,0 +1,421 @@
+package admin
+
+import (
+	"math"
+	"net/http"
+	"oko/pkg/account"
+	"oko/pkg/audit"
+	"oko/pkg/db"
+	"oko/pkg/e"
+	"oko/pkg/ginapp/types"
+	"oko/pkg/log"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Accounts godoc
+// @Summary List accounts
+// @Description List and search accounts
+// @ID get-admin-accounts
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param object query admin.AccountListForm true "Accounts search request"
+// @Success 200 {object} types.ResponseAdminAccounts
+// @Failure 400 {object} types.ResponseErrorSwg
+// @Failure 500 {object} types.ResponseErrorSwg
+// @Router /admin/accounts [get]
+// @Security ApiKeyAuth
+func Accounts(c *gin.Context) {
+	var form AccountListForm
+
+	if err := c.ShouldBindQuery(&form); err != nil {
+		e.ErrorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	form.Bound()
+
+	models, count, err := listAccounts(form)
+	if err != nil {
+		e.ErrorResponse(c, http.StatusInternalServerError, "Something went wrong")
+		return
+	}
+
+	data := make([]types.AdminAccount, 0, len(models))
+	for _, acc := range models {
+		data = append(data, toView(acc))
+	}
+
+	result := types.Response{
+		Data: data,
+		Meta: types.PaginationResponse{
+			PaginationRequest: types.PaginationRequest{
+				CurrentPage: form.CurrentPage,
+				PerPage:     form.PerPage,
+			},
+			TotalRecords: count,
+			TotalPages:   uint32(math.Ceil(float64(count) / float64(form.PerPage))),
+		},
+	}
+	result.Success(c)
+}
+
+// GetAccount godoc
+// @Summary Get account
+// @Description Get account with roles
+// @ID get-admin-account
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param id path int true "Account ID"
+// @Success 200 {object} types.ResponseAdminAccount
+// @Failure 404 {object} types.ResponseErrorSwg
+// @Router /admin/accounts/{id} [get]
+// @Security ApiKeyAuth
+func GetAccount(c *gin.Context) {
+	acc, ok := getAccount(c)
+	if !ok {
+		return
+	}
+	types.SuccessResponse(c, toView(acc))
+}
+
+// Ban godoc
+// @Summary Ban account
+// @Description Ban account, all account sessions are revoked
+// @ID post-admin-account-ban
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param id path int true "Account ID"
+// @Success 200 {object} types.ResponseAdminAccount
+// @Failure 400 {object} types.ResponseErrorSwg
+// @Failure 404 {object} types.ResponseErrorSwg
+// @Router /admin/accounts/{id}/ban [post]
+// @Security ApiKeyAuth
+func Ban(c *gin.Context) {
+	acc, ok := getAccount(c)
+	if !ok {
+		return
+	}
+	if acc.ID == account.GetContextAcc(c).ID {
+		e.ErrorResponse(c, http.StatusBadRequest, "You can't ban yourself")
+		return
+	}
+	if acc.Status == account.AccStatusService {
+		e.ErrorResponse(c, http.StatusBadRequest, "Service account is disabled by revoking its api keys")
+		return
+	}
+	if !setStatus(c, &acc, account.AccStatusBaned, account.AccStatusUnconfirmed, account.AccStatusActive) {
+		return
+	}
+	if err := account.DropAccountTokens(acc.ID); err != nil {
+		panic(err)
+	}
+	if err := account.DropAccountSignUpTokens(db.GetDB(), acc.ID); err != nil {
+		panic(err)
+	}
+	auditAccount(c, audit.AccountBan, acc.ID, nil)
+
+	types.SuccessResponse(c, toView(acc))
+}
+
+// Unban godoc
+// @Summary Unban account
+// @Description Unban account, it becomes active
+// @ID post-admin-account-unban
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param id path int true "Account ID"
+// @Success 200 {object} types.ResponseAdminAccount
+// @Failure 400 {object} types.ResponseErrorSwg
+// @Failure 404 {object} types.ResponseErrorSwg
+// @Router /admin/accounts/{id}/unban [post]
+// @Security ApiKeyAuth
+func Unban(c *gin.Context) {
+	acc, ok := getAccount(c)
+	if !ok {
+		return
+	}
+	if !setStatus(c, &acc, account.AccStatusActive, account.AccStatusBaned) {
+		return
+	}
+	auditAccount(c, audit.AccountUnban, acc.ID, nil)
+
+	types.SuccessResponse(c, toView(acc))
+}
+
+// Activate godoc
+// @Summary Activate account
+// @Description Activate unconfirmed account without sign up confirmation
+// @ID post-admin-account-activate
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param id path int true "Account ID"
+// @Success 200 {object} types.ResponseAdminAccount
+// @Failure 400 {object} types.ResponseErrorSwg
+// @Failure 404 {object} types.ResponseErrorSwg
+// @Router /admin/accounts/{id}/activate [post]
+// @Security ApiKeyAuth
+func Activate(c *gin.Context) {
+	acc, ok := getAccount(c)
+	if !ok {
+		return
+	}
+	if !setStatus(c, &acc, account.AccStatusActive, account.AccStatusUnconfirmed) {
+		return
+	}
+	if err := account.DropAccountSignUpTokens(db.GetDB(), acc.ID); err != nil {
+		log.Println("Fail to drop sign up tokens", err)
+	}
+	auditAccount(c, audit.AccountActivate, acc.ID, nil)
+
+	types.SuccessResponse(c, toView(acc))
+}
+
+// ResetPassword godoc
+// @Summary Force password reset
+// @Description Replace account password with random one, revoke all account sessions and send recover token
+// @ID post-admin-account-reset-password
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param id path int true "Account ID"
+// @Success 200 {object} types.StdResponse
+// @Failure 400 {object} types.ResponseErrorSwg
+// @Failure 404 {object} types.ResponseErrorSwg
+// @Router /admin/accounts/{id}/reset-password [post]
+// @Security ApiKeyAuth
+func ResetPassword(c *gin.Context) {
+	acc, ok := getAccount(c)
+	if !ok {
+		return
+	}
+	if acc.Status == account.AccStatusService {
+		e.ErrorResponse(c, http.StatusBadRequest, "Service account has no password")
+		return
+	}
+	if err := account.ForceResetPassword(acc); err != nil {
+		e.ErrorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+	auditAccount(c, audit.AccountResetPass, acc.ID, nil)
+
+	types.SuccessEmptyResponse(c)
+}
+
+// ClearLockout godoc
+// @Summary Clear sign in lockout
+// @Description Clear sign in lockout and failed attempts of account e-mail and optionally of ip address
+// @ID delete-admin-account-lockout
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param id path int true "Account ID"
+// @Param ip query string false "Locked ip address"
+// @Success 200 {object} types.StdResponse
+// @Failure 400 {object} types.ResponseErrorSwg
+// @Failure 404 {object} types.ResponseErrorSwg
+// @Failure 500 {object} types.ResponseErrorSwg
+// @Router /admin/accounts/{id}/lockout [delete]
+// @Security ApiKeyAuth
+func ClearLockout(c *gin.Context) {
+	acc, ok := getAccount(c)
+	if !ok {
+		return
+	}
+	if err := account.ClearLockout(acc.Email, c.Query("ip")); err != nil {
+		e.ErrorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	types.SuccessEmptyResponse(c)
+}
+
+// GrantRole godoc
+// @Summary Grant role
+// @Description Grant role to account
+// @ID post-admin-account-role
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param id path int true "Account ID"
+// @Param object body admin.RoleForm true "Role"
+// @Success 200 {object} types.ResponseAdminAccount
+// @Failure 400 {object} types.ResponseErrorSwg
+// @Failure 404 {object} types.ResponseErrorSwg
+// @Router /admin/accounts/{id}/roles [post]
+// @Security ApiKeyAuth
+func GrantRole(c *gin.Context) {
+	var form RoleForm
+
+	if err := c.ShouldBind(&form); err != nil {
+		e.ErrorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	acc, ok := getAccount(c)
+	if !ok {
+		return
+	}
+	if err := account.GrantRole(acc.ID, account.GetRole(form.Role)); err != nil {
+		panic(err)
+	}
+	auditAccount(c, audit.AccountRoleGrant, acc.ID, map[string]string{"role": form.Role})
+
+	acc, _ = findAccount(acc.ID)
+	types.SuccessResponse(c, toView(acc))
+}
+
+// RevokeRole godoc
+// @Summary Revoke role
+// @Description Revoke role from account
+// @ID delete-admin-account-role
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param id path int true "Account ID"
+// @Param role path string true "Role name"
+// @Success 200 {object} types.ResponseAdminAccount
+// @Failure 400 {object} types.ResponseErrorSwg
+// @Failure 404 {object} types.ResponseErrorSwg
+// @Router /admin/accounts/{id}/roles/{role} [delete]
+// @Security ApiKeyAuth
+func RevokeRole(c *gin.Context) {
+	role := account.GetRole(c.Param("role"))
+	if role == 0 {
+		e.ErrorResponse(c, http.StatusBadRequest, "Unknown role")
+		return
+	}
+
+	acc, ok := getAccount(c)
+	if !ok {
+		return
+	}
+	if acc.ID == account.GetContextAcc(c).ID && role == account.AccRoleAdmin {
+		e.ErrorResponse(c, http.StatusBadRequest, "You can't revoke your own admin role")
+		return
+	}
+
+	revoked, err := account.RevokeRole(acc.ID, role)
+	if err != nil {
+		panic(err)
+	}
+	if !revoked {
+		e.ErrorResponse(c, http.StatusNotFound, "Role is not granted")
+		return
+	}
+	auditAccount(c, audit.AccountRoleRevoke, acc.ID, map[string]string{"role": c.Param("role")})
+
+	acc, _ = findAccount(acc.ID)
+	types.SuccessResponse(c, toView(acc))
+}
+
+// Roles godoc
+// @Summary List roles
+// @Description List available roles with their own permissions and included roles
+// @ID get-admin-roles
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Success 200 {object} types.RolesList
+// @Failure 500 {object} types.ResponseErrorSwg
+// @Router /admin/roles [get]
+// @Security ApiKeyAuth
+func Roles(c *gin.Context) {
+	roles, err := account.ListRoles()
+	if err != nil {
+		e.ErrorResponse(c, http.StatusInternalServerError, "Something went wrong")
+		return
+	}
+
+	data := make([]types.RoleItem, 0, len(roles))
+	for _, r := range roles {
+		data = append(data, toRoleView(r))
+	}
+
+	types.SuccessResponse(c, data)
+}
+
+func auditAccount(c *gin.Context, action string, accID int, details interface{}) {
+	audit.Record(c, audit.Event{Action: action, TargetType: audit.TargetAccount, TargetID: accID, Details: details})
+}
+
+func findAccount(id int) (account.Account, error) {
+	var acc account.Account
+	err := db.GetDB().Preload("Roles").First(&acc, id).Error
+	return acc, err
+}
+
+// getAccount loads account from id path param, responds with error if it is not found.
+func getAccount(c *gin.Context) (account.Account, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		e.ErrorResponse(c, http.StatusBadRequest, "Invalid account id")
+		return account.Account{}, false
+	}
+	acc, err := findAccount(id)
+	if err != nil {
+		e.ErrorResponse(c, http.StatusNotFound, "Account not found")
+		return account.Account{}, false
+	}
+	return acc, true
+}
+
+// setStatus moves account to the status if its current status is one of from,
+// responds with error otherwise.
+func setStatus(c *gin.Context, acc *account.Account, status uint, from ...uint) bool {
+	allowed := false
+	for _, s := range from {
+		if acc.Status == s {
+			allowed = true
+			break
+		}
+	}
+	if !allowed {
+		e.ErrorResponse(c, http.StatusBadRequest, "Account is "+acc.GetStrStatus())
+		return false
+	}
+	if err := acc.Update(map[string]interface{}{"status": status}); err != nil {
+		panic(err)
+	}
+	return true
+}
+
+// Invite godoc
+// @Summary Invite account
+// @Description Invite e-mail to sign up, the account gets the roles on sign up
+// @ID post-admin-invite
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param object body admin.InviteForm true "Invite fields"
+// @Success 200 {object} types.ResponseInvite
+// @Failure 400 {object} types.ResponseErrorSwg
+// @Failure 500 {object} types.ResponseErrorSwg
+// @Router /admin/invites [post]
+// @Security ApiKeyAuth
+func Invite(c *gin.Context) {
+	var form InviteForm
+
+	if err := c.ShouldBind(&form); err != nil {
+		e.ErrorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	acc := account.GetContextAcc(c)
+	inv := account.NewInvite(strings.ToLower(form.Email), acc.ID)
+	for _, r := range form.Roles {
+		inv.Roles = append(inv.Roles, int64(account.GetRole(r)))
+	}
+	if err := account.CreateInvite(&inv, acc); err != nil {
+		panic(err)
+	}
+
+	types.SuccessResponse(c, account.ToInviteView(inv))
+}
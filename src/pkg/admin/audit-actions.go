@@ -0,0 +1,73 @@
+package admin
+
+import (
+	"math"
+	"net/http"
+	"oko/pkg/audit"
+	"oko/pkg/e"
+	"oko/pkg/ginapp/types"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AuditLog godoc
+// @Summary List audit log
+// @Description List security and admin events, newest first
+// @ID get-audit
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param object query admin.AuditListForm true "Audit log filter"
+// @Success 200 {object} types.ResponseAuditLog
+// @Failure 400 {object} types.ResponseErrorSwg
+// @Failure 500 {object} types.ResponseErrorSwg
+// @Router /audit [get]
+// @Security ApiKeyAuth
+func AuditLog(c *gin.Context) {
+	var form AuditListForm
+
+	if err := c.ShouldBindQuery(&form); err != nil {
+		e.ErrorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+	if form.PerPage == 0 {
+		form.PerPage = 15
+	}
+
+	var offset uint32
+	if form.CurrentPage > 1 {
+		offset = (form.CurrentPage - 1) * form.PerPage
+	}
+	entries, count, err := audit.List(audit.Filter{
+		ActorID:    form.ActorID,
+		Action:     form.Action,
+		TargetType: form.TargetType,
+		TargetID:   form.TargetID,
+		From:       form.From,
+		To:         form.To,
+		Offset:     offset,
+		Limit:      form.PerPage,
+	})
+	if err != nil {
+		e.ErrorResponse(c, http.StatusInternalServerError, "Something went wrong")
+		return
+	}
+
+	data := make([]types.AuditEntry, 0, len(entries))
+	for _, entry := range entries {
+		data = append(data, toAuditView(entry))
+	}
+
+	result := types.Response{
+		Data: data,
+		Meta: types.PaginationResponse{
+			PaginationRequest: types.PaginationRequest{
+				CurrentPage: form.CurrentPage,
+				PerPage:     form.PerPage,
+			},
+			TotalRecords: count,
+			TotalPages:   uint32(math.Ceil(float64(count) / float64(form.PerPage))),
+		},
+	}
+	result.Success(c)
+}
@@ -0,0 +1,52 @@
+package admin
+
+import (
+	"oko/pkg/account"
+	"oko/pkg/ginapp/controller"
+
+	"github.com/gin-gonic/gin"
+)
+
+func NewController() controller.Ctrl {
+	return controller.Ctrl{
+		Name:     "admin",
+		Handlers: controller.HandlerList{account.Auth(true, []int{})},
+		Acts: []controller.Act{
+			{Method: "GET", Route: "/accounts/", Handlers: []gin.HandlerFunc{Accounts}, HumanOnly: true,
+				Permission: account.PermAccountManage},
+			{Method: "GET", Route: "/accounts/:id", Handlers: []gin.HandlerFunc{GetAccount}, HumanOnly: true,
+				Permission: account.PermAccountManage},
+			{Method: "POST", Route: "/accounts/:id/ban", Handlers: []gin.HandlerFunc{Ban}, HumanOnly: true,
+				Permission: account.PermAccountManage},
+			{Method: "POST", Route: "/accounts/:id/unban", Handlers: []gin.HandlerFunc{Unban}, HumanOnly: true,
+				Permission: account.PermAccountManage},
+			{Method: "POST", Route: "/accounts/:id/activate", Handlers: []gin.HandlerFunc{Activate}, HumanOnly: true,
+				Permission: account.PermAccountManage},
+			{Method: "POST", Route: "/accounts/:id/reset-password", Handlers: []gin.HandlerFunc{ResetPassword},
+				HumanOnly: true, Permission: account.PermAccountManage},
+			{Method: "DELETE", Route: "/accounts/:id/lockout", Handlers: []gin.HandlerFunc{ClearLockout},
+				HumanOnly: true, Permission: account.PermAccountManage},
+			{Method: "POST", Route: "/accounts/:id/roles/", Handlers: []gin.HandlerFunc{GrantRole}, HumanOnly: true,
+				Permission: account.PermAccountManage},
+			{Method: "DELETE", Route: "/accounts/:id/roles/:role", Handlers: []gin.HandlerFunc{RevokeRole},
+				HumanOnly: true, Permission: account.PermAccountManage},
+			{Method: "POST", Route: "/invites/", Handlers: []gin.HandlerFunc{Invite}, HumanOnly: true,
+				Permission: account.PermAccountManage},
+			{Method: "GET", Route: "/roles/", Handlers: []gin.HandlerFunc{Roles}, HumanOnly: true,
+				Permission: account.PermAccountManage},
+		},
+	}
+}
+
+// NewAuditController serves the audit log, it lives here as account records events to the log
+// and the audit package can't depend on it.
+func NewAuditController() controller.Ctrl {
+	return controller.Ctrl{
+		Name:     "audit",
+		Handlers: controller.HandlerList{account.Auth(true, []int{})},
+		Acts: []controller.Act{
+			{Method: "GET", Route: "/", Handlers: []gin.HandlerFunc{AuditLog}, HumanOnly: true,
+				Permission: account.PermAuditRead},
+		},
+	}
+}
@@ -0,0 +1,32 @@
+package admin
+
+import (
+	"oko/pkg/ginapp/types"
+	"time"
+)
+
+type AccountListForm struct {
+	types.PaginationRequest
+	Query  string `json:"query" form:"query" binding:"omitempty,max=255"`
+	Status uint   `json:"status" form:"status" binding:"omitempty,AccStatus"`
+	Role   string `json:"role" form:"role" binding:"omitempty,AccRole"`
+}
+
+type InviteForm struct {
+	Email string   `json:"email" form:"email" binding:"required,email,UniqueEmail"`
+	Roles []string `json:"roles" form:"roles" binding:"UniqueList,dive,AccRole"`
+}
+
+type RoleForm struct {
+	Role string `json:"role" form:"role" binding:"required,AccRole"`
+}
+
+type AuditListForm struct {
+	types.PaginationRequest
+	ActorID    int       `json:"actor_id" form:"actor_id" binding:"omitempty,min=1"`
+	Action     string    `json:"action" form:"action" binding:"omitempty,max=64"`
+	TargetType string    `json:"target_type" form:"target_type" binding:"omitempty,max=32"`
+	TargetID   string    `json:"target_id" form:"target_id" binding:"omitempty,max=64"`
+	From       time.Time `json:"from" form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To         time.Time `json:"to" form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+}
@@ -0,0 +1,48 @@
+package admin
+
+import (
+	"oko/pkg/account"
+	"oko/pkg/db"
+	"oko/pkg/log"
+	"strings"
+)
+
+// listAccounts returns page of accounts matching the form, query is looked up in e-mail and name.
+func listAccounts(form AccountListForm) (models []account.Account, count uint32, err error) {
+	var offset uint32
+	if form.CurrentPage > 1 {
+		offset = (form.CurrentPage - 1) * form.PerPage
+	}
+
+	query := db.GetDB().Model(&account.Account{})
+	if form.Query != "" {
+		like := db.LikeContains(strings.ToLower(form.Query))
+		query = query.Where("lower(email) like ? or lower(name) like ?", like, like)
+	}
+	if form.Status != 0 {
+		query = query.Where("status = ?", form.Status)
+	}
+	if form.Role != "" {
+		query = query.Where(
+			"exists (select 1 from account_role ar where ar.account_id = account.id and ar.role = ?)",
+			account.GetRole(form.Role),
+		)
+	}
+
+	if err = query.Count(&count).Error; err != nil {
+		log.Println("Error in admin.listAccounts", err)
+		return
+	}
+
+	err = query.
+		Preload("Roles").
+		Order("id").
+		Offset(offset).
+		Limit(form.PerPage).
+		Find(&models).Error
+	if err != nil {
+		log.Println("Error in admin.listAccounts", err)
+	}
+
+	return
+}
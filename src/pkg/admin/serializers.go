@@ -0,0 +1,59 @@
+package admin
+
+import (
+	"encoding/json"
+	"oko/pkg/account"
+	"oko/pkg/audit"
+	"oko/pkg/ginapp/types"
+)
+
+func toRoleView(r account.Role) types.RoleItem {
+	perms := make([]string, 0, len(r.Permissions))
+	for _, p := range r.Permissions {
+		perms = append(perms, p.Name)
+	}
+	includes := make([]string, 0, len(r.Includes))
+	for _, i := range r.Includes {
+		includes = append(includes, i.Name)
+	}
+	return types.RoleItem{
+		Role:        r.ID,
+		StrRole:     r.Name,
+		Permissions: perms,
+		Includes:    includes,
+	}
+}
+
+func toView(acc account.Account) types.AdminAccount {
+	roles := make([]types.RoleItem, 0, len(acc.Roles))
+	for _, r := range acc.Roles {
+		roles = append(roles, types.RoleItem{Role: r.Role, StrRole: r.GetStrRole()})
+	}
+	return types.AdminAccount{
+		Profile: types.Profile{
+			ID:        acc.ID,
+			Email:     acc.Email,
+			Name:      acc.Name,
+			Status:    acc.Status,
+			StatusStr: acc.GetStrStatus(),
+			CreatedAt: acc.CreatedAt,
+			UpdatedAt: acc.UpdatedAt,
+		},
+		Roles: roles,
+	}
+}
+
+func toAuditView(entry audit.Entry) types.AuditEntry {
+	return types.AuditEntry{
+		ID:             entry.ID,
+		ActorID:        entry.ActorID,
+		OrganizationID: entry.OrganizationID,
+		Action:         entry.Action,
+		TargetType:     entry.TargetType,
+		TargetID:       entry.TargetID,
+		IP:             entry.IP,
+		UserAgent:      entry.UserAgent,
+		Details:        json.RawMessage(entry.Details),
+		CreatedAt:      entry.CreatedAt,
+	}
+}
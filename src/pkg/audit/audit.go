@@ -0,0 +1,131 @@
+// Package audit keeps append-only log of security and admin events.
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"oko/pkg/db"
+	"oko/pkg/log"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Actions of the log.
+const (
+	AccountSignIn       = "account.sign_in"
+	AccountSignInFailed = "account.sign_in_failed"
+	AccountSignOut      = "account.sign_out"
+	AccountSignOutAll   = "account.sign_out_all"
+	AccountRoleGrant    = "account.role_grant"
+	AccountRoleRevoke   = "account.role_revoke"
+	AccountBan          = "account.ban"
+	AccountUnban        = "account.unban"
+	AccountActivate     = "account.activate"
+	AccountResetPass    = "account.reset_password"
+	AccountFeedReset    = "account.feed_token_reset"
+	OrgMemberAdd        = "org.member_add"
+	OrgMemberUpdate     = "org.member_update"
+	OrgMemberRemove     = "org.member_remove"
+	RuleCreate          = "rule.create"
+	RuleUpdate          = "rule.update"
+	RuleDelete          = "rule.delete"
+	TriggerCreate       = "trigger.create"
+	TriggerUpdate       = "trigger.update"
+	TriggerDelete       = "trigger.delete"
+	ActionCreate        = "action.create"
+	ActionUpdate        = "action.update"
+	ActionDelete        = "action.delete"
+	ActionRuleAdd       = "action.rule_add"
+	ActionRuleRemove    = "action.rule_remove"
+	ProxyCreate         = "proxy.create"
+	ProxyUpdate         = "proxy.update"
+	ProxyDelete         = "proxy.delete"
+	RepostExport        = "repost.export"
+	RssImport           = "rss.import"
+	RssExport           = "rss.export"
+)
+
+// Types of event targets.
+const (
+	TargetAccount      = "account"
+	TargetOrganization = "organization"
+	TargetRule         = "rule"
+	TargetTrigger      = "trigger"
+	TargetAction       = "action"
+	TargetProxy        = "proxy"
+	TargetRepost       = "repost"
+	TargetRss          = "rss"
+)
+
+// keys of the context set by account.Auth and org.Active, audit can't import those packages
+// as they record events themselves
+const (
+	actorKey = "account_id"
+	orgKey   = "organization_id"
+)
+
+// Entry is a record of the log, the table rejects updates and deletes.
+type Entry struct {
+	ID             int64     `json:"id"`
+	ActorID        *int      `json:"actor_id"`
+	OrganizationID *uint     `json:"organization_id"`
+	Action         string    `json:"action"`
+	TargetType     string    `json:"target_type"`
+	TargetID       string    `json:"target_id"`
+	IP             string    `json:"ip"`
+	UserAgent      string    `json:"user_agent"`
+	Details        string    `json:"details" gorm:"type:jsonb"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+func (Entry) TableName() string {
+	return "audit_log"
+}
+
+// Event describes what happened, the actor is the authenticated account of the request
+// unless ActorID is set.
+type Event struct {
+	Action     string
+	ActorID    int
+	TargetType string
+	TargetID   interface{}
+	Details    interface{}
+}
+
+// Record appends the event to the log. Failures are logged only, so the audited action isn't interrupted.
+func Record(c *gin.Context, ev Event) {
+	entry := Entry{
+		Action:     ev.Action,
+		TargetType: ev.TargetType,
+		IP:         c.ClientIP(),
+		UserAgent:  c.Request.UserAgent(),
+	}
+	if ev.TargetID != nil {
+		entry.TargetID = fmt.Sprint(ev.TargetID)
+	}
+
+	actorID := ev.ActorID
+	if actorID == 0 {
+		actorID = c.GetInt(actorKey)
+	}
+	if actorID != 0 {
+		entry.ActorID = &actorID
+	}
+	if val, ok := c.Get(orgKey); ok {
+		if orgID, ok := val.(uint); ok && orgID != 0 {
+			entry.OrganizationID = &orgID
+		}
+	}
+
+	details, err := json.Marshal(ev.Details)
+	if err != nil {
+		log.Println("Fail to encode audit details", ev.Action, err)
+		details = []byte("null")
+	}
+	entry.Details = string(details)
+
+	if err = db.GetDB().Create(&entry).Error; err != nil {
+		log.Println("Fail to record audit event", ev.Action, err)
+	}
+}
@@ -0,0 +1,57 @@
+package audit
+
+import (
+	"oko/pkg/db"
+	"oko/pkg/log"
+	"time"
+)
+
+// Filter selects entries of the log, empty fields aren't applied.
+type Filter struct {
+	ActorID    int
+	Action     string
+	TargetType string
+	TargetID   string
+	From       time.Time
+	To         time.Time
+	Offset     uint32
+	Limit      uint32
+}
+
+// List returns entries matching the filter, newest first, and their total count.
+func List(f Filter) (entries []Entry, count uint32, err error) {
+	query := db.GetDB().Model(&Entry{})
+	if f.ActorID != 0 {
+		query = query.Where("actor_id = ?", f.ActorID)
+	}
+	if f.Action != "" {
+		query = query.Where("action = ?", f.Action)
+	}
+	if f.TargetType != "" {
+		query = query.Where("target_type = ?", f.TargetType)
+	}
+	if f.TargetID != "" {
+		query = query.Where("target_id = ?", f.TargetID)
+	}
+	if !f.From.IsZero() {
+		query = query.Where("created_at >= ?", f.From)
+	}
+	if !f.To.IsZero() {
+		query = query.Where("created_at < ?", f.To)
+	}
+
+	if err = query.Count(&count).Error; err != nil {
+		log.Println("Error in audit.List", err)
+		return
+	}
+
+	err = query.
+		Order("created_at desc, id desc").
+		Offset(f.Offset).
+		Limit(f.Limit).
+		Find(&entries).Error
+	if err != nil {
+		log.Println("Error in audit.List", err)
+	}
+	return
+}
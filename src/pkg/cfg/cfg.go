@@ -4,14 +4,45 @@ import (
 	"log"
 	"os"
 	"strconv"
+	"strings"
+)
+
+// Modes of auth tokens, in jwt mode signed access tokens are verified without storage lookups
+// and sessions are kept as refresh token families.
+const (
+	AuthModeSession = "session"
+	AuthModeJWT     = "jwt"
 )
 
 const (
 	defAutTokenKey              = "Authorization"
-	defMinPassLen               = 8
+	defAPIKeyKey                = "X-Api-Key"
+	defOrgKey                   = "X-Organization"
+	defTokenPrefix              = "oko_live_"
+	defAccessTokenLifetime      = 900
+	defRefreshTokenLifetime     = 2592000
 	defAPIListen                = ":80"
 	defaultSignUpTokenLifetime  = 604800
 	defaultRecoverTokenLifetime = 86400
+	defEmailChangeTokenLifetime = 86400
+	defInviteTokenLifetime      = 604800
+	defOpenSignUp               = true
+	defaultSignUpResendInterval = 60
+	defSignInMaxFailures        = 5
+	defSignInMaxIPFailures      = 50
+	defSignInFailureWindow      = 900
+	defSignInLockout            = 60
+	defSignInMaxLockout         = 86400
+	defSignInChallengeLifetime  = 300
+	defTOTPIssuer               = "OKO"
+	defOIDCCallbackPath         = "/oidc/callback"
+	defOIDCGroupsClaim          = "groups"
+	defMailBackend              = "file"
+	smtpMailBackend             = "smtp"
+	defMailFrom                 = "oko@localhost"
+	defMailDir                  = "mail"
+	defMailTemplatesDir         = "templates/mail"
+	defSMTPPort                 = 25
 )
 
 type Settings struct {
@@ -21,10 +52,50 @@ type Settings struct {
 	AuthTokenLength      int
 	AuthTokenLifetime    int
 	AuthTokenKey         string
-	MinPassLen           int
+	TokenPrefix          string
+	AuthMode             string
+	JWTKeys              []JWTKey
+	AccessTokenLifetime  int
+	RefreshTokenLifetime int
+	APIKeyKey            string
+	FeedTokenSecret      string
+	OrgKey               string
+	Password             PasswordPolicy
 	SignUpTokenLifetime  int
 	RecoverTokenLifetime int
+	EmailTokenLifetime   int
+	InviteTokenLifetime  int
+	OpenSignUp           bool
+	SignUpResendInterval int
+	SignInMaxFailures    int
+	SignInMaxIPFailures  int
+	SignInFailureWindow  int
+	SignInLockout        int
+	SignInMaxLockout     int
+	ChallengeLifetime    int
+	TOTPIssuer           string
+	OIDCIssuer           string
+	OIDCClientID         string
+	OIDCClientSecret     string
+	OIDCRedirectURL      string
+	OIDCScopes           []string
+	OIDCGroupsClaim      string
+	OIDCRoleMap          map[string]string
 	FrontURL             string
+	MailBackend          string
+	MailFrom             string
+	MailDir              string
+	MailTemplatesDir     string
+	SMTPHost             string
+	SMTPPort             int
+	SMTPUser             string
+	SMTPPassword         string
+}
+
+// JWTKey is a signing key of access tokens, the first configured key signs new tokens.
+type JWTKey struct {
+	ID     string
+	Secret string
 }
 
 var App Settings //nolint
@@ -42,6 +113,59 @@ func Load() {
 		App.AuthTokenLength = key
 	}
 
+	val = os.Getenv("TOKEN_PREFIX")
+	if val == "" {
+		App.TokenPrefix = defTokenPrefix
+	} else {
+		App.TokenPrefix = val
+	}
+
+	val = os.Getenv("AUTH_TOKEN_MODE")
+	switch val {
+	case "":
+		App.AuthMode = AuthModeSession
+	case AuthModeSession, AuthModeJWT:
+		App.AuthMode = val
+	default:
+		errors = append(errors, errorsCategory+": Unknown AUTH_TOKEN_MODE.")
+	}
+
+	// keys are set as kid:secret pairs separated by comma, e.g. 2020-02:secret2,2020-01:secret1
+	App.JWTKeys = nil
+	for _, pair := range strings.Split(os.Getenv("JWT_KEYS"), ",") {
+		if pair = strings.TrimSpace(pair); pair == "" {
+			continue
+		}
+		parts := strings.SplitN(pair, ":", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			errors = append(errors, errorsCategory+": Invalid JWT_KEYS.")
+			break
+		}
+		App.JWTKeys = append(App.JWTKeys, JWTKey{ID: parts[0], Secret: parts[1]})
+	}
+	if App.AuthMode == AuthModeJWT && len(App.JWTKeys) == 0 {
+		errors = append(errors, errorsCategory+": Undefined JWT_KEYS.")
+	}
+
+	// feed tokens are disabled when the secret is empty
+	App.FeedTokenSecret = os.Getenv("FEED_TOKEN_SECRET")
+
+	val = os.Getenv("ACCESS_TOKEN_LIFETIME")
+	key, err = strconv.Atoi(val)
+	if err != nil {
+		App.AccessTokenLifetime = defAccessTokenLifetime
+	} else {
+		App.AccessTokenLifetime = key
+	}
+
+	val = os.Getenv("REFRESH_TOKEN_LIFETIME")
+	key, err = strconv.Atoi(val)
+	if err != nil {
+		App.RefreshTokenLifetime = defRefreshTokenLifetime
+	} else {
+		App.RefreshTokenLifetime = key
+	}
+
 	val = os.Getenv("API_LISTEN")
 	if val == "" {
 		App.AuthTokenKey = defAPIListen
@@ -79,14 +203,24 @@ func Load() {
 		App.AuthTokenKey = val
 	}
 
-	val = os.Getenv("MIN_PASS_LEN")
-	key, err = strconv.Atoi(val)
-	if err != nil {
-		App.MinPassLen = defMinPassLen
+	val = os.Getenv("API_KEY_KEY")
+	if val == "" {
+		App.APIKeyKey = defAPIKeyKey
+	} else {
+		App.APIKeyKey = val
+	}
+
+	val = os.Getenv("ORGANIZATION_KEY")
+	if val == "" {
+		App.OrgKey = defOrgKey
 	} else {
-		App.MinPassLen = key
+		App.OrgKey = val
 	}
 
+	var policyErrors []string
+	App.Password, policyErrors = loadPasswordPolicy()
+	errors = append(errors, policyErrors...)
+
 	val = os.Getenv("SIGN_UP_TOKEN_LIFETIME")
 	key, err = strconv.Atoi(val)
 	if err != nil {
@@ -95,6 +229,69 @@ func Load() {
 		App.SignUpTokenLifetime = key
 	}
 
+	val = os.Getenv("SIGN_UP_RESEND_INTERVAL")
+	key, err = strconv.Atoi(val)
+	if err != nil {
+		App.SignUpResendInterval = defaultSignUpResendInterval
+	} else {
+		App.SignUpResendInterval = key
+	}
+
+	val = os.Getenv("SIGN_IN_MAX_FAILURES")
+	key, err = strconv.Atoi(val)
+	if err != nil {
+		App.SignInMaxFailures = defSignInMaxFailures
+	} else {
+		App.SignInMaxFailures = key
+	}
+
+	val = os.Getenv("SIGN_IN_MAX_IP_FAILURES")
+	key, err = strconv.Atoi(val)
+	if err != nil {
+		App.SignInMaxIPFailures = defSignInMaxIPFailures
+	} else {
+		App.SignInMaxIPFailures = key
+	}
+
+	val = os.Getenv("SIGN_IN_FAILURE_WINDOW")
+	key, err = strconv.Atoi(val)
+	if err != nil {
+		App.SignInFailureWindow = defSignInFailureWindow
+	} else {
+		App.SignInFailureWindow = key
+	}
+
+	val = os.Getenv("SIGN_IN_LOCKOUT")
+	key, err = strconv.Atoi(val)
+	if err != nil {
+		App.SignInLockout = defSignInLockout
+	} else {
+		App.SignInLockout = key
+	}
+
+	val = os.Getenv("SIGN_IN_MAX_LOCKOUT")
+	key, err = strconv.Atoi(val)
+	if err != nil {
+		App.SignInMaxLockout = defSignInMaxLockout
+	} else {
+		App.SignInMaxLockout = key
+	}
+
+	val = os.Getenv("SIGN_IN_CHALLENGE_LIFETIME")
+	key, err = strconv.Atoi(val)
+	if err != nil {
+		App.ChallengeLifetime = defSignInChallengeLifetime
+	} else {
+		App.ChallengeLifetime = key
+	}
+
+	val = os.Getenv("TOTP_ISSUER")
+	if val == "" {
+		App.TOTPIssuer = defTOTPIssuer
+	} else {
+		App.TOTPIssuer = val
+	}
+
 	val = os.Getenv("RECOVER_TOKEN_LIFETIME")
 	key, err = strconv.Atoi(val)
 	if err != nil {
@@ -103,6 +300,30 @@ func Load() {
 		App.RecoverTokenLifetime = key
 	}
 
+	val = os.Getenv("EMAIL_CHANGE_TOKEN_LIFETIME")
+	key, err = strconv.Atoi(val)
+	if err != nil {
+		App.EmailTokenLifetime = defEmailChangeTokenLifetime
+	} else {
+		App.EmailTokenLifetime = key
+	}
+
+	val = os.Getenv("INVITE_TOKEN_LIFETIME")
+	key, err = strconv.Atoi(val)
+	if err != nil {
+		App.InviteTokenLifetime = defInviteTokenLifetime
+	} else {
+		App.InviteTokenLifetime = key
+	}
+
+	// without open sign up accounts are registered by invites only
+	val = os.Getenv("OPEN_SIGN_UP")
+	if open, err := strconv.ParseBool(val); err != nil {
+		App.OpenSignUp = defOpenSignUp
+	} else {
+		App.OpenSignUp = open
+	}
+
 	// front url
 	val = os.Getenv("FRONT_URL")
 	if val == "" {
@@ -111,6 +332,98 @@ func Load() {
 		App.FrontURL = val
 	}
 
+	// single sign on is enabled by issuer url, the redirect defaults to the front callback page
+	App.OIDCIssuer = os.Getenv("OIDC_ISSUER")
+	App.OIDCClientID = os.Getenv("OIDC_CLIENT_ID")
+	App.OIDCClientSecret = os.Getenv("OIDC_CLIENT_SECRET")
+	if App.OIDCIssuer != "" && App.OIDCClientID == "" {
+		errors = append(errors, errorsCategory+": Undefined OIDC_CLIENT_ID.")
+	}
+
+	val = os.Getenv("OIDC_REDIRECT_URL")
+	if val == "" {
+		App.OIDCRedirectURL = App.FrontURL + defOIDCCallbackPath
+	} else {
+		App.OIDCRedirectURL = val
+	}
+
+	App.OIDCScopes = strings.Fields(os.Getenv("OIDC_SCOPES"))
+	if len(App.OIDCScopes) == 0 {
+		App.OIDCScopes = []string{"email", "profile"}
+	}
+
+	val = os.Getenv("OIDC_GROUPS_CLAIM")
+	if val == "" {
+		App.OIDCGroupsClaim = defOIDCGroupsClaim
+	} else {
+		App.OIDCGroupsClaim = val
+	}
+
+	// groups of identity provider are mapped to role names as group:role pairs separated by comma
+	App.OIDCRoleMap = map[string]string{}
+	for _, pair := range strings.Split(os.Getenv("OIDC_ROLE_MAP"), ",") {
+		if pair = strings.TrimSpace(pair); pair == "" {
+			continue
+		}
+		parts := strings.SplitN(pair, ":", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			errors = append(errors, errorsCategory+": Invalid OIDC_ROLE_MAP.")
+			break
+		}
+		App.OIDCRoleMap[parts[0]] = parts[1]
+	}
+
+	// mail, messages are kept in files unless smtp is configured
+	App.SMTPHost = os.Getenv("SMTP_HOST")
+	val = os.Getenv("MAIL_BACKEND")
+	switch {
+	case val != "":
+		App.MailBackend = val
+	case App.SMTPHost != "":
+		App.MailBackend = smtpMailBackend
+	default:
+		App.MailBackend = defMailBackend
+	}
+
+	// sender is only required to deliver mail, file backend keeps messages locally
+	val = os.Getenv("MAIL_FROM")
+	if val == "" && App.MailBackend == smtpMailBackend {
+		errors = append(errors, errorsCategory+": Undefined MAIL_FROM.")
+	} else if val == "" {
+		App.MailFrom = defMailFrom
+	} else {
+		App.MailFrom = val
+	}
+
+	val = os.Getenv("MAIL_DIR")
+	if val == "" {
+		App.MailDir = defMailDir
+	} else {
+		App.MailDir = val
+	}
+
+	val = os.Getenv("MAIL_TEMPLATES_DIR")
+	if val == "" {
+		App.MailTemplatesDir = defMailTemplatesDir
+	} else {
+		App.MailTemplatesDir = val
+	}
+
+	if App.SMTPHost == "" && App.MailBackend == smtpMailBackend {
+		errors = append(errors, errorsCategory+": Undefined SMTP_HOST.")
+	}
+
+	val = os.Getenv("SMTP_PORT")
+	key, err = strconv.Atoi(val)
+	if err != nil {
+		App.SMTPPort = defSMTPPort
+	} else {
+		App.SMTPPort = key
+	}
+
+	App.SMTPUser = os.Getenv("SMTP_USER")
+	App.SMTPPassword = os.Getenv("SMTP_PASSWORD")
+
 	if len(errors) > 0 {
 		log.Panicln(errors)
 	}
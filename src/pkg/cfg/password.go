@@ -0,0 +1,109 @@
+package cfg
+
+import (
+	"os"
+	"strconv"
+	"strings"
+)
+
+const (
+	defPassMinLength = 8
+	defPassMaxRepeat = 0
+)
+
+// PasswordPolicy is the set of rules new passwords are checked against.
+type PasswordPolicy struct {
+	MinLength     int
+	RequireLower  bool
+	RequireUpper  bool
+	RequireDigit  bool
+	RequireSymbol bool
+	// MaxRepeat limits the same character repeated in a row, 0 disables the check
+	MaxRepeat int
+	// CheckIdentity rejects passwords containing e-mail or name of the account
+	CheckIdentity bool
+	// BreachedFile is a file of SHA-1 hashes of breached passwords sorted by hash, one per line
+	BreachedFile string
+}
+
+// Describe returns human readable requirements of the policy, it's used as validator message.
+func (p PasswordPolicy) Describe() string {
+	var classes []string
+	if p.RequireLower {
+		classes = append(classes, "one lowercase letter")
+	}
+	if p.RequireUpper {
+		classes = append(classes, "one uppercase letter")
+	}
+	if p.RequireDigit {
+		classes = append(classes, "one number")
+	}
+	if p.RequireSymbol {
+		classes = append(classes, "one special character")
+	}
+
+	rules := []string{"be at least " + strconv.Itoa(p.MinLength) + " characters"}
+	if len(classes) > 0 {
+		rules = append(rules, "contain "+joinList(classes))
+	}
+	if p.MaxRepeat > 0 {
+		rules = append(rules, "not repeat a character more than "+strconv.Itoa(p.MaxRepeat)+" times in a row")
+	}
+	if p.CheckIdentity {
+		rules = append(rules, "not contain your e-mail or name")
+	}
+	if p.BreachedFile != "" {
+		rules = append(rules, "not be a known breached password")
+	}
+	return "Password must " + joinList(rules)
+}
+
+func joinList(items []string) string {
+	if len(items) == 1 {
+		return items[0]
+	}
+	return strings.Join(items[:len(items)-1], ", ") + " and " + items[len(items)-1]
+}
+
+func loadPasswordPolicy() (p PasswordPolicy, errors []string) {
+	const errorsCategory = "Settings"
+
+	p.MinLength = defPassMinLength
+	if val := os.Getenv("MIN_PASS_LEN"); val != "" {
+		if key, err := strconv.Atoi(val); err == nil && key > 0 {
+			p.MinLength = key
+		} else {
+			errors = append(errors, errorsCategory+": Invalid MIN_PASS_LEN.")
+		}
+	}
+
+	p.RequireLower = getBool("PASS_REQUIRE_LOWER", true)
+	p.RequireUpper = getBool("PASS_REQUIRE_UPPER", true)
+	p.RequireDigit = getBool("PASS_REQUIRE_DIGIT", true)
+	p.RequireSymbol = getBool("PASS_REQUIRE_SYMBOL", false)
+	p.CheckIdentity = getBool("PASS_CHECK_IDENTITY", true)
+
+	p.MaxRepeat = defPassMaxRepeat
+	if val := os.Getenv("PASS_MAX_REPEAT"); val != "" {
+		if key, err := strconv.Atoi(val); err == nil && key >= 0 {
+			p.MaxRepeat = key
+		} else {
+			errors = append(errors, errorsCategory+": Invalid PASS_MAX_REPEAT.")
+		}
+	}
+
+	p.BreachedFile = os.Getenv("BREACHED_PASSWORDS_FILE")
+	if p.BreachedFile != "" {
+		if _, err := os.Stat(p.BreachedFile); err != nil {
+			errors = append(errors, errorsCategory+": Invalid BREACHED_PASSWORDS_FILE.")
+		}
+	}
+	return p, errors
+}
+
+func getBool(name string, def bool) bool {
+	if val, err := strconv.ParseBool(os.Getenv(name)); err == nil {
+		return val
+	}
+	return def
+}
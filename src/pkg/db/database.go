@@ -65,3 +65,8 @@ func GetDB() *gorm.DB {
 	}
 	return conn
 }
+
+// SetDB replaces the connection, tests use it to run queries against mocked database.
+func SetDB(db *gorm.DB) {
+	conn = db
+}
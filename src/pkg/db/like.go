@@ -0,0 +1,11 @@
+package db
+
+import "strings"
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// LikeContains returns LIKE pattern matching strings which contain s, wildcards of s are matched literally.
+// Backslash is the default escape character of postgres LIKE.
+func LikeContains(s string) string {
+	return "%" + likeEscaper.Replace(s) + "%"
+}
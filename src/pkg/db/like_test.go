@@ -0,0 +1,12 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLikeContains(t *testing.T) {
+	require.Equal(t, "%example%", LikeContains("example"))
+	require.Equal(t, `%100\% \_off\\%`, LikeContains(`100% _off\`))
+}
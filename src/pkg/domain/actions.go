@@ -1,8 +1,12 @@
 package domain
 
 import (
+	"math"
 	"net/http"
 	"oko/pkg/e"
+	"oko/pkg/feed"
+	"oko/pkg/ginapp/types"
+	"oko/pkg/org"
 	"oko/pkg/rss"
 	"strconv"
 
@@ -22,17 +26,29 @@ func NewHandler(repo Repository) Handler {
 
 // List godoc
 // @Summary List
-// @Description List
+// @Description List domains, query is looked up in domain name.
+// @Description Sort is name, created_at or updated_at, prefixed with "-" for descending order
 // @ID get-domains-list
 // @Tags Domain
 // @Accept json
 // @Produce json
+// @Param object query domain.ListForm true "Domain list request"
 // @Success 200 {object} types.StdResponse
 // @Failure 400 {object} types.ResponseErrorSwg
 // @Failure 500 {object} types.ResponseErrorSwg
 // @Router /domain [get]
+// @Security ApiKeyAuth
 func (h *domainHandler) List(c *gin.Context) {
-	models, err := h.repository.List()
+	var form ListForm
+
+	if err := c.ShouldBindQuery(&form); err != nil {
+		e.ErrorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	form.Bound()
+
+	models, count, err := h.repository.Search(org.GetID(c), form.Filter())
 	if err != nil {
 		e.ErrorResponse(c, http.StatusInternalServerError, "Something went wrong")
 		return
@@ -44,6 +60,14 @@ func (h *domainHandler) List(c *gin.Context) {
 		http.StatusOK,
 		gin.H{
 			"data": serializer.To(),
+			"meta": types.PaginationResponse{
+				PaginationRequest: types.PaginationRequest{
+					CurrentPage: form.CurrentPage,
+					PerPage:     form.PerPage,
+				},
+				TotalRecords: count,
+				TotalPages:   uint32(math.Ceil(float64(count) / float64(form.PerPage))),
+			},
 		})
 }
 
@@ -59,6 +83,7 @@ func (h *domainHandler) List(c *gin.Context) {
 // @Failure 400 {object} types.ResponseErrorSwg
 // @Failure 500 {object} types.ResponseErrorSwg
 // @Router /domain/{id} [get]
+// @Security ApiKeyAuth
 func (h *domainHandler) Get(c *gin.Context) {
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
@@ -66,7 +91,7 @@ func (h *domainHandler) Get(c *gin.Context) {
 		return
 	}
 
-	model, err := h.repository.Get(uint(id))
+	model, err := h.repository.Get(org.GetID(c), uint(id))
 	if err != nil {
 		e.ErrorResponse(c, http.StatusBadRequest, "Something went wrong")
 		return
@@ -81,9 +106,33 @@ func (h *domainHandler) Get(c *gin.Context) {
 		})
 }
 
+// checkRssLinks rejects rss links with hosts which are not public, the links are stored without probing,
+// so they are held to the host policy of the fetch client. Error response is written on failure.
+func checkRssLinks(c *gin.Context, links []string) bool {
+	for _, link := range links {
+		err := rss.CheckLinkHost(c.Request.Context(), link)
+		if err == nil {
+			continue
+		}
+		message := "Rss link host is not available"
+		if err == feed.ErrForbidden {
+			message = "Rss link host is not public"
+		}
+		e.ErrorResponse(c, http.StatusBadRequest, e.CustomFieldError{
+			Name:    "rss_links",
+			Tag:     "rss_links",
+			Param:   "",
+			Value:   link,
+			Message: message,
+		})
+		return false
+	}
+	return true
+}
+
 // Create godoc
 // @Summary Create
-// @Description Create
+// @Description Create domain with rss links, links with hosts which are not public are rejected
 // @ID get-domains-create
 // @Tags Domain
 // @Accept json
@@ -93,6 +142,7 @@ func (h *domainHandler) Get(c *gin.Context) {
 // @Failure 400 {object} types.ResponseErrorSwg
 // @Failure 500 {object} types.ResponseErrorSwg
 // @Router /domain [post]
+// @Security ApiKeyAuth
 func (h *domainHandler) Create(c *gin.Context) {
 	var form CreateForm
 
@@ -100,11 +150,19 @@ func (h *domainHandler) Create(c *gin.Context) {
 		e.ErrorResponse(c, http.StatusBadRequest, err)
 		return
 	}
+	if !checkRssLinks(c, form.RssLinks) {
+		return
+	}
 
-	rsses := []*rss.Rss{{
-		Link: form.RssLink,
-	}}
+	rsses := make([]*rss.Rss, 0, len(form.RssLinks))
+	for _, link := range form.RssLinks {
+		rsses = append(rsses, &rss.Rss{
+			Link:           link,
+			OrganizationID: org.GetID(c),
+		})
+	}
 	model := &Domain{
+		OrganizationID:   org.GetID(c),
 		Name:             form.Name,
 		Rss:              rsses,
 		TelegramUsername: form.TelegramUsername,
@@ -126,7 +184,9 @@ func (h *domainHandler) Create(c *gin.Context) {
 
 // Update godoc
 // @Summary Update
-// @Description Update
+// @Description Update domain, rss links are replaced by the given list: new links are added,
+// @Description missing ones are removed and the rest are kept. Omitted list leaves rss links untouched,
+// @Description links with hosts which are not public are rejected
 // @ID get-domains-update
 // @Tags Domain
 // @Accept json
@@ -137,6 +197,7 @@ func (h *domainHandler) Create(c *gin.Context) {
 // @Failure 400 {object} types.ResponseErrorSwg
 // @Failure 500 {object} types.ResponseErrorSwg
 // @Router /domain/{id} [put]
+// @Security ApiKeyAuth
 func (h *domainHandler) Update(c *gin.Context) {
 	var form UpdateForm
 
@@ -150,15 +211,28 @@ func (h *domainHandler) Update(c *gin.Context) {
 		e.ErrorResponse(c, http.StatusBadRequest, "Something went wrong")
 		return
 	}
+	if !checkRssLinks(c, form.RssLinks) {
+		return
+	}
 
 	model := &Domain{
 		Name:             form.Name,
 		TelegramUsername: form.TelegramUsername,
 	}
 
-	if err := h.repository.Update(uint(id), model); err != nil {
+	updated, err := h.repository.Update(org.GetID(c), uint(id), model, form.RssLinks)
+	if err != nil {
 		e.ErrorResponse(c, http.StatusBadRequest, "Something went wrong")
+		return
 	}
+
+	serializer := Serializer{*updated}
+
+	c.JSON(
+		http.StatusOK,
+		gin.H{
+			"data": serializer.To(),
+		})
 }
 
 // Delete godoc
@@ -187,7 +261,7 @@ func (h *domainHandler) Delete(c *gin.Context) {
 		},
 	}
 
-	if err := h.repository.Delete(model); err != nil {
+	if err := h.repository.Delete(org.GetID(c), model); err != nil {
 		e.ErrorResponse(c, http.StatusBadRequest, "Something went wrong")
 		return
 	}
@@ -1,8 +1,10 @@
 package domain
 
 import (
+	"oko/pkg/account"
 	"oko/pkg/db"
 	"oko/pkg/ginapp/controller"
+	"oko/pkg/org"
 
 	"github.com/gin-gonic/gin"
 )
@@ -20,13 +22,13 @@ func NewController() controller.Ctrl {
 	handler := NewHandler(repository)
 	return controller.Ctrl{
 		Name:     "domain",
-		Handlers: nil,
+		Handlers: controller.HandlerList{account.Auth(true, []int{}), org.Active()},
 		Acts: []controller.Act{
-			{Method: "GET", Route: "/", Handlers: []gin.HandlerFunc{handler.List}},
-			{Method: "GET", Route: "/:id", Handlers: []gin.HandlerFunc{handler.Get}},
-			{Method: "PUT", Route: "/:id", Handlers: []gin.HandlerFunc{handler.Update}},
-			{Method: "DELETE", Route: "/:id", Handlers: []gin.HandlerFunc{handler.Delete}},
-			{Method: "POST", Route: "/", Handlers: []gin.HandlerFunc{handler.Create}},
+			{Method: "GET", Route: "/", Handlers: []gin.HandlerFunc{handler.List}, Scope: account.ScopeDomainRead},
+			{Method: "GET", Route: "/:id", Handlers: []gin.HandlerFunc{handler.Get}, Scope: account.ScopeDomainRead},
+			{Method: "PUT", Route: "/:id", Handlers: []gin.HandlerFunc{handler.Update}, Scope: account.ScopeDomainWrite},
+			{Method: "DELETE", Route: "/:id", Handlers: []gin.HandlerFunc{handler.Delete}, Scope: account.ScopeDomainWrite},
+			{Method: "POST", Route: "/", Handlers: []gin.HandlerFunc{handler.Create}, Scope: account.ScopeDomainWrite},
 		},
 	}
 }
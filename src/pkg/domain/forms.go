@@ -1,13 +1,50 @@
 package domain
 
+import "oko/pkg/ginapp/types"
+
+type ListForm struct {
+	types.PaginationRequest
+	Query       string `json:"query" form:"query" binding:"omitempty,max=255"`
+	HasRss      *bool  `json:"has_rss" form:"has_rss"`
+	HasTelegram *bool  `json:"has_telegram" form:"has_telegram"`
+	Cache       *bool  `json:"cache" form:"cache"`
+	HasError    *bool  `json:"has_error" form:"has_error"`
+	Sort        string `json:"sort" form:"sort" binding:"omitempty,oneof=name -name created_at -created_at updated_at -updated_at"` //nolint
+	Last        bool   `json:"last" form:"last"`
+	ForTelegram bool   `json:"for_telegram" form:"for_telegram"`
+	ExistRss    bool   `json:"exist_rss" form:"exist_rss"`
+}
+
+// Filter converts the form to repository filter.
+func (f ListForm) Filter() Filter {
+	var offset uint32
+	if f.CurrentPage > 1 {
+		offset = (f.CurrentPage - 1) * f.PerPage
+	}
+	return Filter{
+		Last:        f.Last,
+		ForTelegram: f.ForTelegram,
+		ExistRss:    f.ExistRss,
+		Query:       f.Query,
+		HasRss:      f.HasRss,
+		HasTelegram: f.HasTelegram,
+		Cache:       f.Cache,
+		HasError:    f.HasError,
+		Sort:        f.Sort,
+		Offset:      offset,
+		Limit:       f.PerPage,
+	}
+}
+
 type CreateForm struct {
-	Name             string `json:"name" form:"name"`
-	RssLink          string `json:"rss_link" form:"rss_link"`
-	TelegramUsername string `json:"telegram_username" form:"telegram_username"`
+	Name             string   `json:"name" form:"name"`
+	RssLinks         []string `json:"rss_links" form:"rss_links" binding:"omitempty,max=50,UniqueList,dive,required,url"`
+	TelegramUsername string   `json:"telegram_username" form:"telegram_username"`
 }
 
+// UpdateForm changes non-blank fields, rss links are replaced when the list is given.
 type UpdateForm struct {
-	Name             string `json:"name" form:"name"`
-	RssLink          string `json:"rss_link" form:"rss_link"`
-	TelegramUsername string `json:"telegram_username" form:"telegram_username"`
+	Name             string   `json:"name" form:"name"`
+	RssLinks         []string `json:"rss_links" form:"rss_links" binding:"omitempty,max=50,UniqueList,dive,required,url"`
+	TelegramUsername string   `json:"telegram_username" form:"telegram_username"`
 }
@@ -8,6 +8,7 @@ import (
 
 type Domain struct {
 	gorm.Model
+	OrganizationID   uint `gorm:"column:organization_id"`
 	Name             string
 	TelegramUsername string `gorm:"column:telegram_username;default:'null'"`
 	Cache            bool   `gorm:"column:cache;default:'false'"`
@@ -19,12 +20,41 @@ func (Domain) TableName() string {
 	return "domains"
 }
 
+// Filter narrows domain list, nil tri-state fields are not checked.
 type Filter struct {
 	Last        bool
 	ForTelegram bool
 	ExistRss    bool
+
+	// Query is looked up in domain name
+	Query       string
+	HasRss      *bool
+	HasTelegram *bool
+	Cache       *bool
+	HasError    *bool
+	// Sort is a column name optionally prefixed with "-" for descending order
+	Sort   string
+	Offset uint32
+	Limit  uint32
 }
 
 func (f Filter) preloadRss() bool {
 	return f.Last || f.ExistRss
 }
+
+// sortColumns maps Filter.Sort values to order clauses.
+var sortColumns = map[string]string{
+	"name":        "domains.name",
+	"-name":       "domains.name desc",
+	"created_at":  "domains.created_at",
+	"-created_at": "domains.created_at desc",
+	"updated_at":  "domains.updated_at",
+	"-updated_at": "domains.updated_at desc",
+}
+
+func (f Filter) order() string {
+	if order, ok := sortColumns[f.Sort]; ok {
+		return order
+	}
+	return "domains.updated_at"
+}
@@ -2,22 +2,32 @@ package domain
 
 import (
 	"errors"
+	"oko/pkg/db"
 	"oko/pkg/log"
+	"oko/pkg/rss"
+	"strings"
 	"time"
 
 	"github.com/jinzhu/gorm"
 )
 
+// Repository methods take id of the organization owning domains,
+// 0 means all organizations and is used by background jobs only.
 type Repository interface {
-	List(filter ...Filter) (models []*Domain, err error)
-	Get(id uint) (*Domain, error)
+	List(orgID uint, filter ...Filter) (models []*Domain, err error)
+	Search(orgID uint, filter Filter) (models []*Domain, count uint32, err error)
+	Get(orgID, id uint) (*Domain, error)
 	Create(model *Domain) error
-	Update(id uint, model *Domain) error
-	Delete(model *Domain) error
+	Update(orgID, id uint, model *Domain, links []string) (*Domain, error)
+	Delete(orgID uint, model *Domain) error
 	GetForCacheJob(limit int) []Domain
-	GetByName(name string) (*Domain, bool)
+	GetByName(orgID uint, name string) (*Domain, bool)
+	ResolveDomain(tx *gorm.DB, orgID uint, name string) (id uint, created bool, err error)
 }
 
+// existRss matches domains with at least one rss link.
+const existRss = "exists (select 1 from rss_links where rss_links.domain_id = domains.id and rss_links.deleted_at is null)"
+
 type domainRepository struct {
 	db *gorm.DB
 }
@@ -28,14 +38,26 @@ func NewDomainRepository(db *gorm.DB) Repository {
 	}
 }
 
-func (r *domainRepository) Get(id uint) (model *Domain, err error) {
+// scoped limits query to the organization.
+func (r *domainRepository) scoped(orgID uint) *gorm.DB {
+	return scopedTo(r.db, orgID)
+}
+
+func scopedTo(db *gorm.DB, orgID uint) *gorm.DB {
+	if orgID == 0 {
+		return db
+	}
+	return db.Where("domains.organization_id = ?", orgID)
+}
+
+func (r *domainRepository) Get(orgID, id uint) (model *Domain, err error) {
 	model = &Domain{
 		Model: gorm.Model{
 			ID: id,
 		},
 	}
 
-	if err = r.db.First(model).Error; err != nil && err != gorm.ErrRecordNotFound {
+	if err = r.scoped(orgID).First(model).Error; err != nil && err != gorm.ErrRecordNotFound {
 		log.Println("Error in DomainRepository.Get", err)
 		return model, err
 	}
@@ -51,27 +73,89 @@ func (r *domainRepository) Create(model *Domain) error {
 	return nil
 }
 
-func (r *domainRepository) Update(id uint, model *Domain) error {
+// Update changes non-blank fields of the domain and reconciles its rss links with links,
+// nil links leave rss links untouched. The domain is returned with rss links.
+func (r *domainRepository) Update(orgID, id uint, model *Domain, links []string) (*Domain, error) {
+	tx := r.db.Begin()
+	if err := tx.Error; err != nil {
+		return nil, err
+	}
+
 	domain := &Domain{
 		Model: gorm.Model{
 			ID: id,
 		},
 	}
-
-	result := r.db.Model(domain).Updates(model)
-	if err := result.Error; err != nil {
+	if err := scopedTo(tx, orgID).First(domain).Error; err != nil {
+		tx.Rollback()
+		log.Println("Error in DomainRepository.Update", err)
+		return nil, err
+	}
+	if err := tx.Model(domain).Updates(model).Error; err != nil {
+		tx.Rollback()
+		log.Println("Error in DomainRepository.Update", err)
+		return nil, err
+	}
+	if links != nil {
+		if err := reconcileRss(tx, domain, links); err != nil {
+			tx.Rollback()
+			log.Println("Error in DomainRepository.Update", err)
+			return nil, err
+		}
+	}
+	if err := tx.Model(domain).Related(&domain.Rss).Error; err != nil {
+		tx.Rollback()
 		log.Println("Error in DomainRepository.Update", err)
+		return nil, err
+	}
+
+	return domain, tx.Commit().Error
+}
+
+// reconcileRss makes rss links of the domain match links: missing are created,
+// others are deleted and matching ones are kept as is.
+func reconcileRss(tx *gorm.DB, domain *Domain, links []string) error {
+	var current []*rss.Rss
+	if err := tx.Where("domain_id = ?", domain.ID).Order("id").Find(&current).Error; err != nil {
 		return err
 	}
-	if rowsAffected := result.RowsAffected; rowsAffected == 0 {
-		log.Printf("Error in DomainRepository.Update, rowsAffected: %v", rowsAffected)
-		return errors.New("no records updated, No match was found")
+
+	wanted := make(map[string]bool, len(links))
+	for _, link := range links {
+		wanted[link] = true
+	}
+	var drop []uint
+	for _, item := range current {
+		if wanted[item.Link] {
+			delete(wanted, item.Link)
+		} else {
+			drop = append(drop, item.ID)
+		}
+	}
+
+	if len(drop) > 0 {
+		if err := tx.Where("id in (?)", drop).Delete(&rss.Rss{}).Error; err != nil {
+			return err
+		}
+	}
+	for _, link := range links {
+		if !wanted[link] {
+			continue
+		}
+		delete(wanted, link)
+		if err := tx.Create(&rss.Rss{
+			Link:           link,
+			DomainID:       domain.ID,
+			OrganizationID: domain.OrganizationID,
+		}).Error; err != nil {
+			return err
+		}
 	}
 	return nil
 }
 
-func (r *domainRepository) Delete(model *Domain) error {
-	result := r.db.Delete(model)
+func (r *domainRepository) Delete(orgID uint, model *Domain) error {
+	result := r.scoped(orgID).Delete(model)
 	if err := result.Error; err != nil {
 		log.Println("Error in DomainRepository.Delete", err)
 		return err
@@ -84,28 +168,65 @@ func (r *domainRepository) Delete(model *Domain) error {
 	return nil
 }
 
-func (r *domainRepository) List(f ...Filter) (models []*Domain, err error) {
-	q := r.db.Model(&Domain{})
+// filtered applies filter conditions, paging and order are left to the caller.
+func (r *domainRepository) filtered(orgID uint, f Filter) *gorm.DB {
+	q := r.scoped(orgID).Model(&Domain{})
 
-	if len(f) > 0 {
-		if f[0].ForTelegram {
-			q = q.Where("telegram_username is not null")
+	if f.ForTelegram {
+		q = q.Where("telegram_username is not null")
+	}
+	if f.ExistRss {
+		q = q.Where(existRss)
+	}
+	if f.Last {
+		q = q.Where("(updated_at < ? or updated_at is null)", time.Now().AddDate(0, 0, -1))
+	}
+	if f.Query != "" {
+		q = q.Where("lower(domains.name) like ?", db.LikeContains(strings.ToLower(f.Query)))
+	}
+	if f.HasRss != nil {
+		if *f.HasRss {
+			q = q.Where(existRss)
+		} else {
+			q = q.Where("not " + existRss)
 		}
-		if f[0].ExistRss {
-			q = q.Joins("join rss_links on domains.id = rss_links.id").
-				Select("Distinct(domains.*)")
+	}
+	if f.HasTelegram != nil {
+		if *f.HasTelegram {
+			q = q.Where("coalesce(domains.telegram_username, '') not in ('', 'null')")
+		} else {
+			q = q.Where("coalesce(domains.telegram_username, '') in ('', 'null')")
 		}
-		if f[0].Last {
-			q = q.Where("(updated_at < ? or updated_at is null)", time.Now().AddDate(0, 0, -1)).
-				Limit(3)
+	}
+	if f.Cache != nil {
+		q = q.Where("domains.cache = ?", *f.Cache)
+	}
+	if f.HasError != nil {
+		if *f.HasError {
+			q = q.Where("coalesce(domains.error, '') not in ('', 'null')")
+		} else {
+			q = q.Where("coalesce(domains.error, '') in ('', 'null')")
 		}
 	}
 
-	if len(f) == 0 || f[0].preloadRss() {
+	return q
+}
+
+func (r *domainRepository) List(orgID uint, f ...Filter) (models []*Domain, err error) {
+	var filter Filter
+	if len(f) > 0 {
+		filter = f[0]
+	}
+	q := r.filtered(orgID, filter)
+
+	if filter.Last {
+		q = q.Limit(3)
+	}
+	if len(f) == 0 || filter.preloadRss() {
 		q = q.Preload("Rss")
 	}
 
-	if err = q.Order("domains.updated_at").Find(&models).Error; err != nil {
+	if err = q.Order(filter.order()).Find(&models).Error; err != nil {
 		log.Println("Error in DomainRepository.List", err)
 		return
 	}
@@ -113,6 +234,29 @@ func (r *domainRepository) List(f ...Filter) (models []*Domain, err error) {
 	return
 }
 
+// Search returns page of domains matching the filter and total count of them.
+func (r *domainRepository) Search(orgID uint, f Filter) (models []*Domain, count uint32, err error) {
+	q := r.filtered(orgID, f)
+
+	if err = q.Count(&count).Error; err != nil {
+		log.Println("Error in DomainRepository.Search", err)
+		return
+	}
+
+	err = q.
+		Preload("Rss").
+		Order(f.order()).
+		Order("domains.id").
+		Offset(f.Offset).
+		Limit(f.Limit).
+		Find(&models).Error
+	if err != nil {
+		log.Println("Error in DomainRepository.Search", err)
+	}
+
+	return
+}
+
 func (r domainRepository) GetForCacheJob(limit int) []Domain {
 	var domains []Domain
 
@@ -127,8 +271,29 @@ func (r domainRepository) GetForCacheJob(limit int) []Domain {
 	return domains
 }
 
-func (r domainRepository) GetByName(name string) (*Domain, bool) {
+func (r domainRepository) GetByName(orgID uint, name string) (*Domain, bool) {
 	dom := &Domain{}
-	notFound := r.db.Model(dom).Where("name = ?", name).First(dom).RecordNotFound()
+	notFound := r.scoped(orgID).Model(dom).Where("name = ?", name).First(dom).RecordNotFound()
 	return dom, notFound
 }
+
+// ResolveDomain returns id of the domain with the name, missing domain is created in the transaction tx.
+// It's used by rss import which can't import this package.
+func (r *domainRepository) ResolveDomain(tx *gorm.DB, orgID uint, name string) (id uint, created bool, err error) {
+	txr := &domainRepository{db: tx}
+	if dom, notFound := txr.GetByName(orgID, name); !notFound {
+		if dom.ID == 0 {
+			return 0, false, errors.New("fail to find domain " + name)
+		}
+		return dom.ID, false, nil
+	}
+
+	dom := &Domain{
+		OrganizationID: orgID,
+		Name:           name,
+	}
+	if err = txr.Create(dom); err != nil {
+		return 0, false, err
+	}
+	return dom.ID, true, nil
+}
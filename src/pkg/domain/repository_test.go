@@ -40,7 +40,7 @@ func (s *Suite) TestList() {
 		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(id))
 	s.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "rss_links"  WHERE "rss_links"."deleted_at" IS NULL AND (("domain_id" IN ($1)))`)).
 		WithArgs(id).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(id))
-	_, err := s.repo.List()
+	_, err := s.repo.List(0)
 	require.NoError(s.T(), err)
 }
 
@@ -49,18 +49,18 @@ func (s *Suite) TestListDomainsForTelegram() {
 	id := 1
 	s.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "domains"  WHERE "domains"."deleted_at" IS NULL AND ((telegram_username is not null)) ORDER BY domains.updated_at`)).
 		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(id))
-	_, err := s.repo.List(Filter{ForTelegram: true})
+	_, err := s.repo.List(0, Filter{ForTelegram: true})
 	require.NoError(s.T(), err)
 }
 
 //nolint
 func (s *Suite) TestListDomainsWithRss() {
 	id := 1
-	s.mock.ExpectQuery(regexp.QuoteMeta(`SELECT Distinct(domains.*) FROM "domains" join rss_links on domains.id = rss_links.id WHERE "domains"."deleted_at" IS NULL ORDER BY domains.updated_at`)).
+	s.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "domains"  WHERE "domains"."deleted_at" IS NULL AND ((`+existRss+`)) ORDER BY domains.updated_at`)).
 		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(id))
 	s.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "rss_links"  WHERE "rss_links"."deleted_at" IS NULL AND (("domain_id" IN ($1)))`)).
 		WithArgs(id).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(id))
-	_, err := s.repo.List(Filter{ExistRss: true})
+	_, err := s.repo.List(0, Filter{ExistRss: true})
 	require.NoError(s.T(), err)
 }
 
@@ -71,10 +71,80 @@ func (s *Suite) TestListDomainsLast() {
 		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(id))
 	s.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "rss_links"  WHERE "rss_links"."deleted_at" IS NULL AND (("domain_id" IN ($1)))`)).
 		WithArgs(id).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(id))
-	_, err := s.repo.List(Filter{Last: true})
+	_, err := s.repo.List(0, Filter{Last: true})
 	require.NoError(s.T(), err)
 }
 
+//nolint
+func (s *Suite) TestListOrganizationDomains() {
+	id := 1
+	s.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "domains"  WHERE "domains"."deleted_at" IS NULL AND ((domains.organization_id = $1)) ORDER BY domains.updated_at`)).
+		WithArgs(2).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(id))
+	s.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "rss_links"  WHERE "rss_links"."deleted_at" IS NULL AND (("domain_id" IN ($1)))`)).
+		WithArgs(id).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(id))
+	_, err := s.repo.List(2)
+	require.NoError(s.T(), err)
+}
+
+//nolint
+func (s *Suite) TestGetOrganizationDomain() {
+	id := 1
+	s.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "domains"  WHERE "domains"."deleted_at" IS NULL AND "domains"."id" = $1 AND ((domains.organization_id = $2)) ORDER BY "domains"."id" ASC LIMIT 1`)).
+		WithArgs(id, 2).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(id))
+	_, err := s.repo.Get(2, uint(id))
+	require.NoError(s.T(), err)
+}
+
+//nolint
+func (s *Suite) TestSearch() {
+	id := 1
+	s.mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "domains"  WHERE "domains"."deleted_at" IS NULL AND ((domains.organization_id = $1) AND (lower(domains.name) like $2))`)).
+		WithArgs(2, "%news%").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(21))
+	s.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "domains"  WHERE "domains"."deleted_at" IS NULL AND ((domains.organization_id = $1) AND (lower(domains.name) like $2)) ORDER BY domains.name desc,"domains"."id" LIMIT 10 OFFSET 20`)).
+		WithArgs(2, "%news%").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(id))
+	s.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "rss_links"  WHERE "rss_links"."deleted_at" IS NULL AND (("domain_id" IN ($1)))`)).
+		WithArgs(id).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	models, count, err := s.repo.Search(2, Filter{Query: "News", Sort: "-name", Offset: 20, Limit: 10})
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), uint32(21), count)
+	require.Len(s.T(), models, 1)
+}
+
+//nolint
+func (s *Suite) TestSearchState() {
+	yes, no := true, false
+	s.mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "domains"  WHERE "domains"."deleted_at" IS NULL AND ((not `+existRss+`) AND (coalesce(domains.telegram_username, '') not in ('', 'null')) AND (domains.cache = $1) AND (coalesce(domains.error, '') not in ('', 'null')))`)).
+		WithArgs(false).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	s.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "domains"  WHERE "domains"."deleted_at" IS NULL AND ((not `+existRss+`) AND (coalesce(domains.telegram_username, '') not in ('', 'null')) AND (domains.cache = $1) AND (coalesce(domains.error, '') not in ('', 'null'))) ORDER BY domains.updated_at,"domains"."id" LIMIT 15 OFFSET 0`)).
+		WithArgs(false).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	_, _, err := s.repo.Search(0, Filter{HasRss: &no, HasTelegram: &yes, Cache: &no, HasError: &yes, Limit: 15})
+	require.NoError(s.T(), err)
+}
+
+//nolint
+func (s *Suite) TestUpdateReconcileRss() {
+	id := 1
+	s.mock.ExpectBegin()
+	s.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "domains"  WHERE "domains"."deleted_at" IS NULL AND "domains"."id" = $1 AND ((domains.organization_id = $2))`)).
+		WithArgs(id, 2).WillReturnRows(sqlmock.NewRows([]string{"id", "organization_id"}).AddRow(id, 2))
+	s.mock.ExpectExec(regexp.QuoteMeta(`UPDATE "domains" SET "name" = $1, "updated_at" = $2`)).
+		WithArgs("news", sqlmock.AnyArg(), id).WillReturnResult(sqlmock.NewResult(0, 1))
+	s.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "rss_links"  WHERE "rss_links"."deleted_at" IS NULL AND ((domain_id = $1)) ORDER BY "id"`)).
+		WithArgs(id).WillReturnRows(sqlmock.NewRows([]string{"id", "link"}).AddRow(10, "http://a/rss").AddRow(11, "http://b/rss"))
+	s.mock.ExpectExec(regexp.QuoteMeta(`UPDATE "rss_links" SET "deleted_at"=$1  WHERE "rss_links"."deleted_at" IS NULL AND ((id in ($2)))`)).
+		WithArgs(sqlmock.AnyArg(), 10).WillReturnResult(sqlmock.NewResult(0, 1))
+	s.mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "rss_links"`)).
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, "http://c/rss", id, 2, 0, "", "", 0, 0).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(12))
+	s.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "rss_links"  WHERE "rss_links"."deleted_at" IS NULL AND (("domain_id" = $1))`)).
+		WithArgs(id).WillReturnRows(sqlmock.NewRows([]string{"id", "link"}).AddRow(11, "http://b/rss").AddRow(12, "http://c/rss"))
+	s.mock.ExpectCommit()
+
+	model, err := s.repo.Update(2, uint(id), &Domain{Name: "news"}, []string{"http://b/rss", "http://c/rss"})
+	require.NoError(s.T(), err)
+	require.Len(s.T(), model.Rss, 2)
+}
+
 func (s *Suite) AfterTest(_, _ string) {
 	require.NoError(s.T(), s.mock.ExpectationsWereMet())
 }
@@ -15,11 +15,15 @@ type ListSerializer struct {
 }
 
 type RssResponse struct {
-	ID        uint       `json:"id"`
-	CreatedAt time.Time  `json:"created_at"`
-	UpdatedAt time.Time  `json:"updated_at"`
-	DeleteAt  *time.Time `json:"delete_at,omitempty"`
-	Link      string     `json:"link"`
+	ID          uint       `json:"id"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	DeleteAt    *time.Time `json:"delete_at,omitempty"`
+	Link        string     `json:"link"`
+	FetchedAt   *time.Time `json:"fetched_at"`
+	ItemCount   int        `json:"item_count"`
+	Error       string     `json:"error"`
+	NextFetchAt *time.Time `json:"next_fetch_at"`
 }
 
 type Response struct {
@@ -43,11 +47,15 @@ func toRssResponse(dom *Domain) *[]*RssResponse {
 	for _, rss := range dom.Rss {
 		if rss != nil {
 			res = append(res, &RssResponse{
-				ID:        rss.ID,
-				CreatedAt: rss.CreatedAt,
-				UpdatedAt: rss.UpdatedAt,
-				DeleteAt:  rss.DeletedAt,
-				Link:      rss.Link,
+				ID:          rss.ID,
+				CreatedAt:   rss.CreatedAt,
+				UpdatedAt:   rss.UpdatedAt,
+				DeleteAt:    rss.DeletedAt,
+				Link:        rss.Link,
+				FetchedAt:   rss.FetchedAt,
+				ItemCount:   rss.ItemCount,
+				Error:       rss.Error,
+				NextFetchAt: rss.NextFetchAt,
 			})
 		}
 	}
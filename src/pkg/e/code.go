@@ -8,8 +8,10 @@ const (
 	Error         = 500
 	InvalidParams = 400
 	Unauthorized  = 401
+	Forbidden     = 403
 	NotFound      = 404
 	NotAcceptable = 406
+	TooManyReqs   = 429
 
 	ErrorAuthCheckTokenFail    = 20001
 	ErrorAuthCheckTokenTimeout = 20002
@@ -20,6 +22,8 @@ const (
 	ErrorAuthRole              = 20007
 	ErrorAuthAppKeyNotAllowed  = 20008
 	Processing                 = 20009
+	ErrorAuthLocked            = 20010
+	ErrorAuthRefreshReused     = 20011
 )
 
 var CodeStatuses = map[int]int{
@@ -28,8 +32,10 @@ var CodeStatuses = map[int]int{
 	Error:                      http.StatusInternalServerError,
 	InvalidParams:              http.StatusBadRequest,
 	Unauthorized:               http.StatusUnauthorized,
+	Forbidden:                  http.StatusForbidden,
 	NotFound:                   http.StatusNotFound,
 	NotAcceptable:              http.StatusNotAcceptable,
+	TooManyReqs:                http.StatusTooManyRequests,
 	ErrorAuthCheckTokenFail:    http.StatusUnauthorized,
 	ErrorAuthCheckTokenTimeout: http.StatusUnauthorized,
 	ErrorAuthToken:             http.StatusUnauthorized,
@@ -38,4 +44,6 @@ var CodeStatuses = map[int]int{
 	ErrorAuthBanned:            http.StatusUnauthorized,
 	ErrorAuthRole:              http.StatusUnauthorized,
 	ErrorAuthAppKeyNotAllowed:  http.StatusUnauthorized,
+	ErrorAuthLocked:            http.StatusTooManyRequests,
+	ErrorAuthRefreshReused:     http.StatusUnauthorized,
 }
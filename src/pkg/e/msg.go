@@ -24,8 +24,10 @@ var MsgFlags = map[int]string{
 	Error:                      "fail",
 	InvalidParams:              "invalid params",
 	Unauthorized:               "unauthorized",
+	Forbidden:                  "forbidden",
 	NotFound:                   "not found",
 	NotAcceptable:              "not acceptable",
+	TooManyReqs:                "too many requests",
 	ErrorAuthCheckTokenFail:    "token check failed",
 	ErrorAuthCheckTokenTimeout: "token expired",
 	ErrorAuthToken:             "token authentication failed",
@@ -35,6 +37,8 @@ var MsgFlags = map[int]string{
 	ErrorAuthRole:              "no role needed",
 	ErrorAuthAppKeyNotAllowed:  "use of the method with the application key is not allowed",
 	Processing:                 "link is being processed",
+	ErrorAuthLocked:            "too many failed sign in attempts",
+	ErrorAuthRefreshReused:     "refresh token reused, the session is revoked",
 }
 
 var ValidatorMessages = map[string]string{}
@@ -0,0 +1,224 @@
+package feed
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// DefMaxSize limits feed document size when Client.MaxSize is not set.
+const DefMaxSize = 10 << 20
+
+var (
+	ErrTooLarge    = errors.New("feed: document is too large")
+	ErrNotModified = errors.New("feed: not modified")
+	ErrForbidden   = errors.New("feed: address is not public")
+)
+
+// Validators are cache validators of the previous response used for conditional requests.
+type Validators struct {
+	ETag         string
+	LastModified string
+}
+
+// StatusError is returned for non 2xx responses.
+type StatusError struct {
+	Code int
+}
+
+func (e StatusError) Error() string {
+	return "feed: unexpected status " + strconv.Itoa(e.Code)
+}
+
+// Probe is result of checking a link, Feed is nil for html page with feeds discovered on it.
+type Probe struct {
+	// URL is the final url after redirects
+	URL        string
+	Feed       *Feed
+	Candidates []Candidate
+}
+
+// Client fetches and parses feeds.
+type Client struct {
+	HTTP      *http.Client
+	UserAgent string
+	MaxSize   int64
+}
+
+// Fetch downloads and parses the feed, relative links are resolved against the final url.
+func (c *Client) Fetch(ctx context.Context, link string) (*Feed, error) {
+	f, _, err := c.FetchIfModified(ctx, link, Validators{})
+	return f, err
+}
+
+// FetchIfModified is Fetch sending validators of the previous response, ErrNotModified is returned
+// if the feed is not changed. Validators of the response are returned for the next request.
+func (c *Client) FetchIfModified(ctx context.Context, link string, prev Validators) (*Feed, Validators, error) {
+	data, resp, err := c.get(ctx, link, prev)
+	if err != nil {
+		return nil, prev, err
+	}
+
+	f, err := Parse(data, resp.Request.URL)
+	if err != nil {
+		return nil, prev, err
+	}
+	return f, Validators{
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+	}, nil
+}
+
+// Probe checks the link is a feed, feeds advertised by html page are returned as candidates.
+// ErrFormat is returned for documents which are neither feeds nor pages with feeds.
+func (c *Client) Probe(ctx context.Context, link string) (*Probe, error) {
+	data, resp, err := c.get(ctx, link, Validators{})
+	if err != nil {
+		return nil, err
+	}
+
+	p := &Probe{URL: resp.Request.URL.String()}
+	if p.Feed, err = Parse(data, resp.Request.URL); err == nil {
+		return p, nil
+	}
+	if p.Candidates, _ = Discover(data, resp.Request.URL); len(p.Candidates) == 0 {
+		return nil, err
+	}
+	return p, nil
+}
+
+// get downloads the document, response body is already read and closed.
+func (c *Client) get(ctx context.Context, link string, prev Validators) ([]byte, *http.Response, error) {
+	req, err := http.NewRequest(http.MethodGet, link, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Accept",
+		"application/rss+xml, application/atom+xml, application/feed+json, application/json;q=0.9, "+
+			"application/xml;q=0.9, text/xml;q=0.8, */*;q=0.5")
+	if c.UserAgent != "" {
+		req.Header.Set("User-Agent", c.UserAgent)
+	}
+	if prev.ETag != "" {
+		req.Header.Set("If-None-Match", prev.ETag)
+	}
+	if prev.LastModified != "" {
+		req.Header.Set("If-Modified-Since", prev.LastModified)
+	}
+
+	client := c.HTTP
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		if errors.Is(err, ErrForbidden) {
+			return nil, nil, ErrForbidden
+		}
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified {
+		return nil, nil, ErrNotModified
+	}
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return nil, nil, StatusError{Code: resp.StatusCode}
+	}
+
+	maxSize := c.MaxSize
+	if maxSize <= 0 {
+		maxSize = DefMaxSize
+	}
+	data, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxSize+1))
+	if err != nil {
+		return nil, nil, err
+	}
+	if int64(len(data)) > maxSize {
+		return nil, nil, ErrTooLarge
+	}
+	return data, resp, nil
+}
+
+var privateNets = func() []*net.IPNet {
+	var nets []*net.IPNet
+	for _, cidr := range []string{
+		"0.0.0.0/8", "10.0.0.0/8", "100.64.0.0/10", "127.0.0.0/8", "169.254.0.0/16", "172.16.0.0/12",
+		"192.168.0.0/16", "::/128", "::1/128", "fc00::/7", "fe80::/10",
+	} {
+		_, n, _ := net.ParseCIDR(cidr)
+		nets = append(nets, n)
+	}
+	return nets
+}()
+
+func isPublicIP(ip net.IP) bool {
+	if ip.IsMulticast() {
+		return false
+	}
+	for _, n := range privateNets {
+		if n.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// CheckHost returns ErrForbidden if the host is localhost or resolves to addresses PublicClient refuses,
+// links given by users are checked before they are stored.
+func CheckHost(ctx context.Context, host string) error {
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	if host == "" || host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return ErrForbidden
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		if !isPublicIP(ip) {
+			return ErrForbidden
+		}
+		return nil
+	}
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return err
+	}
+	for _, addr := range addrs {
+		if !isPublicIP(addr.IP) {
+			return ErrForbidden
+		}
+	}
+	return nil
+}
+
+// PublicClient returns http client refusing connections to loopback, private and link-local
+// addresses, it's used to fetch links given by users.
+func PublicClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: func(_, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || !isPublicIP(ip) {
+				return ErrForbidden
+			}
+			return nil
+		},
+	}
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			Proxy:               nil,
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: timeout,
+		},
+	}
+}
@@ -0,0 +1,75 @@
+package feed
+
+import (
+	"bytes"
+	"net/url"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// feedTypes are media types of alternate links treated as feeds.
+var feedTypes = map[string]bool{
+	"application/rss+xml":   true,
+	"application/atom+xml":  true,
+	"application/feed+json": true,
+	"application/json":      true,
+	"application/rdf+xml":   true,
+}
+
+// Candidate is a feed advertised by html page.
+type Candidate struct {
+	URL   string `json:"url"`
+	Title string `json:"title"`
+	Type  string `json:"type"`
+}
+
+// Discover returns feeds from <link rel="alternate"> tags of html page in document order,
+// relative links are resolved against base or <base href> of the page.
+func Discover(data []byte, base *url.URL) ([]Candidate, error) {
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	if href, ok := doc.Find("base[href]").First().Attr("href"); ok {
+		if u, err := url.Parse(strings.TrimSpace(href)); err == nil {
+			if base != nil {
+				u = base.ResolveReference(u)
+			}
+			base = u
+		}
+	}
+
+	var res []Candidate
+	seen := make(map[string]bool)
+	doc.Find("link[rel][href]").Each(func(_ int, s *goquery.Selection) {
+		if !isAlternate(s.AttrOr("rel", "")) {
+			return
+		}
+		typ := strings.ToLower(strings.TrimSpace(strings.SplitN(s.AttrOr("type", ""), ";", 2)[0]))
+		if !feedTypes[typ] {
+			return
+		}
+		link := resolve(base, s.AttrOr("href", ""))
+		if link == "" || seen[link] {
+			return
+		}
+		seen[link] = true
+		res = append(res, Candidate{
+			URL:   link,
+			Title: strings.TrimSpace(s.AttrOr("title", "")),
+			Type:  typ,
+		})
+	})
+	return res, nil
+}
+
+func isAlternate(rel string) bool {
+	for _, r := range strings.Fields(strings.ToLower(rel)) {
+		if r == "alternate" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,290 @@
+// Package feed parses RSS 2.0, RSS 1.0, Atom and JSON Feed documents and writes Atom and RSS 2.0 ones.
+package feed
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+var ErrFormat = errors.New("feed: unknown format")
+
+// Feed is a parsed feed, only fields used for links ingestion are kept.
+type Feed struct {
+	Title string
+	Items []Item
+}
+
+// Item is a feed entry, URL is absolute.
+type Item struct {
+	URL         string
+	PublishedAt *time.Time
+}
+
+// Parse detects format of the document, relative item links are resolved against base.
+// Items without link are skipped.
+func Parse(data []byte, base *url.URL) (*Feed, error) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 {
+		return nil, ErrFormat
+	}
+
+	var (
+		f   *Feed
+		err error
+	)
+	if trimmed[0] == '{' {
+		f, err = parseJSON(trimmed)
+	} else {
+		f, err = parseXML(trimmed)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	items := f.Items[:0]
+	for _, item := range f.Items {
+		if item.URL = resolve(base, item.URL); item.URL != "" {
+			items = append(items, item)
+		}
+	}
+	f.Items = items
+	return f, nil
+}
+
+func resolve(base *url.URL, link string) string {
+	link = strings.TrimSpace(link)
+	if link == "" {
+		return ""
+	}
+	u, err := url.Parse(link)
+	if err != nil {
+		return ""
+	}
+	if base != nil {
+		u = base.ResolveReference(u)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" || u.Host == "" {
+		return ""
+	}
+	return u.String()
+}
+
+type rssLink struct {
+	XMLName xml.Name
+	Value   string `xml:",chardata"`
+}
+
+type rssItem struct {
+	Links []rssLink `xml:"link"`
+	GUID  struct {
+		Value       string `xml:",chardata"`
+		IsPermaLink string `xml:"isPermaLink,attr"`
+	} `xml:"guid"`
+	PubDate string `xml:"pubDate"`
+	Date    string `xml:"http://purl.org/dc/elements/1.1/ date"`
+}
+
+// link returns item link ignoring atom:link elements, permalink guid is used as fallback.
+func (i rssItem) link() string {
+	for _, l := range i.Links {
+		if l.XMLName.Space == "" && strings.TrimSpace(l.Value) != "" {
+			return l.Value
+		}
+	}
+	if i.GUID.IsPermaLink != "false" {
+		return i.GUID.Value
+	}
+	return ""
+}
+
+type rssDoc struct {
+	Channel struct {
+		Title string    `xml:"title"`
+		Items []rssItem `xml:"item"`
+	} `xml:"channel"`
+	// RSS 1.0 keeps items next to the channel
+	Items []rssItem `xml:"item"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr"`
+}
+
+type atomEntry struct {
+	Links     []atomLink `xml:"link"`
+	Published string     `xml:"published"`
+	Updated   string     `xml:"updated"`
+}
+
+// link returns alternate link of the entry.
+func (e atomEntry) link() string {
+	for _, l := range e.Links {
+		if l.Rel == "" || l.Rel == "alternate" {
+			return l.Href
+		}
+	}
+	return ""
+}
+
+type atomDoc struct {
+	Title   string      `xml:"title"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+func parseXML(data []byte) (*Feed, error) {
+	root, err := rootElement(data)
+	if err != nil {
+		return nil, err
+	}
+
+	f := &Feed{}
+	switch root.Local {
+	case "rss", "RDF":
+		var doc rssDoc
+		if err = newDecoder(data).Decode(&doc); err != nil {
+			return nil, err
+		}
+		f.Title = strings.TrimSpace(doc.Channel.Title)
+		for _, item := range append(doc.Channel.Items, doc.Items...) {
+			date := item.PubDate
+			if date == "" {
+				date = item.Date
+			}
+			f.Items = append(f.Items, Item{URL: item.link(), PublishedAt: parseTime(date)})
+		}
+	case "feed":
+		var doc atomDoc
+		if err = newDecoder(data).Decode(&doc); err != nil {
+			return nil, err
+		}
+		f.Title = strings.TrimSpace(doc.Title)
+		for _, entry := range doc.Entries {
+			date := entry.Published
+			if date == "" {
+				date = entry.Updated
+			}
+			f.Items = append(f.Items, Item{URL: entry.link(), PublishedAt: parseTime(date)})
+		}
+	default:
+		return nil, ErrFormat
+	}
+	return f, nil
+}
+
+func rootElement(data []byte) (xml.Name, error) {
+	dec := newDecoder(data)
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			return xml.Name{}, ErrFormat
+		}
+		if err != nil {
+			return xml.Name{}, err
+		}
+		if el, ok := tok.(xml.StartElement); ok {
+			return el.Name, nil
+		}
+	}
+}
+
+func newDecoder(data []byte) *xml.Decoder {
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	dec.Strict = false
+	dec.CharsetReader = charsetReader
+	return dec
+}
+
+// charsetReader supports single byte latin charsets besides utf-8 which is handled by decoder.
+func charsetReader(charset string, input io.Reader) (io.Reader, error) {
+	switch strings.ToLower(charset) {
+	case "utf-8", "utf8", "us-ascii", "ascii":
+		return input, nil
+	case "iso-8859-1", "latin1", "windows-1252", "cp1252":
+		data, err := ioutil.ReadAll(input)
+		if err != nil {
+			return nil, err
+		}
+		buf := make([]byte, 0, len(data))
+		for _, b := range data {
+			buf = append(buf, string(rune(b))...)
+		}
+		return bytes.NewReader(buf), nil
+	}
+	return nil, errors.New("feed: unsupported charset " + charset)
+}
+
+type jsonDoc struct {
+	Version string `json:"version"`
+	Title   string `json:"title"`
+	Items   []struct {
+		URL           string `json:"url"`
+		ExternalURL   string `json:"external_url"`
+		DatePublished string `json:"date_published"`
+		DateModified  string `json:"date_modified"`
+	} `json:"items"`
+}
+
+func parseJSON(data []byte) (*Feed, error) {
+	var doc jsonDoc
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	if !strings.HasPrefix(doc.Version, "https://jsonfeed.org/version/") {
+		return nil, ErrFormat
+	}
+
+	f := &Feed{Title: strings.TrimSpace(doc.Title)}
+	for _, item := range doc.Items {
+		link := item.URL
+		if link == "" {
+			link = item.ExternalURL
+		}
+		date := item.DatePublished
+		if date == "" {
+			date = item.DateModified
+		}
+		f.Items = append(f.Items, Item{URL: link, PublishedAt: parseTime(date)})
+	}
+	return f, nil
+}
+
+var timeLayouts = []string{
+	time.RFC3339,
+	time.RFC1123Z,
+	time.RFC1123,
+	"Mon, 2 Jan 2006 15:04:05 -0700",
+	"Mon, 2 Jan 2006 15:04:05 MST",
+	"2 Jan 2006 15:04:05 -0700",
+	"2 Jan 2006 15:04:05 MST",
+	time.RFC822Z,
+	time.RFC822,
+	"Mon, 2 Jan 2006 15:04 -0700",
+	"Mon, 2 Jan 2006 15:04 MST",
+	"2006-01-02T15:04:05",
+	"2006-01-02",
+}
+
+// parseTime returns nil for empty or unknown date.
+func parseTime(s string) *time.Time {
+	s = strings.TrimSpace(s)
+	if s == "" || !utf8.ValidString(s) {
+		return nil
+	}
+	for _, layout := range timeLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			t = t.UTC()
+			return &t
+		}
+	}
+	return nil
+}
@@ -0,0 +1,134 @@
+package feed
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newServer() *httptest.Server {
+	mux := http.NewServeMux()
+	mux.Handle("/", http.FileServer(http.Dir("testdata")))
+	mux.HandleFunc("/page.html", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte("<!DOCTYPE html><html><head><title>Page</title></head></html>"))
+	})
+	return httptest.NewServer(mux)
+}
+
+func date(s string) *time.Time {
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		panic(err)
+	}
+	return &t
+}
+
+func TestFetchRSS(t *testing.T) {
+	srv := newServer()
+	defer srv.Close()
+	f, err := (&Client{}).Fetch(context.Background(), srv.URL+"/rss.xml")
+	require.NoError(t, err)
+
+	require.Equal(t, "News", f.Title)
+	require.Equal(t, []Item{
+		{URL: "https://news.example.com/first", PublishedAt: date("2026-10-05T07:15:00Z")},
+		{URL: srv.URL + "/second", PublishedAt: date("2026-10-06T08:00:00Z")},
+		{URL: "https://news.example.com/third"},
+	}, f.Items)
+}
+
+func TestFetchAtom(t *testing.T) {
+	srv := newServer()
+	defer srv.Close()
+	f, err := (&Client{}).Fetch(context.Background(), srv.URL+"/atom.xml")
+	require.NoError(t, err)
+
+	require.Equal(t, "Blog", f.Title)
+	require.Equal(t, []Item{
+		{URL: "https://blog.example.com/post", PublishedAt: date("2026-10-05T07:30:00Z")},
+		{URL: srv.URL + "/post-2", PublishedAt: date("2026-10-06T12:00:00Z")},
+	}, f.Items)
+}
+
+func TestFetchJSON(t *testing.T) {
+	srv := newServer()
+	defer srv.Close()
+	f, err := (&Client{}).Fetch(context.Background(), srv.URL+"/feed.json")
+	require.NoError(t, err)
+
+	require.Equal(t, "Notes", f.Title)
+	require.Equal(t, []Item{
+		{URL: "https://notes.example.com/1", PublishedAt: date("2026-10-04T08:00:00Z")},
+		{URL: "https://other.example.com/2"},
+	}, f.Items)
+}
+
+func TestFetchCharset(t *testing.T) {
+	srv := newServer()
+	defer srv.Close()
+	f, err := (&Client{}).Fetch(context.Background(), srv.URL+"/latin1.xml")
+	require.NoError(t, err)
+
+	require.Equal(t, "Café", f.Title)
+	require.Len(t, f.Items, 1)
+}
+
+func TestFetchErrors(t *testing.T) {
+	srv := newServer()
+	defer srv.Close()
+
+	_, err := (&Client{}).Fetch(context.Background(), srv.URL+"/missing.xml")
+	require.Equal(t, StatusError{Code: http.StatusNotFound}, err)
+
+	_, err = (&Client{}).Fetch(context.Background(), srv.URL+"/page.html")
+	require.Equal(t, ErrFormat, err)
+
+	_, err = (&Client{MaxSize: 100}).Fetch(context.Background(), srv.URL+"/rss.xml")
+	require.Equal(t, ErrTooLarge, err)
+}
+
+func TestProbe(t *testing.T) {
+	srv := newServer()
+	defer srv.Close()
+	client := &Client{}
+
+	p, err := client.Probe(context.Background(), srv.URL+"/rss.xml")
+	require.NoError(t, err)
+	require.Equal(t, srv.URL+"/rss.xml", p.URL)
+	require.Len(t, p.Feed.Items, 3)
+	require.Empty(t, p.Candidates)
+
+	p, err = client.Probe(context.Background(), srv.URL+"/blog.html")
+	require.NoError(t, err)
+	require.Nil(t, p.Feed)
+	require.Equal(t, []Candidate{
+		{URL: srv.URL + "/rss.xml", Title: "Posts", Type: "application/rss+xml"},
+		{URL: srv.URL + "/atom.xml", Title: "Posts (Atom)", Type: "application/atom+xml"},
+		{URL: "https://partner.example.net/feed.json", Title: "Partner", Type: "application/feed+json"},
+	}, p.Candidates)
+
+	_, err = client.Probe(context.Background(), srv.URL+"/page.html")
+	require.Equal(t, ErrFormat, err)
+}
+
+func TestPublicClient(t *testing.T) {
+	srv := newServer()
+	defer srv.Close()
+
+	_, err := (&Client{HTTP: PublicClient(time.Second)}).Probe(context.Background(), srv.URL+"/rss.xml")
+	require.Equal(t, ErrForbidden, err)
+}
+
+func TestCheckHost(t *testing.T) {
+	ctx := context.Background()
+	for _, host := range []string{"localhost", "feeds.localhost.", "127.0.0.1", "10.1.2.3", "169.254.169.254", "::1", ""} {
+		require.Equal(t, ErrForbidden, CheckHost(ctx, host), host)
+	}
+	require.NoError(t, CheckHost(ctx, "93.184.216.34"))
+	require.NoError(t, CheckHost(ctx, "2606:2800:220:1:248:1893:25c8:1946"))
+}
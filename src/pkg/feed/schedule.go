@@ -0,0 +1,77 @@
+package feed
+
+import (
+	"sort"
+	"time"
+)
+
+// gapSamples is the number of latest items used to measure publishing rate.
+const gapSamples = 20
+
+// Schedule computes when feed is polled next: busy feeds are polled more often than quiet ones,
+// failing feeds are backed off exponentially. Intervals are kept within Min and Max.
+type Schedule struct {
+	Min time.Duration
+	Max time.Duration
+	// Default is used for feeds with unknown publishing rate
+	Default time.Duration
+}
+
+// Next returns interval to the next poll of a healthy feed with average gap between items.
+func (s Schedule) Next(avgGap time.Duration) time.Duration {
+	if avgGap <= 0 {
+		return s.clamp(s.Default)
+	}
+	return s.clamp(avgGap / 2)
+}
+
+// Backoff returns interval to the next poll after consecutive failures.
+func (s Schedule) Backoff(failures int) time.Duration {
+	d := s.Min
+	for i := 0; i < failures && d < s.Max; i++ {
+		d *= 2
+	}
+	return s.clamp(d)
+}
+
+func (s Schedule) clamp(d time.Duration) time.Duration {
+	if d < s.Min {
+		return s.Min
+	}
+	if d > s.Max {
+		return s.Max
+	}
+	return d
+}
+
+// AverageGap returns average gap between publish dates of latest items, 0 if it is unknown.
+func AverageGap(items []Item) time.Duration {
+	dates := make([]time.Time, 0, len(items))
+	for _, item := range items {
+		if item.PublishedAt != nil {
+			dates = append(dates, *item.PublishedAt)
+		}
+	}
+	if len(dates) < 2 {
+		return 0
+	}
+
+	sort.Slice(dates, func(i, j int) bool {
+		return dates[i].After(dates[j])
+	})
+	if len(dates) > gapSamples {
+		dates = dates[:gapSamples]
+	}
+	return dates[0].Sub(dates[len(dates)-1]) / time.Duration(len(dates)-1)
+}
+
+// SmoothGap blends previous average gap with the measured one, unknown values are ignored.
+func SmoothGap(prev, measured time.Duration) time.Duration {
+	switch {
+	case prev <= 0:
+		return measured
+	case measured <= 0:
+		return prev
+	}
+	return (prev + measured) / 2
+}
@@ -0,0 +1,69 @@
+package feed
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchedule(t *testing.T) {
+	s := Schedule{Min: 15 * time.Minute, Max: 24 * time.Hour, Default: time.Hour}
+
+	require.Equal(t, time.Hour, s.Next(0))
+	require.Equal(t, 15*time.Minute, s.Next(10*time.Minute), "busy feed")
+	require.Equal(t, 3*time.Hour, s.Next(6*time.Hour))
+	require.Equal(t, 24*time.Hour, s.Next(7*24*time.Hour), "quiet feed")
+
+	require.Equal(t, 15*time.Minute, s.Backoff(0))
+	require.Equal(t, 30*time.Minute, s.Backoff(1))
+	require.Equal(t, 4*time.Hour, s.Backoff(4))
+	require.Equal(t, 24*time.Hour, s.Backoff(100))
+}
+
+func TestAverageGap(t *testing.T) {
+	require.Equal(t, time.Duration(0), AverageGap([]Item{{URL: "a"}, {URL: "b", PublishedAt: date("2026-10-05T00:00:00Z")}}))
+
+	items := []Item{
+		{URL: "a", PublishedAt: date("2026-10-05T06:00:00Z")},
+		{URL: "b"},
+		{URL: "c", PublishedAt: date("2026-10-05T00:00:00Z")},
+		{URL: "d", PublishedAt: date("2026-10-05T12:00:00Z")},
+	}
+	require.Equal(t, 6*time.Hour, AverageGap(items))
+
+	require.Equal(t, 4*time.Hour, SmoothGap(2*time.Hour, 6*time.Hour))
+	require.Equal(t, 6*time.Hour, SmoothGap(0, 6*time.Hour))
+	require.Equal(t, 2*time.Hour, SmoothGap(2*time.Hour, 0))
+}
+
+func TestFetchIfModified(t *testing.T) {
+	const etag = `"v1"`
+	data, err := ioutil.ReadFile("testdata/rss.xml")
+	require.NoError(t, err)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", etag)
+		w.Header().Set("Last-Modified", "Mon, 05 Oct 2026 10:00:00 GMT")
+		_, _ = w.Write(data)
+	}))
+	defer srv.Close()
+
+	client := &Client{}
+	f, v, err := client.FetchIfModified(context.Background(), srv.URL, Validators{})
+	require.NoError(t, err)
+	require.Len(t, f.Items, 3)
+	require.Equal(t, Validators{ETag: etag, LastModified: "Mon, 05 Oct 2026 10:00:00 GMT"}, v)
+
+	f, next, err := client.FetchIfModified(context.Background(), srv.URL, v)
+	require.Equal(t, ErrNotModified, err)
+	require.Nil(t, f)
+	require.Equal(t, v, next)
+}
@@ -0,0 +1,189 @@
+package feed
+
+import (
+	"encoding/xml"
+	"io"
+	"time"
+)
+
+// Channel is an outbound feed, SelfURL is the url the feed is served by.
+type Channel struct {
+	ID      string
+	Title   string
+	Link    string
+	SelfURL string
+	Updated time.Time
+	Entries []Entry
+}
+
+// Entry is an item of outbound feed, zero Published falls back to Updated.
+type Entry struct {
+	ID         string
+	Title      string
+	URL        string
+	Summary    string
+	Published  time.Time
+	Updated    time.Time
+	Categories []Category
+}
+
+// Category is a term of the entry, Scheme names the vocabulary, e.g. domain or sentiment.
+type Category struct {
+	Scheme string
+	Term   string
+}
+
+const (
+	atomNS = "http://www.w3.org/2005/Atom"
+	// ContentTypeAtom and ContentTypeRSS are media types of written documents.
+	ContentTypeAtom = "application/atom+xml; charset=utf-8"
+	ContentTypeRSS  = "application/rss+xml; charset=utf-8"
+)
+
+type atomOutLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+type atomOutCategory struct {
+	Term   string `xml:"term,attr"`
+	Scheme string `xml:"scheme,attr,omitempty"`
+}
+
+type atomOutEntry struct {
+	ID         string            `xml:"id"`
+	Title      string            `xml:"title"`
+	Links      []atomOutLink     `xml:"link"`
+	Published  string            `xml:"published"`
+	Updated    string            `xml:"updated"`
+	Summary    string            `xml:"summary,omitempty"`
+	Categories []atomOutCategory `xml:"category"`
+}
+
+type atomOutDoc struct {
+	XMLName xml.Name       `xml:"feed"`
+	NS      string         `xml:"xmlns,attr"`
+	ID      string         `xml:"id"`
+	Title   string         `xml:"title"`
+	Updated string         `xml:"updated"`
+	Links   []atomOutLink  `xml:"link"`
+	Entries []atomOutEntry `xml:"entry"`
+}
+
+type rssOutCategory struct {
+	Domain string `xml:"domain,attr,omitempty"`
+	Value  string `xml:",chardata"`
+}
+
+type rssOutItem struct {
+	Title       string           `xml:"title"`
+	Link        string           `xml:"link"`
+	GUID        rssOutGUID       `xml:"guid"`
+	PubDate     string           `xml:"pubDate"`
+	Description string           `xml:"description,omitempty"`
+	Categories  []rssOutCategory `xml:"category"`
+}
+
+type rssOutGUID struct {
+	IsPermaLink string `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+type rssOutDoc struct {
+	XMLName xml.Name `xml:"rss"`
+	Version string   `xml:"version,attr"`
+	AtomNS  string   `xml:"xmlns:atom,attr"`
+	Channel struct {
+		Title         string       `xml:"title"`
+		Link          string       `xml:"link"`
+		Description   string       `xml:"description"`
+		LastBuildDate string       `xml:"lastBuildDate"`
+		Self          *atomOutLink `xml:"atom:link,omitempty"`
+		Items         []rssOutItem `xml:"item"`
+	} `xml:"channel"`
+}
+
+func (e Entry) published() time.Time {
+	if e.Published.IsZero() {
+		return e.Updated
+	}
+	return e.Published
+}
+
+func (e Entry) updated() time.Time {
+	if e.Updated.IsZero() {
+		return e.Published
+	}
+	return e.Updated
+}
+
+// WriteAtom writes the channel as Atom 1.0 document.
+func WriteAtom(w io.Writer, ch Channel) error {
+	doc := atomOutDoc{
+		NS:      atomNS,
+		ID:      ch.ID,
+		Title:   ch.Title,
+		Updated: ch.Updated.UTC().Format(time.RFC3339),
+	}
+	if ch.Link != "" {
+		doc.Links = append(doc.Links, atomOutLink{Href: ch.Link, Rel: "alternate"})
+	}
+	if ch.SelfURL != "" {
+		doc.Links = append(doc.Links, atomOutLink{Href: ch.SelfURL, Rel: "self", Type: "application/atom+xml"})
+	}
+	for _, e := range ch.Entries {
+		entry := atomOutEntry{
+			ID:        e.ID,
+			Title:     e.Title,
+			Links:     []atomOutLink{{Href: e.URL, Rel: "alternate"}},
+			Published: e.published().UTC().Format(time.RFC3339),
+			Updated:   e.updated().UTC().Format(time.RFC3339),
+			Summary:   e.Summary,
+		}
+		for _, c := range e.Categories {
+			entry.Categories = append(entry.Categories, atomOutCategory{Term: c.Term, Scheme: c.Scheme})
+		}
+		doc.Entries = append(doc.Entries, entry)
+	}
+	return writeXML(w, doc)
+}
+
+// WriteRSS writes the channel as RSS 2.0 document, the self link is written as atom:link.
+func WriteRSS(w io.Writer, ch Channel) error {
+	doc := rssOutDoc{Version: "2.0", AtomNS: atomNS}
+	doc.Channel.Title = ch.Title
+	doc.Channel.Link = ch.Link
+	doc.Channel.Description = ch.Title
+	doc.Channel.LastBuildDate = ch.Updated.UTC().Format(time.RFC1123Z)
+	if ch.SelfURL != "" {
+		doc.Channel.Self = &atomOutLink{Href: ch.SelfURL, Rel: "self", Type: "application/rss+xml"}
+	}
+	for _, e := range ch.Entries {
+		item := rssOutItem{
+			Title:       e.Title,
+			Link:        e.URL,
+			GUID:        rssOutGUID{IsPermaLink: "false", Value: e.ID},
+			PubDate:     e.published().UTC().Format(time.RFC1123Z),
+			Description: e.Summary,
+		}
+		for _, c := range e.Categories {
+			item.Categories = append(item.Categories, rssOutCategory{Domain: c.Scheme, Value: c.Term})
+		}
+		doc.Channel.Items = append(doc.Channel.Items, item)
+	}
+	return writeXML(w, doc)
+}
+
+func writeXML(w io.Writer, doc interface{}) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
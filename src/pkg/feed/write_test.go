@@ -0,0 +1,65 @@
+package feed
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func testChannel() Channel {
+	updated := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	return Channel{
+		ID:      "https://api.example.com/api/link/feed.atom?query=go",
+		Title:   "Search: go",
+		Link:    "https://app.example.com/",
+		SelfURL: "https://api.example.com/api/link/feed.atom?query=go&token=t",
+		Updated: updated,
+		Entries: []Entry{
+			{
+				ID:        "link:1",
+				Title:     "Go & friends",
+				URL:       "https://example.com/go?a=1&b=2",
+				Summary:   "Summary <b>text</b>",
+				Published: updated.Add(-time.Hour),
+				Updated:   updated,
+				Categories: []Category{
+					{Scheme: "domain", Term: "example.com"},
+					{Scheme: "sentiment", Term: "positive"},
+				},
+			},
+			{ID: "link:2", Title: "Undated", URL: "https://example.org/", Updated: updated},
+		},
+	}
+}
+
+func TestWriteAtom(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, WriteAtom(&buf, testChannel()))
+	require.Contains(t, buf.String(), `<category term="example.com" scheme="domain"></category>`)
+	require.Contains(t, buf.String(), `rel="self"`)
+
+	f, err := Parse(buf.Bytes(), nil)
+	require.NoError(t, err)
+	require.Equal(t, "Search: go", f.Title)
+	require.Equal(t, []Item{
+		{URL: "https://example.com/go?a=1&b=2", PublishedAt: date("2026-10-18T11:00:00Z")},
+		{URL: "https://example.org/", PublishedAt: date("2026-10-18T12:00:00Z")},
+	}, f.Items)
+}
+
+func TestWriteRSS(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, WriteRSS(&buf, testChannel()))
+	require.Contains(t, buf.String(), `<category domain="sentiment">positive</category>`)
+	require.Contains(t, buf.String(), `<guid isPermaLink="false">link:1</guid>`)
+
+	f, err := Parse(buf.Bytes(), nil)
+	require.NoError(t, err)
+	require.Equal(t, "Search: go", f.Title)
+	require.Equal(t, []Item{
+		{URL: "https://example.com/go?a=1&b=2", PublishedAt: date("2026-10-18T11:00:00Z")},
+		{URL: "https://example.org/", PublishedAt: date("2026-10-18T12:00:00Z")},
+	}, f.Items)
+}
@@ -2,12 +2,20 @@ package controller
 
 import "github.com/gin-gonic/gin"
 
+const actKey = "controller_act"
+
 type HandlerList []gin.HandlerFunc
 
 type Act struct {
 	Method   string
 	Route    string
 	Handlers HandlerList
+	// HumanOnly rejects requests authenticated with application key
+	HumanOnly bool
+	// Scope is required from application key to perform the action, acts without scope are denied to the keys
+	Scope string
+	// Permission is required from account to perform the action, it implies authentication
+	Permission string
 }
 
 type Ctrl struct {
@@ -15,3 +23,19 @@ type Ctrl struct {
 	Handlers HandlerList
 	Acts     []Act
 }
+
+// Bind returns handler which makes the act available for the next handlers of the chain.
+func (a Act) Bind() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(actKey, a)
+	}
+}
+
+func GetAct(c *gin.Context) (Act, bool) {
+	val, ok := c.Get(actKey)
+	if !ok {
+		return Act{}, false
+	}
+	act, ok := val.(Act)
+	return act, ok
+}
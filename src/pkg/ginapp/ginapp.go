@@ -11,6 +11,8 @@ import (
 	"oko/pkg/e"
 	"oko/pkg/env"
 	"oko/pkg/ginapp/controller"
+	"oko/pkg/mail"
+	"oko/pkg/password"
 	"oko/pkg/valid"
 	"os"
 	"os/signal"
@@ -27,21 +29,28 @@ import (
 )
 
 type App struct {
-	Engine          *gin.Engine
-	cachePool       *redis.Pool
-	CacheStore      *persistence.RedisStore
-	Srv             *http.Server
-	RootRote        string
-	RootHandlers    controller.HandlerList
-	Head            *gin.RouterGroup
-	Ctrls           []controller.Ctrl
-	Validators      []valid.Item
-	ValidatorEngine *validator.Validate
-	ValidatorMsgs   map[string]string
+	Engine            *gin.Engine
+	cachePool         *redis.Pool
+	CacheStore        *persistence.RedisStore
+	Srv               *http.Server
+	RootRote          string
+	RootHandlers      controller.HandlerList
+	Head              *gin.RouterGroup
+	Ctrls             []controller.Ctrl
+	Validators        []valid.Item
+	ValidatorMsgFuncs map[string]func() string
+	ValidatorEngine   *validator.Validate
+	ValidatorMsgs     map[string]string
 }
 
 func (a *App) Init() {
 	cfg.Load()
+	if err := password.Init(); err != nil {
+		log.Fatal(err)
+	}
+	if err := mail.Init(); err != nil {
+		log.Fatal(err)
+	}
 	a.initCache()
 	a.initEngine()
 	a.initValidator()
@@ -107,9 +116,11 @@ func (a *App) initEngine() {
 }
 
 func (a *App) addCors() {
+	allowHeaders := []string{"Origin", "Content-Length", "Content-Type",
+		cfg.App.AuthTokenKey, cfg.App.APIKeyKey, cfg.App.OrgKey}
 	a.Engine.Use(cors.New(cors.Config{
 		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "HEAD"},
-		AllowHeaders:     []string{"Origin", "Content-Length", "Content-Type", cfg.App.AuthTokenKey},
+		AllowHeaders:     allowHeaders,
 		ExposeHeaders:    []string{"Content-Length"},
 		AllowCredentials: true,
 		AllowOriginFunc: func(origin string) bool {
@@ -121,19 +132,24 @@ func (a *App) addCors() {
 
 func (a *App) addCtrl(c controller.Ctrl) {
 	if len(c.Acts) > 0 {
-		r := a.Head.Group("/"+c.Name+"/", c.Handlers...)
+		r := a.Head.Group("/" + c.Name + "/")
 		for _, a := range c.Acts {
+			// act is bound before controller handlers, so auth could check act requirements
+			handlers := make(controller.HandlerList, 0, 1+len(c.Handlers)+len(a.Handlers))
+			handlers = append(handlers, a.Bind())
+			handlers = append(handlers, c.Handlers...)
+			handlers = append(handlers, a.Handlers...)
 			switch a.Method {
 			case "GET":
-				r.GET(a.Route, a.Handlers...)
+				r.GET(a.Route, handlers...)
 			case "POST":
-				r.POST(a.Route, a.Handlers...)
+				r.POST(a.Route, handlers...)
 			case "DELETE":
-				r.DELETE(a.Route, a.Handlers...)
+				r.DELETE(a.Route, handlers...)
 			case "PUT":
-				r.PUT(a.Route, a.Handlers...)
+				r.PUT(a.Route, handlers...)
 			case "HEAD":
-				r.HEAD(a.Route, a.Handlers...)
+				r.HEAD(a.Route, handlers...)
 			default:
 				log.Panicln("Unsupported method " + a.Method)
 			}
@@ -176,6 +192,9 @@ func (a *App) initValidator() {
 			e.ValidatorMessages[item.Key] = item.Message
 		}
 	}
+	for key, msg := range a.ValidatorMsgFuncs {
+		e.ValidatorMessages[key] = msg()
+	}
 }
 
 func pingPage(c *gin.Context) {
@@ -5,6 +5,31 @@ type PaginationRequest struct {
 	PerPage     uint32 `json:"per_page" form:"per_page,default=15" binding:"omitempty"`
 }
 
+const (
+	DefPerPage = 15
+	MaxPerPage = 100
+	// MaxServicePerPage limits pages of items loaded from proxy service one by one
+	MaxServicePerPage = 25
+)
+
+// Bound sets default page size when it's not given and limits it with MaxPerPage.
+func (p *PaginationRequest) Bound() {
+	p.BoundTo(MaxPerPage)
+}
+
+// BoundTo sets default page size when it's not given and limits it with max.
+func (p *PaginationRequest) BoundTo(max uint32) {
+	if p.CurrentPage == 0 {
+		p.CurrentPage = 1
+	}
+	if p.PerPage == 0 {
+		p.PerPage = DefPerPage
+	}
+	if p.PerPage > max {
+		p.PerPage = max
+	}
+}
+
 type PaginationResponse struct {
 	PaginationRequest
 	TotalPages   uint32 `json:"total_pages" binding:"omitempty"`
@@ -1,6 +1,7 @@
 package types
 
 import (
+	"encoding/json"
 	"net/http"
 	"oko/pkg/e"
 	"time"
@@ -24,7 +25,9 @@ type ResponseError struct {
 }
 
 type ResponseAccessToken struct {
-	AccessToken string `json:"access_token"`
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	ExpiresIn    int    `json:"expires_in,omitempty"`
 }
 
 type ResponseSignIn struct {
@@ -32,6 +35,26 @@ type ResponseSignIn struct {
 	Data ResponseAccessToken `json:"data"`
 }
 
+type SignInChallenge struct {
+	ChallengeToken string `json:"challenge_token"`
+	ExpiresIn      int    `json:"expires_in"`
+}
+
+type ResponseSignInChallenge struct {
+	StdResponse
+	Data SignInChallenge `json:"data"`
+}
+
+type TwoFactorEnroll struct {
+	Secret string `json:"secret"`
+	URI    string `json:"uri"`
+}
+
+type ResponseTwoFactorEnroll struct {
+	StdResponse
+	Data TwoFactorEnroll `json:"data"`
+}
+
 type Profile struct {
 	ID        int       `json:"id"`
 	Email     string    `json:"email"`
@@ -40,6 +63,8 @@ type Profile struct {
 	StatusStr string    `json:"status_str"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
+
+	TwoFactorEnabled bool `json:"two_factor_enabled"`
 }
 
 type ResponseProfile struct {
@@ -47,9 +72,62 @@ type ResponseProfile struct {
 	Data Profile `json:"data"`
 }
 
+type Session struct {
+	ID         string    `json:"id"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	IP         string    `json:"ip"`
+	UserAgent  string    `json:"user_agent"`
+	Current    bool      `json:"current"`
+}
+
+type ResponseSessions struct {
+	StdResponse
+	Data []Session `json:"data"`
+}
+
+type APIKey struct {
+	ID         int        `json:"id"`
+	AccountID  int        `json:"account_id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	ExpireAt   *time.Time `json:"expire_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+type APIKeyCreated struct {
+	APIKey
+	Key string `json:"key"`
+}
+
+type ResponseAPIKeys struct {
+	StdResponse
+	Data []APIKey `json:"data"`
+}
+
+type ResponseAPIKeyCreated struct {
+	StdResponse
+	Data APIKeyCreated `json:"data"`
+}
+
+type FeedToken struct {
+	Token   string `json:"token"`
+	AtomURL string `json:"atom_url"`
+	RssURL  string `json:"rss_url"`
+}
+
+type ResponseFeedToken struct {
+	StdResponse
+	Data FeedToken `json:"data"`
+}
+
 type RoleItem struct {
-	Role    int    `json:"role"`
-	StrRole string `json:"str_role"`
+	Role        int      `json:"role"`
+	StrRole     string   `json:"str_role"`
+	Permissions []string `json:"permissions,omitempty"`
+	Includes    []string `json:"includes,omitempty"`
 }
 
 type RolesList struct {
@@ -57,6 +135,145 @@ type RolesList struct {
 	Data []RoleItem `json:"data"`
 }
 
+type AdminAccount struct {
+	Profile
+	Roles []RoleItem `json:"roles"`
+}
+
+type ResponseAdminAccount struct {
+	StdResponse
+	Data AdminAccount `json:"data"`
+}
+
+type ResponseAdminAccounts struct {
+	StdResponse
+	Data []AdminAccount     `json:"data"`
+	Meta PaginationResponse `json:"meta"`
+}
+
+type Organization struct {
+	ID   uint   `json:"id"`
+	Name string `json:"name"`
+	Role string `json:"role"`
+}
+
+type ResponseOrganization struct {
+	StdResponse
+	Data Organization `json:"data"`
+}
+
+type ResponseOrganizations struct {
+	StdResponse
+	Data []Organization `json:"data"`
+}
+
+type OrgMember struct {
+	AccountID int       `json:"account_id"`
+	Email     string    `json:"email"`
+	Name      string    `json:"name"`
+	Role      string    `json:"role"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type ResponseOrgMembers struct {
+	StdResponse
+	Data []OrgMember `json:"data"`
+}
+
+type Invite struct {
+	ID             int        `json:"id"`
+	Email          string     `json:"email"`
+	Roles          []RoleItem `json:"roles"`
+	OrganizationID *uint      `json:"organization_id,omitempty"`
+	OrgRole        string     `json:"org_role,omitempty"`
+	ExpireAt       time.Time  `json:"expire_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+type ResponseInvite struct {
+	StdResponse
+	Data Invite `json:"data"`
+}
+
+type OIDCLogin struct {
+	URL string `json:"url"`
+}
+
+type ResponseOIDCLogin struct {
+	StdResponse
+	Data OIDCLogin `json:"data"`
+}
+
+type Identity struct {
+	ID        int       `json:"id"`
+	Issuer    string    `json:"issuer"`
+	Subject   string    `json:"subject"`
+	Email     string    `json:"email"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type ResponseIdentities struct {
+	StdResponse
+	Data []Identity `json:"data"`
+}
+
+type AuditEntry struct {
+	ID             int64           `json:"id"`
+	ActorID        *int            `json:"actor_id"`
+	OrganizationID *uint           `json:"organization_id"`
+	Action         string          `json:"action"`
+	TargetType     string          `json:"target_type"`
+	TargetID       string          `json:"target_id"`
+	IP             string          `json:"ip"`
+	UserAgent      string          `json:"user_agent"`
+	Details        json.RawMessage `json:"details" swaggertype:"object"`
+	CreatedAt      time.Time       `json:"created_at"`
+}
+
+type ResponseAuditLog struct {
+	StdResponse
+	Data []AuditEntry       `json:"data"`
+	Meta PaginationResponse `json:"meta"`
+}
+
+type FeedCandidate struct {
+	URL   string `json:"url"`
+	Title string `json:"title"`
+	Type  string `json:"type"`
+}
+
+type RssSaved struct {
+	ID       uint   `json:"id"`
+	DomainID uint   `json:"domain_id"`
+	Link     string `json:"link"`
+	// Candidates are feeds discovered on html page given as link
+	Candidates []FeedCandidate `json:"candidates"`
+}
+
+type ResponseRssSaved struct {
+	StdResponse
+	Data RssSaved `json:"data"`
+}
+
+type RssImportItem struct {
+	URL    string `json:"url"`
+	ID     uint   `json:"id,omitempty"`
+	Domain string `json:"domain,omitempty"`
+	Reason string `json:"reason,omitempty"`
+}
+
+type RssImport struct {
+	CreatedDomains []string        `json:"created_domains"`
+	Created        []RssImportItem `json:"created"`
+	Skipped        []RssImportItem `json:"skipped"`
+	Rejected       []RssImportItem `json:"rejected"`
+}
+
+type ResponseRssImport struct {
+	StdResponse
+	Data RssImport `json:"data"`
+}
+
 type StringArray struct {
 	StdResponse
 	Data []string `json:"data"`
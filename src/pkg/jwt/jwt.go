@@ -0,0 +1,148 @@
+// Package jwt signs and verifies HS256 JSON web tokens, the signing keys are identified by kid,
+// so the keys can be rotated without invalidating issued tokens.
+package jwt
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+)
+
+const algHS256 = "HS256"
+
+var (
+	ErrMalformed  = errors.New("jwt: malformed token")
+	ErrUnknownKey = errors.New("jwt: unknown signing key")
+	ErrSignature  = errors.New("jwt: invalid signature")
+	ErrExpired    = errors.New("jwt: token is expired")
+	ErrNoKeys     = errors.New("jwt: no signing keys")
+)
+
+// Clock is a source of current time, tests replace it with fixed one.
+type Clock interface {
+	Now() time.Time
+}
+
+type SystemClock struct{}
+
+func (SystemClock) Now() time.Time {
+	return time.Now()
+}
+
+type Key struct {
+	ID     string
+	Secret []byte
+}
+
+// StandardClaims are registered claims, they are embedded into claims of the application.
+type StandardClaims struct {
+	Subject   string `json:"sub,omitempty"`
+	IssuedAt  int64  `json:"iat,omitempty"`
+	ExpiresAt int64  `json:"exp,omitempty"`
+}
+
+func (sc StandardClaims) standard() StandardClaims {
+	return sc
+}
+
+// Claims is implemented by any struct embedding StandardClaims.
+type Claims interface {
+	standard() StandardClaims
+}
+
+type header struct {
+	Alg string `json:"alg"`
+	Typ string `json:"typ"`
+	Kid string `json:"kid,omitempty"`
+}
+
+// Signer signs tokens with the first key, tokens signed with any of the keys are accepted.
+type Signer struct {
+	Keys  []Key
+	Clock Clock
+}
+
+func NewSigner(keys []Key, clock Clock) *Signer {
+	return &Signer{Keys: keys, Clock: clock}
+}
+
+func (s *Signer) Sign(claims Claims) (string, error) {
+	if len(s.Keys) == 0 {
+		return "", ErrNoKeys
+	}
+	key := s.Keys[0]
+
+	head, err := json.Marshal(header{Alg: algHS256, Typ: "JWT", Kid: key.ID})
+	if err != nil {
+		return "", err
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	unsigned := encode(head) + "." + encode(payload)
+	return unsigned + "." + encode(sign(key.Secret, unsigned)), nil
+}
+
+// Parse verifies the token and decodes its payload into claims.
+func (s *Signer) Parse(token string, claims Claims) error {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return ErrMalformed
+	}
+
+	var head header
+	if err := decodeJSON(parts[0], &head); err != nil || head.Alg != algHS256 {
+		return ErrMalformed
+	}
+	key, ok := s.key(head.Kid)
+	if !ok {
+		return ErrUnknownKey
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return ErrMalformed
+	}
+	if !hmac.Equal(sig, sign(key.Secret, parts[0]+"."+parts[1])) {
+		return ErrSignature
+	}
+
+	if err = decodeJSON(parts[1], claims); err != nil {
+		return ErrMalformed
+	}
+	if exp := claims.standard().ExpiresAt; exp != 0 && s.Clock.Now().Unix() >= exp {
+		return ErrExpired
+	}
+	return nil
+}
+
+func (s *Signer) key(id string) (Key, bool) {
+	for _, k := range s.Keys {
+		if k.ID == id {
+			return k, true
+		}
+	}
+	return Key{}, false
+}
+
+func sign(secret []byte, data string) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(data)) //nolint
+	return mac.Sum(nil)
+}
+
+func encode(data []byte) string {
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeJSON(part string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(part)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
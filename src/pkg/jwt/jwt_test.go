@@ -0,0 +1,69 @@
+package jwt
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+type testClaims struct {
+	StandardClaims
+	Roles []int `json:"roles"`
+}
+
+func TestSignParse(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1600000000, 0)}
+	s := NewSigner([]Key{{ID: "k1", Secret: []byte("secret")}}, clock)
+
+	token, err := s.Sign(testClaims{
+		StandardClaims: StandardClaims{Subject: "42", ExpiresAt: clock.now.Add(time.Minute).Unix()},
+		Roles:          []int{1, 2},
+	})
+	require.NoError(t, err)
+
+	var claims testClaims
+	require.NoError(t, s.Parse(token, &claims))
+	require.Equal(t, "42", claims.Subject)
+	require.Equal(t, []int{1, 2}, claims.Roles)
+
+	clock.now = clock.now.Add(time.Minute)
+	require.Equal(t, ErrExpired, s.Parse(token, &claims))
+}
+
+func TestKeyRotation(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1600000000, 0)}
+	old := NewSigner([]Key{{ID: "k1", Secret: []byte("old")}}, clock)
+	token, err := old.Sign(testClaims{})
+	require.NoError(t, err)
+
+	rotated := NewSigner([]Key{{ID: "k2", Secret: []byte("new")}, {ID: "k1", Secret: []byte("old")}}, clock)
+	require.NoError(t, rotated.Parse(token, &testClaims{}))
+
+	retired := NewSigner([]Key{{ID: "k2", Secret: []byte("new")}}, clock)
+	require.Equal(t, ErrUnknownKey, retired.Parse(token, &testClaims{}))
+}
+
+func TestTampered(t *testing.T) {
+	s := NewSigner([]Key{{ID: "k1", Secret: []byte("secret")}}, SystemClock{})
+	token, err := s.Sign(testClaims{StandardClaims: StandardClaims{Subject: "1"}})
+	require.NoError(t, err)
+
+	parts := strings.Split(token, ".")
+	forged, err := s.Sign(testClaims{StandardClaims: StandardClaims{Subject: "2"}})
+	require.NoError(t, err)
+	parts[1] = strings.Split(forged, ".")[1]
+	parts[2] = strings.Split(token, ".")[2]
+	require.Equal(t, ErrSignature, s.Parse(strings.Join(parts, "."), &testClaims{}))
+
+	require.Equal(t, ErrMalformed, s.Parse("abc", &testClaims{}))
+}
@@ -40,6 +40,7 @@ func NewHandler(repo Repository) Handler {
 // @Failure 400 {object} types.ResponseErrorSwg
 // @Failure 500 {object} types.ResponseErrorSwg
 // @Router /link [get]
+// @Security ApiKeyAuth
 func (h *linkHandler) List(c *gin.Context) {
 	var form ListRequest
 
@@ -48,26 +49,7 @@ func (h *linkHandler) List(c *gin.Context) {
 		return
 	}
 
-	reg := etcd.NewRegistry(
-		registry.Addrs(env.GetEnvOrPanic("ETCD_ADDRESS")),
-	)
-	service := micro.NewService(
-		micro.Registry(reg),
-	)
-	service.Init()
-
-	cl := service.Client()
-	_ = cl.Init(
-		client.RequestTimeout(time.Second * 30))
-
-	ts := contentPB.NewContentService("go.micro.srv.content", cl)
-
-	result, err := ts.List(context.Background(), &contentPB.ContentListRequest{
-		Query:    form.Query,
-		Page:     form.CurrentPage,
-		Limit:    form.PerPage,
-		DomainId: form.DomainID,
-	})
+	result, err := search(form.Query, form.DomainID, form.CurrentPage, form.PerPage)
 
 	if err != nil || result == nil {
 		e.ErrorResponse(c, http.StatusBadRequest, "Something went wrong")
@@ -110,3 +92,27 @@ func (h *linkHandler) List(c *gin.Context) {
 			},
 		})
 }
+
+// search queries content service, pages start from 1.
+func search(query, domainID string, page, limit uint32) (*contentPB.ContentListResponse, error) {
+	reg := etcd.NewRegistry(
+		registry.Addrs(env.GetEnvOrPanic("ETCD_ADDRESS")),
+	)
+	service := micro.NewService(
+		micro.Registry(reg),
+	)
+	service.Init()
+
+	cl := service.Client()
+	_ = cl.Init(
+		client.RequestTimeout(time.Second * 30))
+
+	ts := contentPB.NewContentService("go.micro.srv.content", cl)
+
+	return ts.List(context.Background(), &contentPB.ContentListRequest{
+		Query:    query,
+		Page:     page,
+		Limit:    limit,
+		DomainId: domainID,
+	})
+}
@@ -1,6 +1,7 @@
 package links
 
 import (
+	"oko/pkg/account"
 	"oko/pkg/db"
 	"oko/pkg/ginapp/controller"
 
@@ -9,6 +10,8 @@ import (
 
 type Handler interface {
 	List(c *gin.Context)
+	FeedAtom(c *gin.Context)
+	FeedRSS(c *gin.Context)
 }
 
 func NewController() controller.Ctrl {
@@ -19,7 +22,10 @@ func NewController() controller.Ctrl {
 		Name:     "link",
 		Handlers: nil,
 		Acts: []controller.Act{
-			{Method: "GET", Route: "/", Handlers: []gin.HandlerFunc{handler.List}},
+			{Method: "GET", Route: "/", Handlers: []gin.HandlerFunc{account.Auth(true, []int{}), handler.List},
+				Scope: account.ScopeLinkRead},
+			{Method: "GET", Route: "/feed.atom", Handlers: []gin.HandlerFunc{account.FeedAuth(), handler.FeedAtom}},
+			{Method: "GET", Route: "/feed.rss", Handlers: []gin.HandlerFunc{account.FeedAuth(), handler.FeedRSS}},
 		},
 	}
 }
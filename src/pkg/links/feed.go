@@ -0,0 +1,260 @@
+package links
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/url"
+	"oko/pkg/account"
+	"oko/pkg/cfg"
+	"oko/pkg/e"
+	"oko/pkg/feed"
+	"oko/pkg/log"
+	"oko/pkg/org"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	titleLength   = 120
+	summaryLength = 500
+	// content ids are link ids with 8 chars suffix
+	contentIDSuffix = 8
+)
+
+// Sentiment categories of feed entries.
+const (
+	sentimentPositive = "positive"
+	sentimentNegative = "negative"
+	sentimentNeutral  = "neutral"
+)
+
+// linkID returns id of the link of content service document.
+func linkID(contentID string) (uint, bool) {
+	if len(contentID) <= contentIDSuffix {
+		return 0, false
+	}
+	id, err := strconv.Atoi(contentID[:len(contentID)-contentIDSuffix])
+	if err != nil || id <= 0 {
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// snippet returns first line of the text cut to max runes.
+func snippet(text string, max int, firstLine bool) string {
+	text = strings.TrimSpace(text)
+	if firstLine {
+		if i := strings.IndexByte(text, '\n'); i >= 0 {
+			text = strings.TrimSpace(text[:i])
+		}
+	}
+	if utf8.RuneCountInString(text) <= max {
+		return text
+	}
+	runes := []rune(text)
+	return strings.TrimSpace(string(runes[:max-1])) + "…"
+}
+
+func sentiment(score *float32) string {
+	switch {
+	case score == nil || *score == 0:
+		return sentimentNeutral
+	case *score > 0:
+		return sentimentPositive
+	default:
+		return sentimentNegative
+	}
+}
+
+// toEntry returns feed entry of the link, content of the link gives title and summary.
+func toEntry(link *Link, content string) feed.Entry {
+	entry := feed.Entry{
+		ID:      "oko:link:" + strconv.Itoa(int(link.ID)),
+		Title:   snippet(content, titleLength, true),
+		URL:     link.URL,
+		Summary: snippet(content, summaryLength, false),
+		Updated: link.UpdatedAt,
+	}
+	if entry.Title == "" {
+		entry.Title = link.URL
+	}
+	if link.PublishedAt != nil {
+		entry.Published = *link.PublishedAt
+	} else if link.CreatedAt != nil {
+		entry.Published = *link.CreatedAt
+	}
+	if link.Domain.Name != "" {
+		entry.Categories = append(entry.Categories, feed.Category{Scheme: "domain", Term: link.Domain.Name})
+	}
+	entry.Categories = append(entry.Categories, feed.Category{Scheme: "sentiment", Term: sentiment(link.SentimentalScore)})
+	if link.SentimentalScore != nil {
+		entry.Categories = append(entry.Categories, feed.Category{
+			Scheme: "sentiment-score",
+			Term:   strconv.FormatFloat(float64(*link.SentimentalScore), 'f', 2, 32),
+		})
+	}
+	return entry
+}
+
+// feedURL returns public url of the feed request, the token is dropped for the feed id.
+func feedURL(c *gin.Context, withToken bool) string {
+	query := c.Request.URL.Query()
+	if !withToken {
+		query.Del(account.FeedTokenParam)
+	}
+	u := url.URL{
+		Scheme:   cfg.App.APISchema,
+		Host:     cfg.App.APIHost,
+		Path:     c.Request.URL.Path,
+		RawQuery: query.Encode(),
+	}
+	return u.String()
+}
+
+// organizationIDs returns organizations of the account, feeds show links of their domains only.
+func organizationIDs(accID int) ([]uint, error) {
+	memberships, err := org.ListMemberships(accID)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]uint, 0, len(memberships))
+	for _, m := range memberships {
+		ids = append(ids, m.OrganizationID)
+	}
+	return ids, nil
+}
+
+func (h *linkHandler) channel(c *gin.Context) (feed.Channel, bool) {
+	var form FeedRequest
+
+	if err := c.ShouldBind(&form); err != nil {
+		e.ErrorResponse(c, http.StatusBadRequest, err)
+		return feed.Channel{}, false
+	}
+
+	orgIDs, err := organizationIDs(account.GetContextAccID(c))
+	if err != nil {
+		log.Println("Error in linkHandler.Feed", err)
+		e.ErrorResponse(c, http.StatusInternalServerError, "Something went wrong")
+		return feed.Channel{}, false
+	}
+	if form.DomainID != "" {
+		domainID, err := strconv.ParseUint(form.DomainID, 10, 32)
+		if err != nil {
+			e.ErrorResponse(c, http.StatusBadRequest, "Invalid domain id")
+			return feed.Channel{}, false
+		}
+		exists, err := h.repository.DomainExists(orgIDs, uint(domainID))
+		if err != nil {
+			e.ErrorResponse(c, http.StatusInternalServerError, "Something went wrong")
+			return feed.Channel{}, false
+		}
+		if !exists {
+			e.ErrorResponse(c, http.StatusNotFound, "Domain not found")
+			return feed.Channel{}, false
+		}
+	}
+
+	result, err := search(form.Query, form.DomainID, 1, form.Limit)
+	if err != nil || result == nil {
+		log.Println("Error in linkHandler.Feed", err)
+		e.ErrorResponse(c, http.StatusBadRequest, "Something went wrong")
+		return feed.Channel{}, false
+	}
+
+	ids := make([]uint, 0, len(result.Data))
+	contents := make(map[uint]string, len(result.Data))
+	for _, model := range result.Data {
+		if id, ok := linkID(model.Id); ok {
+			ids = append(ids, id)
+			contents[id] = model.Data
+		}
+	}
+	// search isn't limited to the organizations, links of other organizations are dropped
+	links, err := h.repository.ListOfOrganizations(orgIDs, ids)
+	if err != nil {
+		e.ErrorResponse(c, http.StatusInternalServerError, "Something went wrong")
+		return feed.Channel{}, false
+	}
+	byID := make(map[uint]*Link, len(links))
+	for _, link := range links {
+		byID[link.ID] = link
+	}
+
+	ch := feed.Channel{
+		ID:      feedURL(c, false),
+		Title:   "OKO search: " + form.Query,
+		Link:    cfg.App.FrontURL,
+		SelfURL: feedURL(c, true),
+	}
+	for _, id := range ids {
+		link, ok := byID[id]
+		if !ok || link.URL == "" {
+			continue
+		}
+		entry := toEntry(link, contents[id])
+		if entry.Updated.After(ch.Updated) {
+			ch.Updated = entry.Updated
+		}
+		ch.Entries = append(ch.Entries, entry)
+	}
+	if ch.Updated.IsZero() {
+		ch.Updated = time.Now()
+	}
+	return ch, true
+}
+
+func writeFeed(c *gin.Context, contentType string, ch feed.Channel, write func(w io.Writer, ch feed.Channel) error) {
+	buf := &bytes.Buffer{}
+	if err := write(buf, ch); err != nil {
+		log.Println("Error in linkHandler.Feed", err)
+		e.ErrorResponse(c, http.StatusInternalServerError, "Something went wrong")
+		return
+	}
+	c.DataFromReader(http.StatusOK, int64(buf.Len()), contentType, buf, nil)
+}
+
+// FeedAtom godoc
+// @Summary Search feed Atom
+// @Description Atom feed of link search, entries carry domain, sentiment and sentiment score as categories.
+// @Description Feed readers can't send Authorization header, so the feed is authenticated by token query parameter,
+// @Description see /account/feed-token. Entries are links of domains of the token owner organizations
+// @ID get-links-feed-atom
+// @Tags Link
+// @Produce xml
+// @Param object query links.FeedRequest true "Feed request"
+// @Param token query string true "Feed token"
+// @Success 200
+// @Failure 400 {object} types.ResponseErrorSwg
+// @Failure 401 {object} types.ResponseErrorSwg
+// @Failure 404 {object} types.ResponseErrorSwg
+// @Router /link/feed.atom [get]
+func (h *linkHandler) FeedAtom(c *gin.Context) {
+	if ch, ok := h.channel(c); ok {
+		writeFeed(c, feed.ContentTypeAtom, ch, feed.WriteAtom)
+	}
+}
+
+// FeedRSS godoc
+// @Summary Search feed RSS
+// @Description RSS 2.0 feed of link search, the same as Atom feed
+// @ID get-links-feed-rss
+// @Tags Link
+// @Produce xml
+// @Param object query links.FeedRequest true "Feed request"
+// @Param token query string true "Feed token"
+// @Success 200
+// @Failure 400 {object} types.ResponseErrorSwg
+// @Failure 401 {object} types.ResponseErrorSwg
+// @Failure 404 {object} types.ResponseErrorSwg
+// @Router /link/feed.rss [get]
+func (h *linkHandler) FeedRSS(c *gin.Context) {
+	if ch, ok := h.channel(c); ok {
+		writeFeed(c, feed.ContentTypeRSS, ch, feed.WriteRSS)
+	}
+}
@@ -0,0 +1,56 @@
+package links
+
+import (
+	"oko/pkg/domain"
+	"oko/pkg/feed"
+	"testing"
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLinkID(t *testing.T) {
+	id, ok := linkID("4200000001")
+	require.True(t, ok)
+	require.Equal(t, uint(42), id)
+
+	_, ok = linkID("00000001")
+	require.False(t, ok)
+	_, ok = linkID("abc00000001")
+	require.False(t, ok)
+}
+
+func TestToEntry(t *testing.T) {
+	published := time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)
+	score := float32(-0.456)
+	link := &Link{
+		Model:            gorm.Model{ID: 7, UpdatedAt: published.Add(time.Hour)},
+		URL:              "https://example.com/post",
+		PublishedAt:      &published,
+		SentimentalScore: &score,
+		Domain:           domain.Domain{Name: "example.com"},
+	}
+
+	entry := toEntry(link, "  Headline of the post\nBody of the post")
+	require.Equal(t, "oko:link:7", entry.ID)
+	require.Equal(t, "Headline of the post", entry.Title)
+	require.Equal(t, "Headline of the post\nBody of the post", entry.Summary)
+	require.Equal(t, published, entry.Published)
+	require.Equal(t, []feed.Category{
+		{Scheme: "domain", Term: "example.com"},
+		{Scheme: "sentiment", Term: "negative"},
+		{Scheme: "sentiment-score", Term: "-0.46"},
+	}, entry.Categories)
+
+	link.SentimentalScore = nil
+	entry = toEntry(link, "")
+	require.Equal(t, "https://example.com/post", entry.Title)
+	require.Equal(t, feed.Category{Scheme: "sentiment", Term: "neutral"}, entry.Categories[1])
+	require.Len(t, entry.Categories, 2)
+}
+
+func TestSnippet(t *testing.T) {
+	require.Equal(t, "abc", snippet(" abc ", 5, false))
+	require.Equal(t, "абвг…", snippet("абвгдеж", 5, false))
+}
@@ -10,6 +10,12 @@ type ListRequest struct {
 	DomainID string `json:"domain_id" form:"domain_id"`
 }
 
+type FeedRequest struct {
+	Query    string `json:"query" form:"query" binding:"required"`
+	DomainID string `json:"domain_id" form:"domain_id"`
+	Limit    uint32 `json:"limit" form:"limit,default=30" binding:"omitempty,min=1,max=100"`
+}
+
 type RePostRequest struct {
 	URL string `json:"url" form:"url" binding:"required"`
 }
@@ -10,6 +10,8 @@ import (
 
 type Repository interface {
 	List([]uint) (models []*Link, err error)
+	ListOfOrganizations(orgIDs []uint, ids []uint) (models []*Link, err error)
+	DomainExists(orgIDs []uint, domainID uint) (bool, error)
 	Get(id uint) (*Link, error)
 	Update(id uint, values *Link) error
 	GetForDownloaderOld() []Link
@@ -56,6 +58,37 @@ func (r *linkRepository) List(ids []uint) (models []*Link, err error) {
 
 	return
 }
+
+// ListOfOrganizations returns links with the ids which belong to domains of the organizations.
+func (r *linkRepository) ListOfOrganizations(orgIDs []uint, ids []uint) (models []*Link, err error) {
+	if len(orgIDs) == 0 || len(ids) == 0 {
+		return
+	}
+	err = r.db.Preload("Domain").
+		Joins("join domains on domains.id = links.domain_id and domains.deleted_at is null").
+		Where("links.id in (?) and domains.organization_id in (?)", ids, orgIDs).
+		Find(&models).Error
+	if err != nil {
+		log.Println("Error in LinkRepository.ListOfOrganizations", err)
+	}
+	return
+}
+
+// DomainExists reports if the domain belongs to one of the organizations.
+func (r *linkRepository) DomainExists(orgIDs []uint, domainID uint) (bool, error) {
+	if len(orgIDs) == 0 {
+		return false, nil
+	}
+	var count int
+	err := r.db.Table("domains").
+		Where("id = ? and organization_id in (?) and deleted_at is null", domainID, orgIDs).
+		Count(&count).Error
+	if err != nil {
+		log.Println("Error in LinkRepository.DomainExists", err)
+	}
+	return count > 0, err
+}
+
 func (r *linkRepository) Update(id uint, values *Link) error {
 	err := r.db.
 		Model(&Link{
@@ -133,6 +166,10 @@ func (r *linkRepository) GetForContentParser() []Link {
 }
 
 func (r *linkRepository) BulkCreateRecords(links []Link) error {
+	if len(links) == 0 {
+		return nil
+	}
+
 	var valueStrings []string
 	var valueArgs []interface{}
 
@@ -0,0 +1,50 @@
+package links
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jinzhu/gorm"
+	"github.com/stretchr/testify/require"
+)
+
+func mockRepository(t *testing.T) (Repository, sqlmock.Sqlmock) {
+	sqlDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	gdb, err := gorm.Open("postgres", sqlDB)
+	require.NoError(t, err)
+	return NewLinkRepository(gdb), mock
+}
+
+func TestListOfOrganizations(t *testing.T) {
+	repo, mock := mockRepository(t)
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT "links".* FROM "links" join domains on domains.id = links.domain_id `+
+		`and domains.deleted_at is null WHERE "links"."deleted_at" IS NULL AND ((links.id in ($1,$2) and domains.organization_id in ($3)))`)).
+		WithArgs(4, 5, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "url", "domain_id"}).AddRow(5, "https://example.com/post", 9))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "domains"  WHERE "domains"."deleted_at" IS NULL AND (("id" IN ($1)))`)).
+		WithArgs(9).WillReturnRows(sqlmock.NewRows([]string{"id", "name", "organization_id"}).AddRow(9, "example.com", 2))
+
+	links, err := repo.ListOfOrganizations([]uint{2}, []uint{4, 5})
+	require.NoError(t, err)
+	require.Len(t, links, 1)
+	require.Equal(t, "example.com", links[0].Domain.Name)
+	require.NoError(t, mock.ExpectationsWereMet())
+
+	// account without organizations sees nothing
+	links, err = repo.ListOfOrganizations(nil, []uint{4, 5})
+	require.NoError(t, err)
+	require.Empty(t, links)
+}
+
+func TestDomainExists(t *testing.T) {
+	repo, mock := mockRepository(t)
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "domains"  WHERE (id = $1 and organization_id in ($2,$3) and deleted_at is null)`)).
+		WithArgs(9, 2, 3).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+
+	exists, err := repo.DomainExists([]uint{2, 3}, 9)
+	require.NoError(t, err)
+	require.False(t, exists)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
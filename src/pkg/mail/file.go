@@ -0,0 +1,51 @@
+package mail
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+)
+
+// fileMailer stores messages in maildir layout, useful for local runs.
+type fileMailer struct {
+	dir      string
+	from     string
+	renderer *Renderer
+	seq      uint64
+}
+
+func NewFileMailer(dir, from string, renderer *Renderer) Mailer {
+	return &fileMailer{
+		dir:      dir,
+		from:     from,
+		renderer: renderer,
+	}
+}
+
+func (m *fileMailer) Send(msg Message) error {
+	if !validAddress(msg.To) {
+		return errors.New("invalid recipient address")
+	}
+	body, err := m.renderer.Render(msg.Template, msg.Data)
+	if err != nil {
+		return err
+	}
+
+	for _, sub := range []string{"tmp", "new", "cur"} {
+		if err = os.MkdirAll(filepath.Join(m.dir, sub), 0755); err != nil {
+			return err
+		}
+	}
+
+	host, _ := os.Hostname()
+	name := fmt.Sprintf("%d.%d_%d.%s.eml", time.Now().UnixNano(), os.Getpid(), atomic.AddUint64(&m.seq, 1), host)
+	tmpPath := filepath.Join(m.dir, "tmp", name)
+	if err = ioutil.WriteFile(tmpPath, compose(m.from, msg, body), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, filepath.Join(m.dir, "new", name))
+}
@@ -0,0 +1,90 @@
+package mail
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"mime"
+	"oko/pkg/cfg"
+	"strings"
+	"time"
+)
+
+const (
+	BackendSMTP = "smtp"
+	BackendFile = "file"
+
+	queueSize = 100
+)
+
+type Message struct {
+	To       string
+	Subject  string
+	Template string
+	Data     interface{}
+}
+
+type Mailer interface {
+	Send(msg Message) error
+}
+
+var ErrNotInitialized = errors.New("mailer is not initialized")
+
+var defQueue *Queue
+
+// New returns mailer for the backend configured by MAIL_BACKEND.
+func New() (Mailer, error) {
+	renderer := NewRenderer(cfg.App.MailTemplatesDir)
+	switch cfg.App.MailBackend {
+	case BackendSMTP:
+		return NewSMTPMailer(cfg.App.SMTPHost, cfg.App.SMTPPort, cfg.App.SMTPUser, cfg.App.SMTPPassword,
+			cfg.App.MailFrom, renderer), nil
+	case BackendFile:
+		return NewFileMailer(cfg.App.MailDir, cfg.App.MailFrom, renderer), nil
+	default:
+		return nil, fmt.Errorf("unknown mail backend %s", cfg.App.MailBackend)
+	}
+}
+
+// Init starts the default queue with mailer of the configured backend, it's called on start of the app.
+func Init() error {
+	mailer, err := New()
+	if err != nil {
+		return err
+	}
+	defQueue = NewQueue(mailer, queueSize)
+	return nil
+}
+
+// Send puts the message to the default queue, the message is sent in background.
+func Send(msg Message) error {
+	if defQueue == nil {
+		return ErrNotInitialized
+	}
+	return defQueue.Push(msg)
+}
+
+func compose(from string, msg Message, body string) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("From: " + from + "\r\n")
+	buf.WriteString("To: " + msg.To + "\r\n")
+	buf.WriteString("Subject: " + mime.QEncoding.Encode("utf-8", msg.Subject) + "\r\n")
+	buf.WriteString("Date: " + time.Now().Format(time.RFC1123Z) + "\r\n")
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/html; charset=\"utf-8\"\r\n")
+	buf.WriteString("Content-Transfer-Encoding: base64\r\n")
+	buf.WriteString("\r\n")
+
+	encoded := base64.StdEncoding.EncodeToString([]byte(body))
+	for len(encoded) > 76 {
+		buf.WriteString(encoded[:76] + "\r\n")
+		encoded = encoded[76:]
+	}
+	buf.WriteString(encoded + "\r\n")
+	return buf.Bytes()
+}
+
+func validAddress(addr string) bool {
+	return addr != "" && !strings.ContainsAny(addr, "\r\n")
+}
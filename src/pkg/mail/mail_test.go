@@ -0,0 +1,118 @@
+package mail
+
+import (
+	"encoding/base64"
+	"errors"
+	"io/ioutil"
+	"oko/pkg/cfg"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newTemplates(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "mail-templates")
+	require.NoError(t, err)
+	err = ioutil.WriteFile(filepath.Join(dir, "hello.html"), []byte(`<p>Hello, {{.Name}}</p>`), 0644)
+	require.NoError(t, err)
+	return dir
+}
+
+func TestRender(t *testing.T) {
+	dir := newTemplates(t)
+	defer os.RemoveAll(dir)
+	r := NewRenderer(dir)
+
+	body, err := r.Render("hello", map[string]string{"Name": "<b>Ann</b>"})
+	require.NoError(t, err)
+	require.Equal(t, "<p>Hello, &lt;b&gt;Ann&lt;/b&gt;</p>", body)
+
+	// templates are cached after first use
+	require.NoError(t, os.Remove(filepath.Join(dir, "hello.html")))
+	_, err = r.Render("hello", map[string]string{"Name": "Bob"})
+	require.NoError(t, err)
+
+	_, err = r.Render("../missing", nil)
+	require.Error(t, err)
+}
+
+func TestCompose(t *testing.T) {
+	body := strings.Repeat("Привет ", 20)
+	data := string(compose("noreply@example.com", Message{To: "user@example.com", Subject: "Тема"}, body))
+
+	head := data[:strings.Index(data, "\r\n\r\n")]
+	require.Contains(t, head, "From: noreply@example.com\r\n")
+	require.Contains(t, head, "To: user@example.com\r\n")
+	require.Contains(t, head, "Subject: =?utf-8?q?")
+
+	var encoded string
+	for _, line := range strings.Split(strings.TrimSpace(data[len(head)+4:]), "\r\n") {
+		require.True(t, len(line) <= 76)
+		encoded += line
+	}
+	decoded, err := base64.StdEncoding.DecodeString(encoded)
+	require.NoError(t, err)
+	require.Equal(t, body, string(decoded))
+}
+
+func TestFileMailer(t *testing.T) {
+	tmpl := newTemplates(t)
+	defer os.RemoveAll(tmpl)
+	dir, err := ioutil.TempDir("", "maildir")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	m := NewFileMailer(dir, "noreply@example.com", NewRenderer(tmpl))
+	require.NoError(t, m.Send(Message{To: "user@example.com", Subject: "Hi", Template: "hello"}))
+	require.Error(t, m.Send(Message{To: "user@example.com\r\nBcc: x@example.com", Template: "hello"}))
+
+	files, err := ioutil.ReadDir(filepath.Join(dir, "new"))
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	files, err = ioutil.ReadDir(filepath.Join(dir, "tmp"))
+	require.NoError(t, err)
+	require.Empty(t, files)
+}
+
+type fakeMailer chan Message
+
+func (m fakeMailer) Send(msg Message) error {
+	if msg.To == "" {
+		return errors.New("no recipient")
+	}
+	m <- msg
+	return nil
+}
+
+func TestQueue(t *testing.T) {
+	sent := make(fakeMailer, 1)
+	q := NewQueue(sent, 1)
+	require.NoError(t, q.Push(Message{To: "user@example.com"}))
+
+	select {
+	case msg := <-sent:
+		require.Equal(t, "user@example.com", msg.To)
+	case <-time.After(time.Second):
+		t.Fatal("message is not sent")
+	}
+
+	full := &Queue{mailer: sent, ch: make(chan Message)}
+	require.Equal(t, ErrQueueFull, full.Push(Message{To: "user@example.com"}))
+}
+
+func TestInit(t *testing.T) {
+	defQueue = nil
+	require.Equal(t, ErrNotInitialized, Send(Message{To: "user@example.com"}))
+
+	cfg.App.MailBackend = "pigeon"
+	require.Error(t, Init())
+	require.Equal(t, ErrNotInitialized, Send(Message{To: "user@example.com"}))
+
+	cfg.App.MailBackend = BackendFile
+	require.NoError(t, Init())
+	require.NotNil(t, defQueue)
+}
@@ -0,0 +1,53 @@
+package mail
+
+import (
+	"errors"
+	"oko/pkg/log"
+	"time"
+)
+
+const (
+	sendTries = 3
+	tryPause  = 5 * time.Second
+)
+
+var ErrQueueFull = errors.New("mail queue is full")
+
+// Queue sends messages in background so callers never wait for the mail server.
+type Queue struct {
+	mailer Mailer
+	ch     chan Message
+}
+
+func NewQueue(mailer Mailer, size int) *Queue {
+	q := &Queue{
+		mailer: mailer,
+		ch:     make(chan Message, size),
+	}
+	go q.run()
+	return q
+}
+
+func (q *Queue) Push(msg Message) error {
+	select {
+	case q.ch <- msg:
+		return nil
+	default:
+		return ErrQueueFull
+	}
+}
+
+func (q *Queue) run() {
+	for msg := range q.ch {
+		var err error
+		for i := 0; i < sendTries; i++ {
+			if err = q.mailer.Send(msg); err == nil {
+				break
+			}
+			time.Sleep(tryPause)
+		}
+		if err != nil {
+			log.Printf("Fail to send mail %s to %s: [%+v]", msg.Template, msg.To, err)
+		}
+	}
+}
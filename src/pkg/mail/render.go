@@ -0,0 +1,51 @@
+package mail
+
+import (
+	"bytes"
+	"html/template"
+	"path/filepath"
+	"sync"
+)
+
+const templateExt = ".html"
+
+type Renderer struct {
+	dir   string
+	mu    sync.Mutex
+	cache map[string]*template.Template
+}
+
+func NewRenderer(dir string) *Renderer {
+	return &Renderer{
+		dir:   dir,
+		cache: make(map[string]*template.Template),
+	}
+}
+
+// Render executes template <dir>/<name>.html with data.
+func (r *Renderer) Render(name string, data interface{}) (string, error) {
+	tmpl, err := r.get(name)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err = tmpl.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+func (r *Renderer) get(name string) (*template.Template, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if tmpl, ok := r.cache[name]; ok {
+		return tmpl, nil
+	}
+	tmpl, err := template.ParseFiles(filepath.Join(r.dir, filepath.Base(name)+templateExt))
+	if err != nil {
+		return nil, err
+	}
+	r.cache[name] = tmpl
+	return tmpl, nil
+}
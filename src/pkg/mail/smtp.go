@@ -0,0 +1,38 @@
+package mail
+
+import (
+	"errors"
+	"net"
+	"net/smtp"
+	"strconv"
+)
+
+type smtpMailer struct {
+	addr     string
+	auth     smtp.Auth
+	from     string
+	renderer *Renderer
+}
+
+func NewSMTPMailer(host string, port int, user, password, from string, renderer *Renderer) Mailer {
+	m := &smtpMailer{
+		addr:     net.JoinHostPort(host, strconv.Itoa(port)),
+		from:     from,
+		renderer: renderer,
+	}
+	if user != "" {
+		m.auth = smtp.PlainAuth("", user, password, host)
+	}
+	return m
+}
+
+func (m *smtpMailer) Send(msg Message) error {
+	if !validAddress(msg.To) {
+		return errors.New("invalid recipient address")
+	}
+	body, err := m.renderer.Render(msg.Template, msg.Data)
+	if err != nil {
+		return err
+	}
+	return smtp.SendMail(m.addr, m.auth, m.from, []string{msg.To}, compose(m.from, msg, body))
+}
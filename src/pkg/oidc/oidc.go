@@ -0,0 +1,304 @@
+// Package oidc implements OpenID Connect authorization code flow with PKCE for a relying party.
+package oidc
+
+import (
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/url"
+	"oko/pkg/rndstr"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	discoveryPath  = "/.well-known/openid-configuration"
+	verifierLength = 64
+)
+
+var (
+	ErrMalformed     = errors.New("oidc: malformed id token")
+	ErrUnknownKey    = errors.New("oidc: unknown signing key")
+	ErrSignature     = errors.New("oidc: invalid id token signature")
+	ErrInvalidClaims = errors.New("oidc: invalid id token claims")
+	ErrExpired       = errors.New("oidc: id token is expired")
+)
+
+// Clock is a source of current time, tests replace it with fixed one.
+type Clock interface {
+	Now() time.Time
+}
+
+type SystemClock struct{}
+
+func (SystemClock) Now() time.Time {
+	return time.Now()
+}
+
+type Config struct {
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+	// GroupsClaim is the name of id token claim listing groups of the user.
+	GroupsClaim string
+}
+
+// Provider is discovered identity provider.
+type Provider struct {
+	Config
+	AuthURL  string
+	TokenURL string
+	JWKSURL  string
+	Client   *http.Client
+	Clock    Clock
+
+	mu   sync.Mutex
+	keys map[string]*rsa.PublicKey
+}
+
+type discovery struct {
+	Issuer   string `json:"issuer"`
+	AuthURL  string `json:"authorization_endpoint"`
+	TokenURL string `json:"token_endpoint"`
+	JWKSURL  string `json:"jwks_uri"`
+}
+
+// IDToken is verified identity of the user.
+type IDToken struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+	Groups        []string
+}
+
+// Discover loads provider metadata from the issuer.
+func Discover(conf Config, client *http.Client, clock Clock) (*Provider, error) {
+	var d discovery
+	if err := getJSON(client, strings.TrimSuffix(conf.Issuer, "/")+discoveryPath, &d); err != nil {
+		return nil, err
+	}
+	if d.Issuer != conf.Issuer {
+		return nil, fmt.Errorf("oidc: issuer %q doesn't match configured %q", d.Issuer, conf.Issuer)
+	}
+	return &Provider{
+		Config:   conf,
+		AuthURL:  d.AuthURL,
+		TokenURL: d.TokenURL,
+		JWKSURL:  d.JWKSURL,
+		Client:   client,
+		Clock:    clock,
+	}, nil
+}
+
+// NewPKCE returns code verifier and its S256 challenge.
+func NewPKCE() (verifier, challenge string) {
+	verifier = rndstr.RandString(verifierLength)
+	return verifier, pkceChallenge(verifier)
+}
+
+func pkceChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// AuthCodeURL returns url of the provider the user is redirected to.
+func (p *Provider) AuthCodeURL(state, nonce, challenge string) string {
+	q := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {p.ClientID},
+		"redirect_uri":          {p.RedirectURL},
+		"scope":                 {strings.Join(append([]string{"openid"}, p.Scopes...), " ")},
+		"state":                 {state},
+		"nonce":                 {nonce},
+		"code_challenge":        {challenge},
+		"code_challenge_method": {"S256"},
+	}
+	sep := "?"
+	if strings.Contains(p.AuthURL, "?") {
+		sep = "&"
+	}
+	return p.AuthURL + sep + q.Encode()
+}
+
+// Exchange redeems authorization code and returns verified id token.
+func (p *Provider) Exchange(code, verifier, nonce string) (*IDToken, error) {
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {p.RedirectURL},
+		"client_id":     {p.ClientID},
+		"client_secret": {p.ClientSecret},
+		"code_verifier": {verifier},
+	}
+	resp, err := p.Client.PostForm(p.TokenURL, form)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("oidc: token endpoint responded %s", resp.Status)
+	}
+
+	var tokens struct {
+		IDToken string `json:"id_token"`
+	}
+	if err = json.NewDecoder(resp.Body).Decode(&tokens); err != nil {
+		return nil, err
+	}
+	if tokens.IDToken == "" {
+		return nil, ErrMalformed
+	}
+	return p.Verify(tokens.IDToken, nonce)
+}
+
+// Verify checks signature and claims of RS256 id token.
+func (p *Provider) Verify(raw, nonce string) (*IDToken, error) {
+	parts := strings.Split(raw, ".")
+	if len(parts) != 3 {
+		return nil, ErrMalformed
+	}
+	var head struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodeJSON(parts[0], &head); err != nil || head.Alg != "RS256" {
+		return nil, ErrMalformed
+	}
+	key, err := p.key(head.Kid)
+	if err != nil {
+		return nil, err
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrMalformed
+	}
+	sum := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if rsa.VerifyPKCS1v15(key, crypto.SHA256, sum[:], sig) != nil {
+		return nil, ErrSignature
+	}
+
+	var claims map[string]interface{}
+	if err = decodeJSON(parts[1], &claims); err != nil {
+		return nil, ErrMalformed
+	}
+	if claims["iss"] != p.Issuer || !hasAudience(claims["aud"], p.ClientID) || claims["nonce"] != nonce {
+		return nil, ErrInvalidClaims
+	}
+	exp, _ := claims["exp"].(float64)
+	if p.Clock.Now().Unix() >= int64(exp) {
+		return nil, ErrExpired
+	}
+
+	token := &IDToken{}
+	token.Subject, _ = claims["sub"].(string)
+	token.Email, _ = claims["email"].(string)
+	token.EmailVerified, _ = claims["email_verified"].(bool)
+	token.Name, _ = claims["name"].(string)
+	if token.Subject == "" {
+		return nil, ErrInvalidClaims
+	}
+	if groups, ok := claims[p.GroupsClaim].([]interface{}); ok {
+		for _, g := range groups {
+			if s, ok := g.(string); ok {
+				token.Groups = append(token.Groups, s)
+			}
+		}
+	}
+	return token, nil
+}
+
+func hasAudience(aud interface{}, clientID string) bool {
+	switch v := aud.(type) {
+	case string:
+		return v == clientID
+	case []interface{}:
+		for _, a := range v {
+			if a == clientID {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// key returns signing key by kid, the key set is reloaded once for unknown kid to follow key rotation.
+func (p *Provider) key(kid string) (*rsa.PublicKey, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if k, ok := p.keys[kid]; ok {
+		return k, nil
+	}
+	keys, err := p.loadKeys()
+	if err != nil {
+		return nil, err
+	}
+	p.keys = keys
+	if k, ok := p.keys[kid]; ok {
+		return k, nil
+	}
+	return nil, ErrUnknownKey
+}
+
+func (p *Provider) loadKeys() (map[string]*rsa.PublicKey, error) {
+	var set struct {
+		Keys []struct {
+			Kid string `json:"kid"`
+			Kty string `json:"kty"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+		} `json:"keys"`
+	}
+	if err := getJSON(p.Client, p.JWKSURL, &set); err != nil {
+		return nil, err
+	}
+	keys := make(map[string]*rsa.PublicKey, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Kty != "RSA" {
+			continue
+		}
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			return nil, err
+		}
+		keys[k.Kid] = &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}
+	}
+	return keys, nil
+}
+
+func getJSON(client *http.Client, u string, v interface{}) error {
+	resp, err := client.Get(u)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("oidc: %s responded %s", u, resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+func decodeJSON(part string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(part)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
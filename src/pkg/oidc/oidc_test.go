@@ -0,0 +1,190 @@
+package oidc
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+// mockIdP is a local identity provider issuing id token for the code it handed out.
+type mockIdP struct {
+	t         *testing.T
+	srv       *httptest.Server
+	key       *rsa.PrivateKey
+	kid       string
+	code      string
+	challenge string
+	claims    map[string]interface{}
+}
+
+func newMockIdP(t *testing.T) *mockIdP {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	m := &mockIdP{t: t, key: key, kid: "k1", code: "code-1"}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(discoveryPath, func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, discovery{
+			Issuer:   m.srv.URL,
+			AuthURL:  m.srv.URL + "/authorize",
+			TokenURL: m.srv.URL + "/token",
+			JWKSURL:  m.srv.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]interface{}{"keys": []map[string]string{{
+			"kid": m.kid,
+			"kty": "RSA",
+			"n":   base64.RawURLEncoding.EncodeToString(m.key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(m.key.E)).Bytes()),
+		}}})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		if r.Form.Get("code") != m.code || pkceChallenge(r.Form.Get("code_verifier")) != m.challenge {
+			http.Error(w, "invalid_grant", http.StatusBadRequest)
+			return
+		}
+		writeJSON(w, map[string]string{"id_token": m.sign(m.claims)})
+	})
+	m.srv = httptest.NewServer(mux)
+	return m
+}
+
+func (m *mockIdP) sign(claims map[string]interface{}) string {
+	head, err := json.Marshal(map[string]string{"alg": "RS256", "kid": m.kid})
+	require.NoError(m.t, err)
+	body, err := json.Marshal(claims)
+	require.NoError(m.t, err)
+	unsigned := base64.RawURLEncoding.EncodeToString(head) + "." + base64.RawURLEncoding.EncodeToString(body)
+	sum := sha256.Sum256([]byte(unsigned))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, m.key, crypto.SHA256, sum[:])
+	require.NoError(m.t, err)
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func setup(t *testing.T) (*mockIdP, *Provider, *fakeClock) {
+	m := newMockIdP(t)
+	clock := &fakeClock{now: time.Unix(1600000000, 0)}
+	p, err := Discover(Config{
+		Issuer:      m.srv.URL,
+		ClientID:    "oko",
+		RedirectURL: "https://front.test/oidc/callback",
+		Scopes:      []string{"email"},
+		GroupsClaim: "groups",
+	}, m.srv.Client(), clock)
+	require.NoError(t, err)
+	return m, p, clock
+}
+
+func (m *mockIdP) claimsFor(p *Provider, nonce string) map[string]interface{} {
+	return map[string]interface{}{
+		"iss":            p.Issuer,
+		"aud":            []string{"other", p.ClientID},
+		"sub":            "user-1",
+		"email":          "user@example.com",
+		"email_verified": true,
+		"name":           "User",
+		"groups":         []string{"admins", "staff"},
+		"nonce":          nonce,
+		"exp":            p.Clock.Now().Add(time.Minute).Unix(),
+	}
+}
+
+func TestAuthCodeFlow(t *testing.T) {
+	m, p, _ := setup(t)
+	defer m.srv.Close()
+
+	verifier, challenge := NewPKCE()
+	authURL, err := url.Parse(p.AuthCodeURL("state-1", "nonce-1", challenge))
+	require.NoError(t, err)
+	require.Equal(t, m.srv.URL+"/authorize", authURL.Scheme+"://"+authURL.Host+authURL.Path)
+	q := authURL.Query()
+	require.Equal(t, "state-1", q.Get("state"))
+	require.Equal(t, "S256", q.Get("code_challenge_method"))
+	require.Equal(t, "openid email", q.Get("scope"))
+
+	m.challenge = q.Get("code_challenge")
+	m.claims = m.claimsFor(p, "nonce-1")
+
+	idt, err := p.Exchange(m.code, verifier, "nonce-1")
+	require.NoError(t, err)
+	require.Equal(t, &IDToken{
+		Subject:       "user-1",
+		Email:         "user@example.com",
+		EmailVerified: true,
+		Name:          "User",
+		Groups:        []string{"admins", "staff"},
+	}, idt)
+
+	_, err = p.Exchange(m.code, "wrong-verifier", "nonce-1")
+	require.Error(t, err)
+}
+
+func TestVerifyClaims(t *testing.T) {
+	m, p, clock := setup(t)
+	defer m.srv.Close()
+
+	claims := m.claimsFor(p, "nonce-1")
+	_, err := p.Verify(m.sign(claims), "nonce-2")
+	require.Equal(t, ErrInvalidClaims, err)
+
+	claims["aud"] = "other"
+	_, err = p.Verify(m.sign(claims), "nonce-1")
+	require.Equal(t, ErrInvalidClaims, err)
+
+	claims = m.claimsFor(p, "nonce-1")
+	clock.now = clock.now.Add(time.Minute)
+	_, err = p.Verify(m.sign(claims), "nonce-1")
+	require.Equal(t, ErrExpired, err)
+}
+
+func TestVerifySignature(t *testing.T) {
+	m, p, _ := setup(t)
+	defer m.srv.Close()
+
+	token := m.sign(m.claimsFor(p, "nonce-1"))
+	parts := strings.Split(token, ".")
+	forged := parts[0] + "." + base64.RawURLEncoding.EncodeToString([]byte(`{"sub":"admin"}`)) + "." + parts[2]
+	_, err := p.Verify(forged, "nonce-1")
+	require.Equal(t, ErrSignature, err)
+
+	// rotated key is picked up from key set
+	m.kid = "k2"
+	_, err = p.Verify(m.sign(m.claimsFor(p, "nonce-1")), "nonce-1")
+	require.NoError(t, err)
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	m.key = key
+	m.kid = "k3"
+	unknown := m.sign(m.claimsFor(p, "nonce-1"))
+	m.kid = "k4"
+	_, err = p.Verify(unknown, "nonce-1")
+	require.Equal(t, ErrUnknownKey, err)
+}
@@ -0,0 +1,119 @@
+// Package opml reads and writes OPML subscription lists.
+package opml
+
+import (
+	"encoding/xml"
+	"errors"
+	"io"
+	"strings"
+	"time"
+)
+
+var ErrFormat = errors.New("opml: not an opml document")
+
+// Feed is a subscription of the list, nesting of outlines is not kept.
+type Feed struct {
+	XMLURL  string
+	HTMLURL string
+	Title   string
+}
+
+// Group is an outline of feeds, it's written as a folder.
+type Group struct {
+	Title string
+	Feeds []Feed
+}
+
+type outline struct {
+	Type     string    `xml:"type,attr,omitempty"`
+	Text     string    `xml:"text,attr"`
+	Title    string    `xml:"title,attr,omitempty"`
+	XMLURL   string    `xml:"xmlUrl,attr,omitempty"`
+	HTMLURL  string    `xml:"htmlUrl,attr,omitempty"`
+	Outlines []outline `xml:"outline"`
+}
+
+type document struct {
+	XMLName xml.Name `xml:"opml"`
+	Version string   `xml:"version,attr"`
+	Head    struct {
+		Title       string `xml:"title"`
+		DateCreated string `xml:"dateCreated,omitempty"`
+	} `xml:"head"`
+	Body struct {
+		Outlines []outline `xml:"outline"`
+	} `xml:"body"`
+}
+
+// Parse returns feeds of all outlines with xmlUrl in document order.
+func Parse(r io.Reader) ([]Feed, error) {
+	var doc document
+	dec := xml.NewDecoder(r)
+	dec.Strict = false
+	if err := dec.Decode(&doc); err != nil {
+		switch err.(type) {
+		case *xml.SyntaxError, xml.UnmarshalError:
+			return nil, ErrFormat
+		}
+		if err == io.EOF {
+			return nil, ErrFormat
+		}
+		return nil, err
+	}
+
+	var feeds []Feed
+	var walk func(items []outline)
+	walk = func(items []outline) {
+		for _, o := range items {
+			if link := strings.TrimSpace(o.XMLURL); link != "" {
+				title := strings.TrimSpace(o.Title)
+				if title == "" {
+					title = strings.TrimSpace(o.Text)
+				}
+				feeds = append(feeds, Feed{
+					XMLURL:  link,
+					HTMLURL: strings.TrimSpace(o.HTMLURL),
+					Title:   title,
+				})
+			}
+			walk(o.Outlines)
+		}
+	}
+	walk(doc.Body.Outlines)
+	return feeds, nil
+}
+
+// Write writes OPML 2.0 document with a folder outline per group.
+func Write(w io.Writer, title string, created time.Time, groups []Group) error {
+	doc := document{Version: "2.0"}
+	doc.Head.Title = title
+	doc.Head.DateCreated = created.UTC().Format(time.RFC1123Z)
+	for _, g := range groups {
+		folder := outline{Text: g.Title, Title: g.Title}
+		for _, f := range g.Feeds {
+			text := f.Title
+			if text == "" {
+				text = f.XMLURL
+			}
+			folder.Outlines = append(folder.Outlines, outline{
+				Type:    "rss",
+				Text:    text,
+				Title:   f.Title,
+				XMLURL:  f.XMLURL,
+				HTMLURL: f.HTMLURL,
+			})
+		}
+		doc.Body.Outlines = append(doc.Body.Outlines, folder)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
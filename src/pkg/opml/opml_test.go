@@ -0,0 +1,59 @@
+package opml
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+const subscriptions = `<?xml version="1.0" encoding="UTF-8"?>
+<opml version="1.0">
+  <head><title>Subscriptions</title></head>
+  <body>
+    <outline text="News">
+      <outline text="Example" type="rss" xmlUrl="https://example.com/rss" htmlUrl="https://example.com/"/>
+      <outline text="Nested">
+        <outline title="Blog" text="Blog text" xmlUrl=" https://blog.example.org/atom.xml "/>
+      </outline>
+    </outline>
+    <outline text="No feed" htmlUrl="https://example.net/"/>
+    <outline type="rss" xmlUrl="https://example.net/feed"/>
+  </body>
+</opml>`
+
+func TestParse(t *testing.T) {
+	feeds, err := Parse(strings.NewReader(subscriptions))
+	require.NoError(t, err)
+	require.Equal(t, []Feed{
+		{XMLURL: "https://example.com/rss", HTMLURL: "https://example.com/", Title: "Example"},
+		{XMLURL: "https://blog.example.org/atom.xml", Title: "Blog"},
+		{XMLURL: "https://example.net/feed"},
+	}, feeds)
+
+	_, err = Parse(strings.NewReader(`<rss version="2.0"><channel></channel></rss>`))
+	require.Equal(t, ErrFormat, err)
+
+	_, err = Parse(strings.NewReader(""))
+	require.Equal(t, ErrFormat, err)
+}
+
+func TestWrite(t *testing.T) {
+	var buf bytes.Buffer
+	created := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	err := Write(&buf, "Export", created, []Group{
+		{Title: "example.com", Feeds: []Feed{{XMLURL: "https://example.com/rss?a=1&b=2"}}},
+		{Title: "example.org", Feeds: []Feed{{XMLURL: "https://example.org/atom.xml", Title: "Blog"}}},
+	})
+	require.NoError(t, err)
+	require.Contains(t, buf.String(), `<dateCreated>Sun, 18 Oct 2026 12:00:00 +0000</dateCreated>`)
+
+	feeds, err := Parse(&buf)
+	require.NoError(t, err)
+	require.Equal(t, []Feed{
+		{XMLURL: "https://example.com/rss?a=1&b=2", Title: "https://example.com/rss?a=1&b=2"},
+		{XMLURL: "https://example.org/atom.xml", Title: "Blog"},
+	}, feeds)
+}
@@ -0,0 +1,285 @@
+package org
+
+import (
+	"net/http"
+	"oko/pkg/account"
+	"oko/pkg/audit"
+	"oko/pkg/db"
+	"oko/pkg/e"
+	"oko/pkg/ginapp/types"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// List godoc
+// @Summary List organizations
+// @Description List organizations of current account
+// @ID get-org-list
+// @Tags Organization
+// @Accept json
+// @Produce json
+// @Success 200 {object} types.ResponseOrganizations
+// @Failure 500 {object} types.ResponseErrorSwg
+// @Router /org [get]
+// @Security ApiKeyAuth
+func List(c *gin.Context) {
+	list, err := ListMemberships(account.GetContextAcc(c).ID)
+	if err != nil {
+		e.ErrorResponse(c, http.StatusInternalServerError, "Something went wrong")
+		return
+	}
+
+	data := make([]types.Organization, 0, len(list))
+	for _, m := range list {
+		data = append(data, toView(m))
+	}
+	types.SuccessResponse(c, data)
+}
+
+// Create godoc
+// @Summary Create organization
+// @Description Create organization, current account becomes its owner
+// @ID post-org
+// @Tags Organization
+// @Accept json
+// @Produce json
+// @Param object body org.CreateForm true "Organization fields"
+// @Success 200 {object} types.ResponseOrganization
+// @Failure 400 {object} types.ResponseErrorSwg
+// @Failure 500 {object} types.ResponseErrorSwg
+// @Router /org [post]
+// @Security ApiKeyAuth
+func Create(c *gin.Context) {
+	var form CreateForm
+
+	if err := c.ShouldBind(&form); err != nil {
+		e.ErrorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	m, err := CreateOrganization(form.Name, account.GetContextAcc(c))
+	if err != nil {
+		panic(err)
+	}
+
+	types.SuccessResponse(c, toView(*m))
+}
+
+// Members godoc
+// @Summary List members
+// @Description List members of active organization
+// @ID get-org-members
+// @Tags Organization
+// @Accept json
+// @Produce json
+// @Success 200 {object} types.ResponseOrgMembers
+// @Failure 500 {object} types.ResponseErrorSwg
+// @Router /org/members [get]
+// @Security ApiKeyAuth
+func Members(c *gin.Context) {
+	list, err := ListMembers(GetID(c))
+	if err != nil {
+		e.ErrorResponse(c, http.StatusInternalServerError, "Something went wrong")
+		return
+	}
+
+	data := make([]types.OrgMember, 0, len(list))
+	for _, m := range list {
+		data = append(data, toMemberView(m))
+	}
+	types.SuccessResponse(c, data)
+}
+
+// AddMember godoc
+// @Summary Add member
+// @Description Add account to active organization, only owner can add owners
+// @ID post-org-member
+// @Tags Organization
+// @Accept json
+// @Produce json
+// @Param object body org.MemberForm true "Member fields"
+// @Success 200 {object} types.StdResponse
+// @Failure 400 {object} types.ResponseErrorSwg
+// @Failure 403 {object} types.ResponseErrorSwg
+// @Router /org/members [post]
+// @Security ApiKeyAuth
+func AddMember(c *gin.Context) {
+	var form MemberForm
+
+	if err := c.ShouldBind(&form); err != nil {
+		e.ErrorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+	if !GetMembership(c).HasRole(form.Role) {
+		e.ErrorResponse(c, http.StatusForbidden, "You can't grant role higher than yours")
+		return
+	}
+
+	acc, err := account.FindAccount(account.Account{Email: strings.ToLower(form.Email)})
+	if err != nil {
+		e.ErrorResponse(c, http.StatusBadRequest, "Something went wrong")
+		return
+	}
+	if _, err = FindMembership(GetID(c), acc.ID); err == nil {
+		e.ErrorResponse(c, http.StatusBadRequest, "Account is already a member")
+		return
+	}
+
+	m := Membership{OrganizationID: GetID(c), AccountID: acc.ID, Role: form.Role}
+	if err = db.GetDB().Omit("Organization", "Account").Create(&m).Error; err != nil {
+		panic(err)
+	}
+	auditMember(c, audit.OrgMemberAdd, acc.ID, map[string]string{"role": form.Role})
+
+	types.SuccessEmptyResponse(c)
+}
+
+// UpdateMember godoc
+// @Summary Change member role
+// @Description Change role of active organization member
+// @ID put-org-member
+// @Tags Organization
+// @Accept json
+// @Produce json
+// @Param account_id path int true "Account ID"
+// @Param object body org.MemberRoleForm true "Member role"
+// @Success 200 {object} types.StdResponse
+// @Failure 400 {object} types.ResponseErrorSwg
+// @Failure 403 {object} types.ResponseErrorSwg
+// @Failure 404 {object} types.ResponseErrorSwg
+// @Router /org/members/{account_id} [put]
+// @Security ApiKeyAuth
+func UpdateMember(c *gin.Context) {
+	var form MemberRoleForm
+
+	if err := c.ShouldBind(&form); err != nil {
+		e.ErrorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	m, ok := getMember(c)
+	if !ok {
+		return
+	}
+	if !GetMembership(c).HasRole(form.Role) || !GetMembership(c).HasRole(m.Role) {
+		e.ErrorResponse(c, http.StatusForbidden, "You can't manage roles higher than yours")
+		return
+	}
+	if m.Role == RoleOwner && form.Role != RoleOwner && !hasOtherOwner(m.OrganizationID) {
+		e.ErrorResponse(c, http.StatusBadRequest, "Organization must have an owner")
+		return
+	}
+
+	err := db.GetDB().Model(&Membership{}).
+		Where("organization_id = ? and account_id = ?", m.OrganizationID, m.AccountID).
+		Update("role", form.Role).Error
+	if err != nil {
+		panic(err)
+	}
+	auditMember(c, audit.OrgMemberUpdate, m.AccountID, map[string]string{"role": form.Role, "old_role": m.Role})
+
+	types.SuccessEmptyResponse(c)
+}
+
+// RemoveMember godoc
+// @Summary Remove member
+// @Description Remove account from active organization
+// @ID delete-org-member
+// @Tags Organization
+// @Accept json
+// @Produce json
+// @Param account_id path int true "Account ID"
+// @Success 200 {object} types.StdResponse
+// @Failure 400 {object} types.ResponseErrorSwg
+// @Failure 403 {object} types.ResponseErrorSwg
+// @Failure 404 {object} types.ResponseErrorSwg
+// @Router /org/members/{account_id} [delete]
+// @Security ApiKeyAuth
+func RemoveMember(c *gin.Context) {
+	m, ok := getMember(c)
+	if !ok {
+		return
+	}
+	if !GetMembership(c).HasRole(m.Role) {
+		e.ErrorResponse(c, http.StatusForbidden, "You can't remove member with role higher than yours")
+		return
+	}
+	if m.Role == RoleOwner && !hasOtherOwner(m.OrganizationID) {
+		e.ErrorResponse(c, http.StatusBadRequest, "Organization must have an owner")
+		return
+	}
+
+	err := db.GetDB().
+		Where("organization_id = ? and account_id = ?", m.OrganizationID, m.AccountID).
+		Delete(&Membership{}).Error
+	if err != nil {
+		panic(err)
+	}
+	auditMember(c, audit.OrgMemberRemove, m.AccountID, map[string]string{"role": m.Role})
+
+	types.SuccessEmptyResponse(c)
+}
+
+func auditMember(c *gin.Context, action string, accID int, details interface{}) {
+	audit.Record(c, audit.Event{Action: action, TargetType: audit.TargetAccount, TargetID: accID, Details: details})
+}
+
+// getMember loads member of active organization from account_id path param,
+// responds with error if it is not found.
+func getMember(c *gin.Context) (*Membership, bool) {
+	accID, err := strconv.Atoi(c.Param("account_id"))
+	if err != nil || accID <= 0 {
+		e.ErrorResponse(c, http.StatusBadRequest, "Invalid account id")
+		return nil, false
+	}
+	m, err := FindMembership(GetID(c), accID)
+	if err != nil {
+		e.ErrorResponse(c, http.StatusNotFound, "Member not found")
+		return nil, false
+	}
+	return m, true
+}
+
+func hasOtherOwner(orgID uint) bool {
+	count, err := countOwners(orgID)
+	if err != nil {
+		panic(err)
+	}
+	return count > 1
+}
+
+// Invite godoc
+// @Summary Invite member
+// @Description Invite e-mail to sign up, the account joins active organization with the role on sign up
+// @ID post-org-invite
+// @Tags Organization
+// @Accept json
+// @Produce json
+// @Param object body org.InviteForm true "Invite fields"
+// @Success 200 {object} types.ResponseInvite
+// @Failure 400 {object} types.ResponseErrorSwg
+// @Failure 403 {object} types.ResponseErrorSwg
+// @Failure 500 {object} types.ResponseErrorSwg
+// @Router /org/invites [post]
+// @Security ApiKeyAuth
+func Invite(c *gin.Context) {
+	var form InviteForm
+
+	if err := c.ShouldBind(&form); err != nil {
+		e.ErrorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	acc := account.GetContextAcc(c)
+	orgID := GetID(c)
+	inv := account.NewInvite(strings.ToLower(form.Email), acc.ID)
+	inv.OrganizationID = &orgID
+	inv.OrgRole = form.Role
+	if err := account.CreateInvite(&inv, acc); err != nil {
+		panic(err)
+	}
+
+	types.SuccessResponse(c, account.ToInviteView(inv))
+}
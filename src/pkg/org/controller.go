@@ -0,0 +1,28 @@
+package org
+
+import (
+	"oko/pkg/account"
+	"oko/pkg/ginapp/controller"
+
+	"github.com/gin-gonic/gin"
+)
+
+func NewController() controller.Ctrl {
+	return controller.Ctrl{
+		Name:     "org",
+		Handlers: controller.HandlerList{account.Auth(true, []int{})},
+		Acts: []controller.Act{
+			{Method: "GET", Route: "/", Handlers: []gin.HandlerFunc{List}, HumanOnly: true},
+			{Method: "POST", Route: "/", Handlers: []gin.HandlerFunc{Create}, HumanOnly: true},
+			{Method: "GET", Route: "/members/", Handlers: []gin.HandlerFunc{Active(), Members}, HumanOnly: true},
+			{Method: "POST", Route: "/members/", Handlers: []gin.HandlerFunc{Active(), RequireRole(RoleAdmin), AddMember},
+				HumanOnly: true},
+			{Method: "POST", Route: "/invites/", Handlers: []gin.HandlerFunc{Active(), RequireRole(RoleOwner), Invite},
+				HumanOnly: true},
+			{Method: "PUT", Route: "/members/:account_id",
+				Handlers: []gin.HandlerFunc{Active(), RequireRole(RoleAdmin), UpdateMember}, HumanOnly: true},
+			{Method: "DELETE", Route: "/members/:account_id",
+				Handlers: []gin.HandlerFunc{Active(), RequireRole(RoleAdmin), RemoveMember}, HumanOnly: true},
+		},
+	}
+}
@@ -0,0 +1,19 @@
+package org
+
+type CreateForm struct {
+	Name string `json:"name" form:"name" binding:"required,max=255"`
+}
+
+type MemberForm struct {
+	Email string `json:"email" form:"email" binding:"required,email,ExistsEmail"`
+	Role  string `json:"role" form:"role" binding:"required,oneof=member admin owner"`
+}
+
+type InviteForm struct {
+	Email string `json:"email" form:"email" binding:"required,email,UniqueEmail"`
+	Role  string `json:"role" form:"role" binding:"required,oneof=member admin owner"`
+}
+
+type MemberRoleForm struct {
+	Role string `json:"role" form:"role" binding:"required,oneof=member admin owner"`
+}
@@ -0,0 +1,101 @@
+package org
+
+import (
+	"errors"
+	"net/http"
+	"oko/pkg/account"
+	"oko/pkg/cfg"
+	"oko/pkg/e"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	orgIDKey      = "organization_id"
+	membershipKey = "organization_membership"
+)
+
+var errInvalidOrganization = errors.New("invalid organization")
+
+func init() {
+	// service accounts are created in active organization of the creator
+	account.ActiveOrganizationID = func(c *gin.Context) (uint, error) {
+		m, err := activeMembership(c, account.GetContextAccID(c))
+		if err != nil {
+			return 0, err
+		}
+		return m.OrganizationID, nil
+	}
+}
+
+// activeMembership returns membership of the account in organization of cfg.App.OrgKey header,
+// the oldest membership is used when the header is empty.
+func activeMembership(c *gin.Context, accID int) (*Membership, error) {
+	val := c.GetHeader(cfg.App.OrgKey)
+	if val == "" {
+		return DefaultMembership(accID)
+	}
+	id, err := strconv.Atoi(val)
+	if err != nil || id <= 0 {
+		return nil, errInvalidOrganization
+	}
+	return FindMembership(uint(id), accID)
+}
+
+// Active resolves active organization of the authenticated account from cfg.App.OrgKey header,
+// the oldest membership is used when the header is empty. Account without memberships is
+// forbidden. It must follow account.Auth.
+func Active() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		accID := account.GetContextAccID(c)
+		if accID == 0 {
+			e.ErrorResponse(c, e.ErrorAuth, "Authentication is required to perform this action")
+			c.Abort()
+			return
+		}
+
+		m, err := activeMembership(c, accID)
+		if err == errInvalidOrganization {
+			e.ErrorResponse(c, http.StatusBadRequest, "Invalid organization")
+			c.Abort()
+			return
+		}
+		if err != nil {
+			e.ErrorResponse(c, http.StatusForbidden, "You are not a member of the organization")
+			c.Abort()
+			return
+		}
+
+		c.Set(orgIDKey, m.OrganizationID)
+		c.Set(membershipKey, *m)
+		c.Next()
+	}
+}
+
+// RequireRole rejects members with role lower than the role, it must follow Active.
+func RequireRole(role string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !GetMembership(c).HasRole(role) {
+			e.ErrorResponse(c, http.StatusForbidden, "Organization "+role+" role is required")
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// GetID returns id of the active organization.
+func GetID(c *gin.Context) uint {
+	if id, ok := c.Get(orgIDKey); ok {
+		return id.(uint)
+	}
+	return 0
+}
+
+func GetMembership(c *gin.Context) Membership {
+	if m, ok := c.Get(membershipKey); ok {
+		return m.(Membership)
+	}
+	return Membership{}
+}
@@ -0,0 +1,126 @@
+package org
+
+import (
+	"database/sql/driver"
+	"net/http"
+	"net/http/httptest"
+	"oko/pkg/account"
+	"oko/pkg/cfg"
+	"oko/pkg/db"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/jinzhu/gorm"
+	"github.com/stretchr/testify/suite"
+)
+
+var memberColumns = []string{"organization_id", "account_id", "role"}
+
+type ActiveSuite struct {
+	suite.Suite
+	mock sqlmock.Sqlmock
+}
+
+func TestActive(t *testing.T) {
+	suite.Run(t, new(ActiveSuite))
+}
+
+func (s *ActiveSuite) SetupTest() {
+	sqlDB, mock, err := sqlmock.New()
+	s.Require().NoError(err)
+	gdb, err := gorm.Open("postgres", sqlDB)
+	s.Require().NoError(err)
+	db.SetDB(gdb)
+	s.mock = mock
+	cfg.App.OrgKey = "X-Organization"
+	gin.SetMode(gin.TestMode)
+}
+
+func (s *ActiveSuite) TearDownTest() {
+	s.Require().NoError(s.mock.ExpectationsWereMet())
+}
+
+func (s *ActiveSuite) context(accID int, header string) (*gin.Context, *httptest.ResponseRecorder) {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+	if header != "" {
+		c.Request.Header.Set(cfg.App.OrgKey, header)
+	}
+	c.Set("account_id", accID)
+	return c, w
+}
+
+func (s *ActiveSuite) expectMembership(args []driver.Value, rows *sqlmock.Rows) {
+	s.mock.ExpectQuery(`SELECT "organization_member".\* FROM "organization_member" join organization`).
+		WithArgs(args...).WillReturnRows(rows)
+}
+
+func (s *ActiveSuite) TestHeader() {
+	c, _ := s.context(7, "5")
+	s.expectMembership([]driver.Value{5, 7}, sqlmock.NewRows(memberColumns).AddRow(5, 7, RoleAdmin))
+	s.mock.ExpectQuery(`SELECT \* FROM "organization"`).WithArgs(5).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(5, "Acme"))
+
+	Active()(c)
+	s.False(c.IsAborted())
+	s.Equal(uint(5), GetID(c))
+	s.Equal(RoleAdmin, GetMembership(c).Role)
+	s.Equal("Acme", GetMembership(c).Organization.Name)
+}
+
+func (s *ActiveSuite) TestNotMember() {
+	c, w := s.context(7, "6")
+	s.expectMembership([]driver.Value{6, 7}, sqlmock.NewRows(memberColumns))
+
+	Active()(c)
+	s.True(c.IsAborted())
+	s.Equal(http.StatusForbidden, w.Code)
+	s.Equal(uint(0), GetID(c))
+}
+
+func (s *ActiveSuite) TestInvalidHeader() {
+	for _, header := range []string{"abc", "-1", "0"} {
+		c, w := s.context(7, header)
+		Active()(c)
+		s.True(c.IsAborted())
+		s.Equal(http.StatusBadRequest, w.Code, header)
+	}
+}
+
+func (s *ActiveSuite) TestDefault() {
+	c, _ := s.context(7, "")
+	s.expectMembership([]driver.Value{7}, sqlmock.NewRows(memberColumns).AddRow(3, 7, RoleOwner))
+	s.mock.ExpectQuery(`SELECT \* FROM "organization"`).WithArgs(3).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(3, "Personal"))
+
+	Active()(c)
+	s.False(c.IsAborted())
+	s.Equal(uint(3), GetID(c))
+
+	// service accounts are created in the same organization
+	s.expectMembership([]driver.Value{7}, sqlmock.NewRows(memberColumns).AddRow(3, 7, RoleOwner))
+	s.mock.ExpectQuery(`SELECT \* FROM "organization"`).WithArgs(3).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(3, "Personal"))
+	id, err := account.ActiveOrganizationID(c)
+	s.NoError(err)
+	s.Equal(uint(3), id)
+}
+
+func (s *ActiveSuite) TestNoMembership() {
+	// no organization is created on request, accounts get it on creation
+	c, w := s.context(7, "")
+	s.expectMembership([]driver.Value{7}, sqlmock.NewRows(memberColumns))
+
+	Active()(c)
+	s.True(c.IsAborted())
+	s.Equal(http.StatusForbidden, w.Code)
+}
+
+func (s *ActiveSuite) TestAnonymous() {
+	c, w := s.context(0, "5")
+	Active()(c)
+	s.True(c.IsAborted())
+	s.NotEqual(http.StatusOK, w.Code)
+}
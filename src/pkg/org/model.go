@@ -0,0 +1,126 @@
+package org
+
+import (
+	"oko/pkg/account"
+	"oko/pkg/db"
+	"time"
+)
+
+// Roles of organization member, each role includes the rights of the lower ones.
+const (
+	RoleMember = "member"
+	RoleAdmin  = "admin"
+	RoleOwner  = "owner"
+)
+
+var roleRanks = map[string]int{
+	RoleMember: 1,
+	RoleAdmin:  2,
+	RoleOwner:  3,
+}
+
+// Organization is a tenant owning domains, rss feeds, repost requests and rules.
+type Organization struct {
+	ID        uint       `json:"id"`
+	Name      string     `json:"name"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	DeletedAt *time.Time `json:"deleted_at"`
+}
+
+func (Organization) TableName() string {
+	return "organization"
+}
+
+type Membership struct {
+	OrganizationID uint            `json:"organization_id" gorm:"primary_key"`
+	AccountID      int             `json:"account_id" gorm:"primary_key"`
+	Role           string          `json:"role"`
+	CreatedAt      time.Time       `json:"created_at"`
+	Organization   Organization    `json:"organization" gorm:"foreignkey:OrganizationID"`
+	Account        account.Account `json:"-" gorm:"foreignkey:AccountID"`
+}
+
+func (Membership) TableName() string {
+	return "organization_member"
+}
+
+// HasRole reports if the member role is the role or a higher one.
+func (m Membership) HasRole(role string) bool {
+	return roleRanks[m.Role] >= roleRanks[role]
+}
+
+// CreateOrganization creates organization with the account as its owner.
+func CreateOrganization(name string, owner account.Account) (*Membership, error) {
+	m := &Membership{
+		AccountID:    owner.ID,
+		Role:         RoleOwner,
+		Organization: Organization{Name: name},
+	}
+	dbt := db.GetDB().Begin()
+	if err := dbt.Create(&m.Organization).Error; err != nil {
+		dbt.Rollback()
+		return nil, err
+	}
+	m.OrganizationID = m.Organization.ID
+	if err := dbt.Omit("Organization", "Account").Create(m).Error; err != nil {
+		dbt.Rollback()
+		return nil, err
+	}
+	return m, dbt.Commit().Error
+}
+
+func FindMembership(orgID uint, accID int) (*Membership, error) {
+	m := &Membership{}
+	err := db.GetDB().
+		Preload("Organization").
+		Joins("join organization on organization.id = organization_member.organization_id").
+		Where("organization.deleted_at is null").
+		Where("organization_member.organization_id = ? and organization_member.account_id = ?", orgID, accID).
+		First(m).Error
+	return m, err
+}
+
+// DefaultMembership returns the oldest membership of the account, accounts get
+// personal organization on creation.
+func DefaultMembership(accID int) (*Membership, error) {
+	m := &Membership{}
+	err := db.GetDB().
+		Preload("Organization").
+		Joins("join organization on organization.id = organization_member.organization_id").
+		Where("organization.deleted_at is null and organization_member.account_id = ?", accID).
+		Order("organization_member.created_at").
+		First(m).Error
+	return m, err
+}
+
+// ListMemberships returns organizations of the account.
+func ListMemberships(accID int) ([]Membership, error) {
+	var list []Membership
+	err := db.GetDB().
+		Preload("Organization").
+		Joins("join organization on organization.id = organization_member.organization_id").
+		Where("organization.deleted_at is null and organization_member.account_id = ?", accID).
+		Order("organization_member.created_at").
+		Find(&list).Error
+	return list, err
+}
+
+// ListMembers returns members of the organization.
+func ListMembers(orgID uint) ([]Membership, error) {
+	var list []Membership
+	err := db.GetDB().
+		Preload("Account").
+		Where("organization_id = ?", orgID).
+		Order("created_at").
+		Find(&list).Error
+	return list, err
+}
+
+func countOwners(orgID uint) (int, error) {
+	var count int
+	err := db.GetDB().Model(&Membership{}).
+		Where("organization_id = ? and role = ?", orgID, RoleOwner).
+		Count(&count).Error
+	return count, err
+}
@@ -0,0 +1,21 @@
+package org
+
+import "oko/pkg/ginapp/types"
+
+func toView(m Membership) types.Organization {
+	return types.Organization{
+		ID:   m.Organization.ID,
+		Name: m.Organization.Name,
+		Role: m.Role,
+	}
+}
+
+func toMemberView(m Membership) types.OrgMember {
+	return types.OrgMember{
+		AccountID: m.AccountID,
+		Email:     m.Account.Email,
+		Name:      m.Account.Name,
+		Role:      m.Role,
+		CreatedAt: m.CreatedAt,
+	}
+}
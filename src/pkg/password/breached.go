@@ -0,0 +1,109 @@
+package password
+
+import (
+	"bytes"
+	"crypto/sha1" //nolint:gosec
+	"encoding/hex"
+	"errors"
+	"io"
+	"os"
+	"strings"
+)
+
+// probeSize is read at every step of the search, it holds the rest of a line and the next line.
+const probeSize = 256
+
+var (
+	errBreachedFormat = errors.New("breached passwords file is not a file of SHA-1 hashes")
+	errBreachedLine   = errors.New("breached passwords file has too long line")
+)
+
+// BreachedList is a file of SHA-1 hashes of breached passwords sorted by hash. Lines are hex hashes
+// optionally followed by :count as in ordered by hash Have I Been Pwned dumps. The file is searched
+// on disk, so it takes no memory whatever its size.
+type BreachedList struct {
+	f    *os.File
+	size int64
+}
+
+// OpenBreached opens the sorted file of hashes, the file is kept open for the process lifetime.
+func OpenBreached(file string) (*BreachedList, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	list := &BreachedList{f: f, size: info.Size()}
+	first, err := list.hashAt(0)
+	if err == nil && list.size > 0 {
+		if _, decodeErr := hex.DecodeString(first); first == "" || decodeErr != nil {
+			err = errBreachedFormat
+		}
+	}
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return list, nil
+}
+
+// Contains reports if the password is breached, the hash is looked up by binary search
+// over byte offsets of the file.
+func (l *BreachedList) Contains(pass string) (bool, error) {
+	sum := sha1.Sum([]byte(pass)) //nolint:gosec
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+
+	// first offset whose line isn't less than the hash
+	lo, hi := int64(0), l.size
+	for lo < hi {
+		mid := lo + (hi-lo)/2
+		h, err := l.hashAt(mid)
+		if err != nil {
+			return false, err
+		}
+		if h == "" || h >= hash {
+			hi = mid
+		} else {
+			lo = mid + 1
+		}
+	}
+	h, err := l.hashAt(lo)
+	return h == hash, err
+}
+
+// hashAt returns upper case hash of the first line starting at the offset or after it,
+// it's empty at the end of the file.
+func (l *BreachedList) hashAt(off int64) (string, error) {
+	start := off
+	if off > 0 {
+		// the line starts at the offset if the previous byte ends a line
+		start = off - 1
+	}
+	buf := make([]byte, probeSize)
+	n, err := l.f.ReadAt(buf, start)
+	if err != nil && err != io.EOF {
+		return "", err
+	}
+	buf = buf[:n]
+	if off > 0 {
+		i := bytes.IndexByte(buf, '\n')
+		if i < 0 {
+			if n < probeSize {
+				return "", nil
+			}
+			return "", errBreachedLine
+		}
+		buf = buf[i+1:]
+	}
+	if len(buf) < hex.EncodedLen(sha1.Size) {
+		if n < probeSize {
+			return "", nil
+		}
+		return "", errBreachedLine
+	}
+	return strings.ToUpper(string(buf[:hex.EncodedLen(sha1.Size)])), nil
+}
@@ -0,0 +1,111 @@
+// Package password checks new passwords against the password policy.
+package password
+
+import (
+	"errors"
+	"oko/pkg/cfg"
+	"strings"
+	"unicode"
+)
+
+// minIdentityPart is the shortest part of e-mail or name looked up in password.
+const minIdentityPart = 3
+
+var errBreachedNotOpen = errors.New("breached passwords file is not open")
+
+// breached is the list of breached passwords of active policy, it's opened by Init.
+var breached *BreachedList
+
+// Init opens breached passwords file of active policy, it's called once at start up.
+func Init() error {
+	if cfg.App.Password.BreachedFile == "" {
+		return nil
+	}
+	list, err := OpenBreached(cfg.App.Password.BreachedFile)
+	if err != nil {
+		return err
+	}
+	breached = list
+	return nil
+}
+
+// Check reports if the password satisfies active policy and isn't breached,
+// identity is e-mail and name of the account.
+func Check(pass string, identity ...string) (bool, error) {
+	if !CheckPolicy(cfg.App.Password, pass, identity...) {
+		return false, nil
+	}
+	if cfg.App.Password.BreachedFile == "" {
+		return true, nil
+	}
+	if breached == nil {
+		return false, errBreachedNotOpen
+	}
+	found, err := breached.Contains(pass)
+	if err != nil {
+		return false, err
+	}
+	return !found, nil
+}
+
+// CheckPolicy reports if the password satisfies rules of the policy p,
+// breached passwords are looked up by Check.
+func CheckPolicy(p cfg.PasswordPolicy, pass string, identity ...string) bool {
+	if len([]rune(pass)) < p.MinLength {
+		return false
+	}
+
+	var lower, upper, digit, symbol bool
+	var prev rune
+	repeat := 0
+	for _, r := range pass {
+		switch {
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsDigit(r):
+			digit = true
+		default:
+			symbol = true
+		}
+		if r == prev {
+			repeat++
+		} else {
+			repeat = 1
+		}
+		if p.MaxRepeat > 0 && repeat > p.MaxRepeat {
+			return false
+		}
+		prev = r
+	}
+	if p.RequireLower && !lower || p.RequireUpper && !upper || p.RequireDigit && !digit || p.RequireSymbol && !symbol {
+		return false
+	}
+
+	if p.CheckIdentity && containsIdentity(pass, identity) {
+		return false
+	}
+
+	return true
+}
+
+// containsIdentity reports if password contains e-mail local part or a word of name.
+func containsIdentity(pass string, identity []string) bool {
+	pass = strings.ToLower(pass)
+	for _, id := range identity {
+		id = strings.ToLower(id)
+		if at := strings.LastIndex(id, "@"); at >= 0 {
+			id = id[:at]
+		}
+		parts := strings.FieldsFunc(id, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		})
+		for _, part := range append(parts, id) {
+			if len([]rune(part)) >= minIdentityPart && strings.Contains(pass, part) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,96 @@
+package password
+
+import (
+	"crypto/sha1" //nolint:gosec
+	"encoding/hex"
+	"io/ioutil"
+	"oko/pkg/cfg"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckPolicy(t *testing.T) {
+	p := cfg.PasswordPolicy{
+		MinLength:     10,
+		RequireLower:  true,
+		RequireUpper:  true,
+		RequireDigit:  true,
+		RequireSymbol: true,
+		MaxRepeat:     2,
+		CheckIdentity: true,
+	}
+
+	require.True(t, CheckPolicy(p, "Correct-Horse7"))
+	require.False(t, CheckPolicy(p, "Short-7a"), "too short")
+	require.False(t, CheckPolicy(p, "correct-horse7"), "no uppercase")
+	require.False(t, CheckPolicy(p, "CorrectHorse7"), "no symbol")
+	require.False(t, CheckPolicy(p, "Correct-Hooorse7"), "repeated character")
+	require.False(t, CheckPolicy(p, "Jsmith-Horse7", "j.smith@example.com", "John Smith"), "name part")
+	require.False(t, CheckPolicy(p, "Horse-J.Smith7", "j.smith@example.com"), "e-mail local part")
+	require.True(t, CheckPolicy(p, "Correct-Horse7", "jo@example.com", "Al"), "too short identity parts")
+
+	p.RequireSymbol = false
+	p.MaxRepeat = 0
+	require.True(t, CheckPolicy(p, "CorrectHooorse7"))
+}
+
+func TestBreached(t *testing.T) {
+	f, err := ioutil.TempFile("", "breached")
+	require.NoError(t, err)
+	defer os.Remove(f.Name())
+
+	var lines []string
+	for i := 0; i < 1000; i++ {
+		sum := sha1.Sum([]byte("Passw0rd-" + strconv.Itoa(i*2))) //nolint:gosec
+		lines = append(lines, strings.ToUpper(hex.EncodeToString(sum[:]))+":"+strconv.Itoa(i))
+	}
+	sort.Strings(lines)
+	_, err = f.WriteString(strings.Join(lines, "\r\n") + "\r\n")
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	list, err := OpenBreached(f.Name())
+	require.NoError(t, err)
+	for i := 0; i < 2000; i++ {
+		found, err := list.Contains("Passw0rd-" + strconv.Itoa(i))
+		require.NoError(t, err)
+		require.Equal(t, i%2 == 0, found, i)
+	}
+
+	cfg.App.Password = cfg.PasswordPolicy{MinLength: 8, RequireDigit: true, BreachedFile: f.Name()}
+	defer func() { breached = nil }()
+	_, err = Check("Passw0rd-2020")
+	require.Error(t, err, "list isn't open")
+	require.NoError(t, Init())
+	ok, err := Check("Passw0rd-20")
+	require.NoError(t, err)
+	require.False(t, ok)
+	ok, err = Check("Passw0rd-2021")
+	require.NoError(t, err)
+	require.True(t, ok)
+}
+
+func TestOpenBreached(t *testing.T) {
+	f, err := ioutil.TempFile("", "breached")
+	require.NoError(t, err)
+	defer os.Remove(f.Name())
+	_, err = f.WriteString("invalid line\n")
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	_, err = OpenBreached(f.Name())
+	require.Error(t, err)
+	_, err = OpenBreached(f.Name() + ".missing")
+	require.Error(t, err)
+}
+
+func TestDescribe(t *testing.T) {
+	p := cfg.PasswordPolicy{MinLength: 12, RequireLower: true, RequireDigit: true, MaxRepeat: 3}
+	require.Equal(t, "Password must be at least 12 characters, contain one lowercase letter and one number"+
+		" and not repeat a character more than 3 times in a row", p.Describe())
+}
@@ -22,12 +22,15 @@ func NewController() controller.Ctrl {
 		Name:     "proxy",
 		Handlers: controller.HandlerList{account.Auth(false, []int{})},
 		Acts: []controller.Act{
-			{Method: "GET", Route: "/:id", Handlers: controller.HandlerList{proxyHandler.Get}},
-			{Method: "GET", Route: "/", Handlers: controller.HandlerList{proxyHandler.List}},
-			{Method: "DELETE", Route: "/:id", Handlers: controller.HandlerList{proxyHandler.Delete}},
-			{Method: "POST", Route: "/", Handlers: controller.HandlerList{proxyHandler.Create}},
-			{Method: "PUT", Route: "/:id", Handlers: controller.HandlerList{proxyHandler.Update}},
-			{Method: "HEAD", Route: "/:id", Handlers: controller.HandlerList{proxyHandler.Exist}},
+			{Method: "GET", Route: "/:id", Handlers: controller.HandlerList{proxyHandler.Get}, Scope: account.ScopeProxyRead},
+			{Method: "GET", Route: "/", Handlers: controller.HandlerList{proxyHandler.List}, Scope: account.ScopeProxyRead},
+			{Method: "DELETE", Route: "/:id", Handlers: controller.HandlerList{proxyHandler.Delete},
+				Scope: account.ScopeProxyWrite, Permission: account.PermProxyWrite},
+			{Method: "POST", Route: "/", Handlers: controller.HandlerList{proxyHandler.Create},
+				Scope: account.ScopeProxyWrite, Permission: account.PermProxyWrite},
+			{Method: "PUT", Route: "/:id", Handlers: controller.HandlerList{proxyHandler.Update},
+				Scope: account.ScopeProxyWrite, Permission: account.PermProxyWrite},
+			{Method: "HEAD", Route: "/:id", Handlers: controller.HandlerList{proxyHandler.Exist}, Scope: account.ScopeProxyRead},
 		},
 	}
 }
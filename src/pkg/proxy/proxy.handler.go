@@ -3,6 +3,7 @@ package proxy
 import (
 	"context"
 	"net/http"
+	"oko/pkg/audit"
 	"oko/pkg/e"
 	"oko/pkg/env"
 	"oko/pkg/ginapp"
@@ -68,6 +69,7 @@ func (p handler) Delete(c *gin.Context) {
 
 		return
 	}
+	audit.Record(c, audit.Event{Action: audit.ProxyDelete, TargetType: audit.TargetProxy, TargetID: proxyID})
 
 	types.SuccessEmptyResponse(c)
 }
@@ -107,6 +109,7 @@ func (p handler) Create(c *gin.Context) {
 		e.ErrorResponse(c, http.StatusInternalServerError, "Something went wrong")
 		return
 	}
+	audit.Record(c, audit.Event{Action: audit.ProxyCreate, TargetType: audit.TargetProxy, Details: request})
 	types.SuccessEmptyResponse(c)
 }
 
@@ -184,6 +187,8 @@ func (p handler) Update(c *gin.Context) {
 		e.ErrorResponse(c, http.StatusInternalServerError, "Something went wrong")
 		return
 	}
+	audit.Record(c, audit.Event{Action: audit.ProxyUpdate, TargetType: audit.TargetProxy, TargetID: proxyID,
+		Details: request})
 
 	types.SuccessEmptyResponse(c)
 }
@@ -0,0 +1,95 @@
+package redis
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Session is a record of per-account session registry, it is stored in hash sessions:<account id>
+// with session id as a field. Only hash of the session token is kept, sessions of jwt mode
+// are refresh token families and keep the family instead.
+type Session struct {
+	ID         string    `json:"id"`
+	TokenHash  string    `json:"token_hash,omitempty"`
+	Family     string    `json:"family,omitempty"`
+	AccountID  int       `json:"account_id"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	IP         string    `json:"ip"`
+	UserAgent  string    `json:"user_agent"`
+}
+
+func sessionsKey(accID int) string {
+	return fmt.Sprintf("sessions:%d", accID)
+}
+
+// SessionID returns public session identifier by token hash, so the hash itself is never shown.
+func SessionID(tokenHash string) string {
+	sum := sha256.Sum256([]byte(tokenHash))
+	return hex.EncodeToString(sum[:8])
+}
+
+// SaveSession stores the session, the registry lives as long as the most recent session.
+func SaveSession(s Session, seconds int32) error {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	if err = HSet(sessionsKey(s.AccountID), s.ID, data); err != nil {
+		return err
+	}
+	return Expire(sessionsKey(s.AccountID), seconds)
+}
+
+func GetSession(accID int, id string) (*Session, error) {
+	data, err := HGet(sessionsKey(accID), id)
+	if err != nil || len(data) == 0 {
+		return nil, err
+	}
+	s := &Session{}
+	if err = json.Unmarshal(data, s); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// GetSessions returns sessions seen during the last lifetime seconds, most recent first.
+// Outdated records are removed from the registry.
+func GetSessions(accID int, lifetime int) ([]Session, error) {
+	data, err := HGetAll(sessionsKey(accID))
+	if err != nil {
+		return nil, err
+	}
+	expired := time.Now().Add(-time.Duration(lifetime) * time.Second)
+	res := make([]Session, 0, len(data))
+	outdated := make([]interface{}, 0)
+	for id, raw := range data {
+		var s Session
+		if err = json.Unmarshal([]byte(raw), &s); err != nil || s.LastSeenAt.Before(expired) {
+			outdated = append(outdated, id)
+			continue
+		}
+		res = append(res, s)
+	}
+	if len(outdated) > 0 {
+		if err = HDel(sessionsKey(accID), outdated); err != nil {
+			return nil, err
+		}
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].LastSeenAt.After(res[j].LastSeenAt)
+	})
+	return res, nil
+}
+
+func DeleteSession(accID int, id string) error {
+	return HDel(sessionsKey(accID), []interface{}{id})
+}
+
+func DeleteSessions(accID int) error {
+	return Delete(sessionsKey(accID))
+}
@@ -62,7 +62,7 @@ func appendArgs(dst, src []interface{}) []interface{} {
 }
 
 func SAdd(key string, members []interface{}) error {
-	args := make([]interface{}, 2, 2+len(members))
+	args := make([]interface{}, 1, 1+len(members))
 	args[0] = key
 	args = appendArgs(args, members)
 
@@ -103,6 +103,29 @@ func SetEx(key string, value []byte, seconds int32) error {
 	return err
 }
 
+// SetNX sets the key only if it doesn't exist, returns false if the key was already set.
+func SetNX(key string, value []byte, seconds int32) (bool, error) {
+	conn := Pool.Get()
+	defer conn.Close()
+
+	res, err := conn.Do("SET", key, value, "EX", seconds, "NX")
+	if err != nil {
+		return false, fmt.Errorf("error setting nx key %s: %v", key, err)
+	}
+	return res != nil, nil
+}
+
+func Expire(key string, seconds int32) error {
+	conn := Pool.Get()
+	defer conn.Close()
+
+	_, err := conn.Do("EXPIRE", key, seconds)
+	if err != nil {
+		return fmt.Errorf("error setting expire of key %s: %v", key, err)
+	}
+	return err
+}
+
 func Exists(key string) (bool, error) {
 	conn := Pool.Get()
 	defer conn.Close()
@@ -114,6 +137,19 @@ func Exists(key string) (bool, error) {
 	return ok, err
 }
 
+// TTL returns remaining time to live of the key in seconds, negative values are returned
+// for missing key or key without expiration.
+func TTL(key string) (int, error) {
+	conn := Pool.Get()
+	defer conn.Close()
+
+	ttl, err := redis.Int(conn.Do("TTL", key))
+	if err != nil {
+		return ttl, fmt.Errorf("error getting TTL of key %s: %v", key, err)
+	}
+	return ttl, err
+}
+
 func Delete(key string) error {
 	conn := Pool.Get()
 	defer conn.Close()
@@ -152,3 +188,55 @@ func Incr(counterKey string) (int, error) {
 
 	return redis.Int(conn.Do("INCR", counterKey))
 }
+
+func HSet(key, field string, value []byte) error {
+	conn := Pool.Get()
+	defer conn.Close()
+
+	_, err := conn.Do("HSET", key, field, value)
+	if err != nil {
+		return fmt.Errorf("error setting hash field %s of key %s: %v", field, key, err)
+	}
+	return err
+}
+
+func HGet(key, field string) ([]byte, error) {
+	conn := Pool.Get()
+	defer conn.Close()
+
+	data, err := redis.Bytes(conn.Do("HGET", key, field))
+	if err != nil {
+		if err == redis.ErrNil {
+			err = nil
+		} else {
+			return data, fmt.Errorf("error getting hash field %s of key %s: %v", field, key, err)
+		}
+	}
+	return data, err
+}
+
+func HGetAll(key string) (map[string]string, error) {
+	conn := Pool.Get()
+	defer conn.Close()
+
+	data, err := redis.StringMap(conn.Do("HGETALL", key))
+	if err != nil {
+		return data, fmt.Errorf("error getting hash %s: %v", key, err)
+	}
+	return data, err
+}
+
+func HDel(key string, fields []interface{}) error {
+	args := make([]interface{}, 1, 1+len(fields))
+	args[0] = key
+	args = appendArgs(args, fields)
+
+	conn := Pool.Get()
+	defer conn.Close()
+
+	_, err := conn.Do("HDEL", args...)
+	if err != nil {
+		return fmt.Errorf("error deleting hash fields of key %s: %v", key, err)
+	}
+	return err
+}
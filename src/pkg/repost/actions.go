@@ -6,6 +6,7 @@ import (
 	"oko/pkg/account"
 	"oko/pkg/e"
 	"oko/pkg/ginapp/types"
+	"oko/pkg/org"
 	"strings"
 
 	"github.com/gin-gonic/gin"
@@ -49,11 +50,14 @@ func (h *repostHandler) NewRequest(c *gin.Context) {
 
 	model, err := h.repository.GetOrNil(model)
 	if model != nil {
-		for _, a := range model.Accounts {
-			if a.ID == acc.ID {
-				e.ErrorResponse(c, http.StatusBadRequest, "Repost request already exist")
-				return
-			}
+		assigned, assignErr := h.repository.AssignedTo(model, org.GetID(c))
+		if assignErr != nil {
+			e.ErrorResponse(c, http.StatusInternalServerError, "Fail to create new repost request")
+			return
+		}
+		if assigned {
+			e.ErrorResponse(c, http.StatusBadRequest, "Repost request already exist")
+			return
 		}
 	}
 
@@ -73,7 +77,7 @@ func (h *repostHandler) NewRequest(c *gin.Context) {
 			Level: 1,
 		}
 	}
-	if err := h.repository.CreateAndAssign(model, acc); err != nil {
+	if err := h.repository.CreateAndAssign(model, acc, org.GetID(c)); err != nil {
 		e.ErrorResponse(c, http.StatusInternalServerError, "Fail to create new repost request")
 		return
 	}
@@ -112,9 +116,7 @@ func (h *repostHandler) View(c *gin.Context) {
 		e.ErrorResponse(c, http.StatusBadRequest, err)
 		return
 	}
-	get, _ := c.Get("account_id")
-
-	model, err := h.repository.GetWithDate(form.URL, get.(int), form.DateFrom, form.DateTo)
+	model, err := h.repository.GetWithDate(form.URL, org.GetID(c), form.DateFrom, form.DateTo)
 	if err != nil {
 		e.ErrorResponse(c, http.StatusInternalServerError, "Something went wrong")
 		return
@@ -154,9 +156,7 @@ func (h *repostHandler) List(c *gin.Context) {
 		return
 	}
 
-	accID, _ := c.Get("account_id")
-
-	models, count, err := h.repository.List(form.PerPage, form.CurrentPage, 1, accID.(int), "")
+	models, count, err := h.repository.List(form.PerPage, form.CurrentPage, 1, org.GetID(c), "")
 	if err != nil {
 		e.ErrorResponse(c, http.StatusBadRequest, "Something went wrong")
 		return
@@ -4,6 +4,7 @@ import (
 	"oko/pkg/account"
 	"oko/pkg/db"
 	"oko/pkg/ginapp/controller"
+	"oko/pkg/org"
 
 	"github.com/gin-gonic/gin"
 )
@@ -20,12 +21,13 @@ func NewController() controller.Ctrl {
 	handler := NewHandler(repository)
 	return controller.Ctrl{
 		Name:     "repost",
-		Handlers: controller.HandlerList{account.Auth(true, []int{})},
+		Handlers: controller.HandlerList{account.Auth(true, []int{}), org.Active()},
 		Acts: []controller.Act{
-			{Method: "POST", Route: "/", Handlers: []gin.HandlerFunc{handler.NewRequest}},
-			{Method: "GET", Route: "/view", Handlers: []gin.HandlerFunc{handler.View}},
-			{Method: "GET", Route: "/", Handlers: []gin.HandlerFunc{handler.List}},
-			{Method: "GET", Route: "/export", Handlers: controller.HandlerList{handler.Export}},
+			{Method: "POST", Route: "/", Handlers: []gin.HandlerFunc{handler.NewRequest}, Scope: account.ScopeRepostWrite},
+			{Method: "GET", Route: "/view", Handlers: []gin.HandlerFunc{handler.View}, Scope: account.ScopeRepostRead},
+			{Method: "GET", Route: "/", Handlers: []gin.HandlerFunc{handler.List}, Scope: account.ScopeRepostRead},
+			{Method: "GET", Route: "/export", Handlers: controller.HandlerList{handler.Export},
+				Scope: account.ScopeRepostExport, Permission: account.PermRepostExport},
 		},
 	}
 }
@@ -4,7 +4,9 @@ import (
 	"bytes"
 	"encoding/csv"
 	"net/http"
+	"oko/pkg/audit"
 	"oko/pkg/e"
+	"oko/pkg/org"
 
 	"github.com/gin-gonic/gin"
 )
@@ -32,9 +34,7 @@ func (h *repostHandler) Export(c *gin.Context) {
 		return
 	}
 
-	accID, _ := c.Get("account_id")
-
-	export, err := h.repository.GetForExport(r.URL, accID.(int), r.DateFrom, r.DateTo)
+	export, err := h.repository.GetForExport(r.URL, org.GetID(c), r.DateFrom, r.DateTo)
 	if err != nil {
 		e.ErrorResponse(c, http.StatusInternalServerError, "Error fetch datas for export")
 		return
@@ -44,6 +44,12 @@ func (h *repostHandler) Export(c *gin.Context) {
 		e.ErrorResponse(c, http.StatusInternalServerError, "Fail get export records")
 		return
 	}
+	audit.Record(c, audit.Event{Action: audit.RepostExport, TargetType: audit.TargetRepost, Details: map[string]interface{}{
+		"url":       r.URL,
+		"date_from": r.DateFrom,
+		"date_to":   r.DateTo,
+		"records":   len(export),
+	}})
 	extraHeaders := map[string]string{
 		"Content-Disposition": `attachment; filename="export.csv"`,
 	}
@@ -10,18 +10,20 @@ import (
 	"github.com/jinzhu/gorm"
 )
 
+// Repository methods which take orgID return requests assigned to the organization only.
 type Repository interface {
-	List(limit uint32, page uint32, maxLevel uint32, accountID int, hasProcessed string) (models []*Request,
+	List(limit uint32, page uint32, maxLevel uint32, orgID uint, hasProcessed string) (models []*Request,
 		count uint32, err error)
 	ListForParser(limit, page, maxLevel uint32, hasProcessed string) (models []*Request, count uint32, err error)
 	Get(model *Request) (*Request, error)
 	Create(model *Request) error
 	Exist(model *Request) (bool, error)
 	Update(id uint, model interface{}) error
-	GetWithDate(url string, accountID int, dateFrom, dateTo *time.Time) (*Request, error)
-	GetForExport(url string, accID int, from, to *time.Time) ([]RecordForExport, error)
+	GetWithDate(url string, orgID uint, dateFrom, dateTo *time.Time) (*Request, error)
+	GetForExport(url string, orgID uint, from, to *time.Time) ([]RecordForExport, error)
 	GetOrNil(*Request) (*Request, error)
-	CreateAndAssign(*Request, account.Account) error
+	AssignedTo(req *Request, orgID uint) (bool, error)
+	CreateAndAssign(req *Request, acc account.Account, orgID uint) error
 }
 
 const trueStr = "true"
@@ -110,7 +112,7 @@ func (repo *requestRepository) ListForParser(limit, page, maxLevel uint32, hasPr
 
 	return
 }
-func (repo *requestRepository) List(limit, page, maxLevel uint32, accountID int, hasProcessed string) (
+func (repo *requestRepository) List(limit, page, maxLevel uint32, orgID uint, hasProcessed string) (
 	models []*Request, count uint32, err error) {
 	var offset uint32
 	if page > 1 {
@@ -124,8 +126,8 @@ func (repo *requestRepository) List(limit, page, maxLevel uint32, accountID int,
 		Preload("Accounts").
 		Order("created_at asc").
 		Where("level <= ?", maxLevel).
-		Joins("join account_repost_request arr on arr.request_id = repost_request.id").
-		Where("arr.account_id = ?", accountID).
+		Where(`exists (select 1 from account_repost_request arr
+			where arr.request_id = repost_request.id and arr.organization_id = ?)`, orgID).
 		Offset(offset).
 		Limit(limit)
 
@@ -136,7 +138,7 @@ func (repo *requestRepository) List(limit, page, maxLevel uint32, accountID int,
 		query = query.Where("has_processed is ?", hasProcessed)
 	}
 
-	repo.db.Model(&Request{}).Where("level <= ?", maxLevel).Count(&count)
+	query.Model(&Request{}).Offset(-1).Limit(-1).Count(&count)
 
 	err = query.Find(&models).Error
 
@@ -175,11 +177,11 @@ func (repo *requestRepository) Update(id uint, model interface{}) error {
 	return nil
 }
 
-func (repo *requestRepository) GetWithDate(url string, accountID int, dateFrom, dateTo *time.Time) (*Request, error) {
+func (repo *requestRepository) GetWithDate(url string, orgID uint, dateFrom, dateTo *time.Time) (*Request, error) {
 	var model Request
 	var err error
-	if !repo.haveAccess(url, accountID) {
-		log.Printf("Error in RequestRepository.GetWithDate access denied to %s for %d", url, accountID)
+	if !repo.haveAccess(url, orgID) {
+		log.Printf("Error in RequestRepository.GetWithDate access denied to %s for organization %d", url, orgID)
 		return nil, errors.New("not found")
 	}
 	preload := repo.db.Preload("Links").Where("(repost_request.url = ? or repost_request.url = ?)", url,
@@ -205,7 +207,8 @@ func (repo *requestRepository) GetWithDate(url string, accountID int, dateFrom,
 	return &model, nil
 }
 
-func (repo *requestRepository) haveAccess(url string, accID int) bool {
+// haveAccess reports if the request, one of its ancestors or descendants is assigned to the organization.
+func (repo *requestRepository) haveAccess(url string, orgID uint) bool {
 	row := repo.db.Raw(`WITH RECURSIVE
     starting (id, url, parent_id) AS
         (
@@ -232,12 +235,12 @@ func (repo *requestRepository) haveAccess(url string, accID int) bool {
             FROM repost_request AS t
                      JOIN ancestors AS a ON t.id = a.parent_id
         )
-select distinct account_id
+select distinct organization_id
 from (TABLE ancestors
       UNION ALL
       TABLE descendants) as res
-         join account_repost_request on account_repost_request.request_id = res.id and account_id = ?`, url,
-		util.URLEncoded(url), accID).Row()
+         join account_repost_request on account_repost_request.request_id = res.id and organization_id = ?`, url,
+		util.URLEncoded(url), orgID).Row()
 	tmp := -1
 	if err := row.Scan(&tmp); err != nil {
 		return false
@@ -245,11 +248,11 @@ from (TABLE ancestors
 	return true
 }
 
-func (repo *requestRepository) GetForExport(url string, accID int, from, to *time.Time) ([]RecordForExport, error) {
+func (repo *requestRepository) GetForExport(url string, orgID uint, from, to *time.Time) ([]RecordForExport, error) {
 	args := make([]interface{}, 0, 3)
 	args = append(args, url)
-	if !repo.haveAccess(url, accID) {
-		log.Printf("Error in RequestRepository.GetForExport access denied to %s for %d", url, accID)
+	if !repo.haveAccess(url, orgID) {
+		log.Printf("Error in RequestRepository.GetForExport access denied to %s for organization %d", url, orgID)
 		return nil, errors.New("access denied")
 	}
 	sql := `WITH RECURSIVE nodes(id, parent_id) AS (
@@ -289,7 +292,16 @@ FROM nodes n
 	err := raw.Scan(&exportRecs).Error
 	return exportRecs, err
 }
-func (repo *requestRepository) CreateAndAssign(req *Request, acc account.Account) error {
+func (repo *requestRepository) AssignedTo(req *Request, orgID uint) (bool, error) {
+	var count int
+	err := repo.db.Table("account_repost_request").
+		Where("request_id = ? and organization_id = ?", req.ID, orgID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// CreateAndAssign creates the request if it is new and assigns it to the account in the organization.
+func (repo *requestRepository) CreateAndAssign(req *Request, acc account.Account, orgID uint) error {
 	tx := repo.db.Begin()
 	if err := tx.Error; err != nil {
 		return err
@@ -300,7 +312,10 @@ func (repo *requestRepository) CreateAndAssign(req *Request, acc account.Account
 			return err
 		}
 	}
-	if err := tx.Model(req).Association("Accounts").Append(acc).Error; err != nil {
+	if err := tx.Exec(
+		"insert into account_repost_request (account_id, request_id, organization_id) values (?, ?, ?)",
+		acc.ID, req.ID, orgID,
+	).Error; err != nil {
 		tx.Rollback()
 		return err
 	}
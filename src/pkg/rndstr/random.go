@@ -1,31 +1,33 @@
 package rndstr
 
 import (
-	"math/rand"
-	"time"
+	"crypto/rand"
 )
 
 const (
 	letterBytes   = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ"
 	letterIdxBits = 6                    // 6 bits to represent a letter index
 	letterIdxMask = 1<<letterIdxBits - 1 // All 1-bits, as many as letterIdxBits
-	letterIdxMax  = 63 / letterIdxBits   // # of letter indices fitting in 63 bits
 )
 
+// RandString returns random string of letters read from crypto/rand, so it's suitable for secrets.
 func RandString(n int) string {
-	src := rand.NewSource(time.Now().UnixNano())
 	b := make([]byte, n)
-	// A src.Int63() generates 63 random bits, enough for letterIdxMax characters!
-	for i, cache, remain := n-1, src.Int63(), letterIdxMax; i >= 0; {
-		if remain == 0 {
-			cache, remain = src.Int63(), letterIdxMax
+	buf := make([]byte, n)
+	for i := 0; i < n; {
+		if _, err := rand.Read(buf); err != nil {
+			panic(err)
 		}
-		if idx := int(cache & letterIdxMask); idx < len(letterBytes) {
-			b[i] = letterBytes[idx]
-			i--
+		// indexes out of letters are skipped, so every letter has the same probability
+		for _, c := range buf {
+			if idx := int(c & letterIdxMask); idx < len(letterBytes) {
+				b[i] = letterBytes[idx]
+				i++
+				if i == n {
+					break
+				}
+			}
 		}
-		cache >>= letterIdxBits
-		remain--
 	}
 
 	return string(b)
@@ -0,0 +1,19 @@
+package rndstr
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRandString(t *testing.T) {
+	require.Equal(t, "", RandString(0))
+
+	s := RandString(1000)
+	require.Len(t, s, 1000)
+	for _, c := range s {
+		require.True(t, strings.ContainsRune(letterBytes, c), "unexpected letter %q", c)
+	}
+	require.NotEqual(t, s, RandString(1000))
+}
@@ -3,9 +3,12 @@ package rss
 import (
 	"oko/pkg/account"
 	"oko/pkg/db"
+	"oko/pkg/feed"
 	"oko/pkg/ginapp/controller"
+	"oko/pkg/org"
 
 	"github.com/gin-gonic/gin"
+	"github.com/jinzhu/gorm"
 )
 
 type Handler interface {
@@ -14,19 +17,32 @@ type Handler interface {
 	Delete(c *gin.Context)
 	List(c *gin.Context)
 	Get(c *gin.Context)
+	Import(c *gin.Context)
+	Export(c *gin.Context)
 }
 
-func NewController() controller.Ctrl {
-	handler := rssHandler{NewRssRepository(db.GetDB())}
+// DomainResolver finds domain of the organization by name creating missing one in the transaction,
+// it's implemented by domain repository which imports this package.
+type DomainResolver interface {
+	ResolveDomain(tx *gorm.DB, orgID uint, name string) (id uint, created bool, err error)
+}
+
+func NewController(domains DomainResolver) controller.Ctrl {
+	handler := rssHandler{
+		rep:     NewRssRepository(db.GetDB()),
+		prober:  &feed.Client{HTTP: feed.PublicClient(probeTimeout)},
+		domains: domains,
+	}
 	return controller.Ctrl{
 		Name:     "rss",
-		Handlers: controller.HandlerList{account.Auth(false, []int{})},
+		Handlers: controller.HandlerList{account.Auth(true, []int{}), org.Active()},
 		Acts: []controller.Act{
-			{Method: "GET", Route: "/", Handlers: []gin.HandlerFunc{handler.List}},
-			{Method: "GET", Route: "/:id", Handlers: []gin.HandlerFunc{handler.Get}},
-			{Method: "PUT", Route: "/", Handlers: []gin.HandlerFunc{handler.Update}},
-			{Method: "DELETE", Route: "/:id", Handlers: []gin.HandlerFunc{handler.Delete}},
-			{Method: "POST", Route: "/", Handlers: []gin.HandlerFunc{handler.Create}},
+			{Method: "GET", Route: "/", Handlers: []gin.HandlerFunc{handler.List}, Scope: account.ScopeRssRead},
+			{Method: "GET", Route: "/:id", Handlers: []gin.HandlerFunc{handler.Get}, Scope: account.ScopeRssRead},
+			{Method: "PUT", Route: "/", Handlers: []gin.HandlerFunc{handler.Update}, Scope: account.ScopeRssWrite},
+			{Method: "DELETE", Route: "/:id", Handlers: []gin.HandlerFunc{handler.Delete}, Scope: account.ScopeRssWrite},
+			{Method: "POST", Route: "/", Handlers: []gin.HandlerFunc{handler.Create}, Scope: account.ScopeRssWrite},
+			{Method: "POST", Route: "/import", Handlers: []gin.HandlerFunc{handler.Import}, Scope: account.ScopeRssWrite},
 		},
 	}
 }
@@ -0,0 +1,93 @@
+package rss
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"oko/pkg/feed"
+	"strings"
+	"time"
+)
+
+// hostTimeout limits resolving of the feed host.
+const hostTimeout = 5 * time.Second
+
+var (
+	errNotFeed     = errors.New("link is not a feed")
+	errForeignHost = errors.New("feed host does not belong to the domain")
+	errScheme      = errors.New("feed link is not http url")
+)
+
+// feedProber checks links given by users, it's feed.Client in production.
+type feedProber interface {
+	Probe(ctx context.Context, link string) (*feed.Probe, error)
+}
+
+// resolveFeed returns url of the feed the link points to. For html page feeds discovered on it
+// are tried in order and returned as candidates. Feed host must belong to the domain unless anyHost.
+func resolveFeed(ctx context.Context, prober feedProber, link, domainName string, anyHost bool) (
+	feedURL string, candidates []feed.Candidate, err error) {
+	p, err := prober.Probe(ctx, link)
+	if err != nil {
+		return "", nil, err
+	}
+	if p.Feed != nil {
+		if !anyHost && !hostBelongs(p.URL, domainName) {
+			return "", nil, errForeignHost
+		}
+		return p.URL, nil, nil
+	}
+
+	err = errNotFeed
+	for _, cand := range p.Candidates {
+		if !anyHost && !hostBelongs(cand.URL, domainName) {
+			err = errForeignHost
+			continue
+		}
+		cp, perr := prober.Probe(ctx, cand.URL)
+		if perr != nil || cp.Feed == nil {
+			continue
+		}
+		return cp.URL, p.Candidates, nil
+	}
+	return "", p.Candidates, err
+}
+
+// CheckLinkHost returns feed.ErrForbidden if host of the feed link isn't public, links stored
+// without probing are checked by it, so they follow the policy of the fetch client.
+func CheckLinkHost(ctx context.Context, link string) error {
+	u, err := url.Parse(link)
+	if err != nil {
+		return err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return errScheme
+	}
+	return checkHost(ctx, u.Hostname())
+}
+
+// checkHost is feed.CheckHost limited by hostTimeout.
+func checkHost(ctx context.Context, host string) error {
+	ctx, cancel := context.WithTimeout(ctx, hostTimeout)
+	defer cancel()
+	return feed.CheckHost(ctx, host)
+}
+
+// hostBelongs reports if host of the link is the domain or its subdomain, www prefix is ignored.
+func hostBelongs(link, domainName string) bool {
+	u, err := url.Parse(link)
+	if err != nil {
+		return false
+	}
+	name := strings.ToLower(strings.TrimSpace(domainName))
+	if strings.Contains(name, "://") {
+		du, err := url.Parse(name)
+		if err != nil {
+			return false
+		}
+		name = du.Hostname()
+	}
+	name = strings.TrimPrefix(strings.TrimSuffix(name, "/"), "www.")
+	host := strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+	return name != "" && (host == name || strings.HasSuffix(host, "."+name))
+}
@@ -0,0 +1,73 @@
+package rss
+
+import (
+	"context"
+	"oko/pkg/feed"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type fakeProber map[string]*feed.Probe
+
+func (p fakeProber) Probe(_ context.Context, link string) (*feed.Probe, error) {
+	if res, ok := p[link]; ok {
+		return res, nil
+	}
+	return nil, feed.StatusError{Code: 404}
+}
+
+func TestHostBelongs(t *testing.T) {
+	require.True(t, hostBelongs("https://example.com/rss", "example.com"))
+	require.True(t, hostBelongs("https://www.example.com/rss", "Example.com"))
+	require.True(t, hostBelongs("https://blog.example.com/rss", "www.example.com"))
+	require.True(t, hostBelongs("http://example.com:8080/rss", "https://example.com/"))
+	require.False(t, hostBelongs("https://badexample.com/rss", "example.com"))
+	require.False(t, hostBelongs("https://example.com.evil.net/rss", "example.com"))
+	require.False(t, hostBelongs("https://example.com/rss", ""))
+}
+
+func TestResolveFeed(t *testing.T) {
+	prober := fakeProber{
+		"https://example.com/rss": {URL: "https://example.com/rss", Feed: &feed.Feed{}},
+		"https://example.com/": {URL: "https://example.com/", Candidates: []feed.Candidate{
+			{URL: "https://feeds.partner.net/example", Title: "Partner"},
+			{URL: "https://example.com/missing", Title: "Gone"},
+			{URL: "https://example.com/rss", Title: "Posts"},
+		}},
+		"https://feeds.partner.net/example": {URL: "https://feeds.partner.net/example", Feed: &feed.Feed{}},
+		"https://example.com/about": {URL: "https://example.com/about", Candidates: []feed.Candidate{
+			{URL: "https://feeds.partner.net/example"},
+		}},
+	}
+	ctx := context.Background()
+
+	link, candidates, err := resolveFeed(ctx, prober, "https://example.com/rss", "example.com", false)
+	require.NoError(t, err)
+	require.Equal(t, "https://example.com/rss", link)
+	require.Empty(t, candidates)
+
+	link, candidates, err = resolveFeed(ctx, prober, "https://example.com/", "example.com", false)
+	require.NoError(t, err)
+	require.Equal(t, "https://example.com/rss", link)
+	require.Len(t, candidates, 3)
+
+	link, _, err = resolveFeed(ctx, prober, "https://example.com/", "example.com", true)
+	require.NoError(t, err)
+	require.Equal(t, "https://feeds.partner.net/example", link)
+
+	_, _, err = resolveFeed(ctx, prober, "https://example.com/about", "example.com", false)
+	require.Equal(t, errForeignHost, err)
+
+	_, _, err = resolveFeed(ctx, prober, "https://feeds.partner.net/example", "example.com", false)
+	require.Equal(t, errForeignHost, err)
+}
+
+func TestCheckLinkHost(t *testing.T) {
+	ctx := context.Background()
+	require.Equal(t, feed.ErrForbidden, CheckLinkHost(ctx, "http://localhost:8080/rss"))
+	require.Equal(t, feed.ErrForbidden, CheckLinkHost(ctx, "https://192.168.1.10/feed.xml"))
+	require.Equal(t, feed.ErrForbidden, CheckLinkHost(ctx, "http://[::1]/rss"))
+	require.Equal(t, errScheme, CheckLinkHost(ctx, "file:///etc/passwd"))
+	require.NoError(t, CheckLinkHost(ctx, "https://93.184.216.34/rss"))
+}
@@ -1,12 +1,15 @@
 package rss
 
 type CreateForm struct {
-	DomainID uint   `json:"domain_id" form:"domain_id"`
-	RssLink  string `json:"rss_link" form:"rss_link"`
+	DomainID uint   `json:"domain_id" form:"domain_id" binding:"required"`
+	RssLink  string `json:"rss_link" form:"rss_link" binding:"required,url,max=2048"`
+	// AllowForeignHost skips check that feed host belongs to the domain
+	AllowForeignHost bool `json:"allow_foreign_host" form:"allow_foreign_host"`
 }
 
 type UpdateForm struct {
-	DomainID uint   `json:"domain_id" form:"domain_id"`
-	RssLink  string `json:"rss_link" form:"rss_link"`
-	RssID    uint   `json:"rss_id" form:"rss_id"`
+	DomainID         uint   `json:"domain_id" form:"domain_id" binding:"required"`
+	RssLink          string `json:"rss_link" form:"rss_link" binding:"required,url,max=2048"`
+	RssID            uint   `json:"rss_id" form:"rss_id" binding:"required"`
+	AllowForeignHost bool   `json:"allow_foreign_host" form:"allow_foreign_host"`
 }
@@ -1,69 +1,161 @@
 package rss
 
 import (
+	"context"
 	"net/http"
 	"oko/pkg/e"
+	"oko/pkg/feed"
 	"oko/pkg/ginapp"
 	"oko/pkg/ginapp/types"
+	"oko/pkg/log"
+	"oko/pkg/org"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
+// probeTimeout limits checking of the link given by user.
+const probeTimeout = 15 * time.Second
+
 type rssHandler struct {
-	rep *rssRepository
+	rep     *rssRepository
+	prober  feedProber
+	domains DomainResolver
+}
+
+func toCandidatesView(candidates []feed.Candidate) []types.FeedCandidate {
+	res := make([]types.FeedCandidate, 0, len(candidates))
+	for _, cand := range candidates {
+		res = append(res, types.FeedCandidate{
+			URL:   cand.URL,
+			Title: cand.Title,
+			Type:  cand.Type,
+		})
+	}
+	return res
+}
+
+func linkErrorResponse(c *gin.Context, field, message string) {
+	e.ErrorResponse(c, http.StatusBadRequest, e.CustomFieldError{
+		Name:    field,
+		Tag:     field,
+		Param:   "",
+		Value:   "",
+		Message: message,
+	})
+}
+
+// checkLink resolves feed of the link for the domain, error response is written on failure.
+func (r rssHandler) checkLink(c *gin.Context, domainID uint, link string, anyHost bool) (
+	feedURL string, candidates []feed.Candidate, ok bool) {
+	name, err := r.rep.domainName(org.GetID(c), domainID)
+	switch err {
+	case nil:
+	case errDomainNotFound:
+		linkErrorResponse(c, "domain_id", "Domain not found")
+		return
+	default:
+		log.Println("Error in rssHandler.checkLink", err)
+		e.ErrorResponse(c, http.StatusInternalServerError, "Something went wrong")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), probeTimeout)
+	defer cancel()
+	feedURL, candidates, err = resolveFeed(ctx, r.prober, link, name, anyHost)
+	switch err {
+	case nil:
+		return feedURL, candidates, true
+	case errForeignHost:
+		linkErrorResponse(c, "rss_link", "Feed host does not belong to the domain")
+	case errNotFeed, feed.ErrFormat:
+		linkErrorResponse(c, "rss_link", "Link is not a feed and page has no available feeds")
+	default:
+		log.Println("Fail to check rss link", link, err)
+		linkErrorResponse(c, "rss_link", "Link is not available")
+	}
+	return
 }
 
 // Create godoc
 // @Summary Create
-// @Description Create
+// @Description Create rss link, the link is fetched and must be a feed. For html page the first
+// @Description available feed advertised by the page is used, all of them are returned as candidates.
+// @Description Feed host must belong to the domain unless allow_foreign_host is set
 // @ID create-rss
 // @Tags Rss
 // @Accept json
 // @Produce json
-// @Param object body rss.CreateForm true "Rss update request fields"
-// @Success 200 {object} types.StdResponse
+// @Param object body rss.CreateForm true "Rss create request fields"
+// @Success 200 {object} types.ResponseRssSaved
 // @Failure 400 {object} types.ResponseErrorSwg
 // @Failure 500 {object} types.ResponseErrorSwg
 // @Router /rss [post]
+// @Security ApiKeyAuth
 func (r rssHandler) Create(c *gin.Context) {
-	req := CreateForm{}
-	if err := c.BindJSON(&req); err != nil {
-		e.ErrorResponse(c, http.StatusBadRequest, "Something went wrong")
+	var form CreateForm
+
+	if err := c.ShouldBind(&form); err != nil {
+		e.ErrorResponse(c, http.StatusBadRequest, err)
 		return
 	}
 
-	if err := r.rep.CreateRss(req.DomainID, req.RssLink); err != nil {
+	feedURL, candidates, ok := r.checkLink(c, form.DomainID, form.RssLink, form.AllowForeignHost)
+	if !ok {
+		return
+	}
+
+	model, err := r.rep.CreateRss(org.GetID(c), form.DomainID, feedURL)
+	if err != nil {
 		e.ErrorResponse(c, http.StatusInternalServerError, "Something went wrong")
 		return
 	}
-	types.SuccessEmptyResponse(c)
+
+	types.SuccessResponse(c, types.RssSaved{
+		ID:         model.ID,
+		DomainID:   model.DomainID,
+		Link:       model.Link,
+		Candidates: toCandidatesView(candidates),
+	})
 }
 
 // Update godoc
 // @Summary Update
-// @Description Update
+// @Description Update rss link, the link is checked as on create
 // @ID update-rss
 // @Tags Rss
 // @Accept json
 // @Produce json
 // @Param object body rss.UpdateForm true "Rss update request fields"
-// @Success 200 {object} types.StdResponse
+// @Success 200 {object} types.ResponseRssSaved
 // @Failure 400 {object} types.ResponseErrorSwg
 // @Failure 500 {object} types.ResponseErrorSwg
 // @Router /rss [put]
+// @Security ApiKeyAuth
 func (r rssHandler) Update(c *gin.Context) {
-	req := UpdateForm{}
-	if err := c.BindJSON(&req); err != nil {
-		e.ErrorResponse(c, http.StatusBadRequest, "Something went wrong")
+	var form UpdateForm
+
+	if err := c.ShouldBind(&form); err != nil {
+		e.ErrorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	feedURL, candidates, ok := r.checkLink(c, form.DomainID, form.RssLink, form.AllowForeignHost)
+	if !ok {
 		return
 	}
 
-	if err := r.rep.UpdateRss(req.DomainID, req.RssLink, req.RssID); err != nil {
+	if err := r.rep.UpdateRss(org.GetID(c), form.DomainID, feedURL, form.RssID); err != nil {
 		e.ErrorResponse(c, http.StatusInternalServerError, "Something went wrong")
 		return
 	}
 
-	types.SuccessEmptyResponse(c)
+	types.SuccessResponse(c, types.RssSaved{
+		ID:         form.RssID,
+		DomainID:   form.DomainID,
+		Link:       feedURL,
+		Candidates: toCandidatesView(candidates),
+	})
 }
 
 // Delete godoc
@@ -78,6 +170,7 @@ func (r rssHandler) Update(c *gin.Context) {
 // @Failure 400 {object} types.ResponseErrorSwg
 // @Failure 500 {object} types.ResponseErrorSwg
 // @Router /rss/{id} [delete]
+// @Security ApiKeyAuth
 func (r rssHandler) Delete(c *gin.Context) {
 	rssID, err := ginapp.GetUint32PathParam(c, "id")
 	if err != nil {
@@ -85,7 +178,7 @@ func (r rssHandler) Delete(c *gin.Context) {
 		return
 	}
 
-	if err = r.rep.DeleteRss(uint(rssID)); err != nil {
+	if err = r.rep.DeleteRss(org.GetID(c), uint(rssID)); err != nil {
 		e.ErrorResponse(c, http.StatusBadRequest, "Something went wrong")
 		return
 	}
@@ -104,8 +197,9 @@ func (r rssHandler) Delete(c *gin.Context) {
 // @Failure 400 {object} types.ResponseErrorSwg
 // @Failure 500 {object} types.ResponseErrorSwg
 // @Router /rss [get]
+// @Security ApiKeyAuth
 func (r rssHandler) List(c *gin.Context) {
-	list, err := r.rep.List()
+	list, err := r.rep.List(org.GetID(c))
 	if err != nil {
 		e.ErrorResponse(c, http.StatusInternalServerError, "Something went wrong")
 		return
@@ -116,7 +210,7 @@ func (r rssHandler) List(c *gin.Context) {
 
 // Get godoc
 // @Summary Get
-// @Description Get
+// @Description Get rss link with result of the last fetch and time of the next one (NextFetchAt)
 // @ID get-rss
 // @Tags Rss
 // @Accept json
@@ -126,13 +220,20 @@ func (r rssHandler) List(c *gin.Context) {
 // @Failure 400 {object} types.ResponseErrorSwg
 // @Failure 500 {object} types.ResponseErrorSwg
 // @Router /rss/{id} [get]
+// @Security ApiKeyAuth
 func (r rssHandler) Get(c *gin.Context) {
+	// gin can't route static /export next to /:id
+	if c.Param("id") == exportRoute {
+		r.Export(c)
+		return
+	}
+
 	id, err := ginapp.GetUint32PathParam(c, "id")
 	if err != nil {
 		e.ErrorResponse(c, http.StatusBadRequest, "Something went wrong")
 		return
 	}
-	rss, err := r.rep.Get(uint(id))
+	rss, err := r.rep.Get(org.GetID(c), uint(id))
 	if err != nil {
 		e.ErrorResponse(c, http.StatusInternalServerError, "Something went wrong")
 		return
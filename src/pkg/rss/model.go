@@ -1,13 +1,40 @@
 package rss
 
 import (
+	"time"
+
 	"github.com/jinzhu/gorm"
 )
 
 type Rss struct {
 	gorm.Model
-	Link     string
-	DomainID uint `gorm:"foreignkey:DomainID"`
+	Link           string
+	DomainID       uint `gorm:"foreignkey:DomainID"`
+	OrganizationID uint `gorm:"column:organization_id"`
+
+	// result of the last fetch by rss fetcher
+	FetchedAt *time.Time `gorm:"column:fetched_at;default:'null'"`
+	ItemCount int        `gorm:"column:item_count"`
+	Error     string     `gorm:"column:error;default:'null'"`
+
+	// poll schedule state, validators are sent with conditional requests
+	ETag         string     `gorm:"column:etag"`
+	LastModified string     `gorm:"column:last_modified"`
+	AvgItemGap   int64      `gorm:"column:avg_item_gap"` // seconds, 0 if unknown
+	FailureCount int        `gorm:"column:failure_count"`
+	NextFetchAt  *time.Time `gorm:"column:next_fetch_at;default:'null'"`
+}
+
+// FetchResult is saved after each fetch of the feed, empty Error clears previous one.
+type FetchResult struct {
+	FetchedAt    time.Time
+	ItemCount    int
+	Error        string
+	ETag         string
+	LastModified string
+	AvgItemGap   time.Duration
+	FailureCount int
+	NextFetchAt  time.Time
 }
 
 func (r *Rss) TableName() string {
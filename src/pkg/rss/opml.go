@@ -0,0 +1,271 @@
+package rss
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"oko/pkg/audit"
+	"oko/pkg/e"
+	"oko/pkg/feed"
+	"oko/pkg/ginapp/types"
+	"oko/pkg/log"
+	"oko/pkg/opml"
+	"oko/pkg/org"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	exportRoute    = "export"
+	maxImportSize  = 5 << 20
+	maxImportFeeds = 1000
+	// importTimeout limits checking of all feed hosts of the file.
+	importTimeout = 30 * time.Second
+	// importResolvers is number of feed hosts checked at once.
+	importResolvers = 8
+)
+
+// importEntry is feed of the file to create, domain is the host name without www prefix.
+type importEntry struct {
+	link   string
+	host   string
+	domain string
+}
+
+// importLink returns entry with normalized feed url.
+func importLink(link string) (importEntry, bool) {
+	u, err := url.Parse(link)
+	if err != nil || u.Scheme != "http" && u.Scheme != "https" || u.Hostname() == "" {
+		return importEntry{}, false
+	}
+	u.Fragment = ""
+	host := strings.ToLower(u.Hostname())
+	return importEntry{link: u.String(), host: host, domain: strings.TrimPrefix(host, "www.")}, true
+}
+
+// checkImportHosts checks each of the hosts once by importResolvers at a time,
+// hosts left when ctx is done get its error.
+func checkImportHosts(ctx context.Context, hosts []string) map[string]error {
+	res := make(map[string]error, len(hosts))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	queue := make(chan string)
+	for i := 0; i < importResolvers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for host := range queue {
+				err := checkHost(ctx, host)
+				mu.Lock()
+				res[host] = err
+				mu.Unlock()
+			}
+		}()
+	}
+	for _, host := range hosts {
+		queue <- host
+	}
+	close(queue)
+	wg.Wait()
+	return res
+}
+
+// hostRejectReason describes error of the feed host check.
+func hostRejectReason(err error) string {
+	if err == feed.ErrForbidden {
+		return "Host is not public"
+	}
+	return "Host is not available"
+}
+
+// Import godoc
+// @Summary Import OPML
+// @Description Create rss links from OPML file, missing domains are created by host name of the feed.
+// @Description Feeds already added to the organization are skipped, feeds with invalid url or host which is not
+// @Description public are rejected.
+// @Description Feeds are created in one transaction, they are not fetched on import
+// @ID import-rss
+// @Tags Rss
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "OPML file"
+// @Success 200 {object} types.ResponseRssImport
+// @Failure 400 {object} types.ResponseErrorSwg
+// @Failure 500 {object} types.ResponseErrorSwg
+// @Router /rss/import [post]
+// @Security ApiKeyAuth
+func (r rssHandler) Import(c *gin.Context) {
+	header, err := c.FormFile("file")
+	if err != nil {
+		linkErrorResponse(c, "file", "OPML file is required")
+		return
+	}
+	if header.Size > maxImportSize {
+		linkErrorResponse(c, "file", "File is too large")
+		return
+	}
+	file, err := header.Open()
+	if err != nil {
+		e.ErrorResponse(c, http.StatusBadRequest, "Something went wrong")
+		return
+	}
+	defer file.Close()
+
+	feeds, err := opml.Parse(io.LimitReader(file, maxImportSize))
+	if err != nil {
+		linkErrorResponse(c, "file", "Invalid OPML file")
+		return
+	}
+	if len(feeds) > maxImportFeeds {
+		linkErrorResponse(c, "file", "Too many feeds in the file")
+		return
+	}
+
+	orgID := org.GetID(c)
+	report := types.RssImport{
+		CreatedDomains: []string{},
+		Created:        []types.RssImportItem{},
+		Skipped:        []types.RssImportItem{},
+		Rejected:       []types.RssImportItem{},
+	}
+	entries := make([]importEntry, 0, len(feeds))
+	links := make([]string, 0, len(feeds))
+	seen := make(map[string]bool, len(feeds))
+	for _, item := range feeds {
+		entry, ok := importLink(item.XMLURL)
+		if !ok {
+			report.Rejected = append(report.Rejected, types.RssImportItem{URL: item.XMLURL, Reason: "Invalid url"})
+			continue
+		}
+		if seen[entry.link] {
+			report.Skipped = append(report.Skipped, types.RssImportItem{URL: entry.link, Reason: "Repeated in the file"})
+			continue
+		}
+		seen[entry.link] = true
+		entries = append(entries, entry)
+		links = append(links, entry.link)
+	}
+
+	existing, err := r.rep.existingLinks(orgID, links)
+	if err != nil {
+		log.Println("Error in rssHandler.Import", err)
+		e.ErrorResponse(c, http.StatusInternalServerError, "Something went wrong")
+		return
+	}
+	hosts := make([]string, 0, len(entries))
+	checked := make(map[string]bool, len(entries))
+	for _, entry := range entries {
+		if !existing[entry.link] && !checked[entry.host] {
+			checked[entry.host] = true
+			hosts = append(hosts, entry.host)
+		}
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), importTimeout)
+	hostErrs := checkImportHosts(ctx, hosts)
+	cancel()
+
+	tx := r.rep.db.Begin()
+	if err = tx.Error; err != nil {
+		log.Println("Error in rssHandler.Import", err)
+		e.ErrorResponse(c, http.StatusInternalServerError, "Something went wrong")
+		return
+	}
+	txRep := NewRssRepository(tx)
+	domainIDs := make(map[string]uint)
+	for _, entry := range entries {
+		if existing[entry.link] {
+			report.Skipped = append(report.Skipped, types.RssImportItem{URL: entry.link, Domain: entry.domain, Reason: "Already added"})
+			continue
+		}
+		if err = hostErrs[entry.host]; err != nil {
+			report.Rejected = append(report.Rejected, types.RssImportItem{URL: entry.link, Domain: entry.domain,
+				Reason: hostRejectReason(err)})
+			continue
+		}
+
+		domainID, ok := domainIDs[entry.domain]
+		if !ok {
+			var created bool
+			domainID, created, err = r.domains.ResolveDomain(tx, orgID, entry.domain)
+			if err != nil {
+				tx.Rollback()
+				log.Println("Error in rssHandler.Import", err)
+				e.ErrorResponse(c, http.StatusInternalServerError, "Something went wrong")
+				return
+			}
+			if created {
+				report.CreatedDomains = append(report.CreatedDomains, entry.domain)
+			}
+			domainIDs[entry.domain] = domainID
+		}
+		model, err := txRep.CreateRss(orgID, domainID, entry.link)
+		if err != nil {
+			tx.Rollback()
+			e.ErrorResponse(c, http.StatusInternalServerError, "Something went wrong")
+			return
+		}
+		report.Created = append(report.Created, types.RssImportItem{URL: entry.link, ID: model.ID, Domain: entry.domain})
+	}
+	if err = tx.Commit().Error; err != nil {
+		log.Println("Error in rssHandler.Import", err)
+		e.ErrorResponse(c, http.StatusInternalServerError, "Something went wrong")
+		return
+	}
+
+	audit.Record(c, audit.Event{Action: audit.RssImport, TargetType: audit.TargetRss, Details: map[string]interface{}{
+		"file":     header.Filename,
+		"domains":  len(report.CreatedDomains),
+		"created":  len(report.Created),
+		"skipped":  len(report.Skipped),
+		"rejected": len(report.Rejected),
+	}})
+	types.SuccessResponse(c, report)
+}
+
+// Export godoc
+// @Summary Export OPML
+// @Description Export rss links of the organization to OPML file, feeds are grouped by domain
+// @ID export-rss
+// @Tags Rss
+// @Accept json
+// @Produce xml
+// @Success 200
+// @Failure 500 {object} types.ResponseErrorSwg
+// @Router /rss/export [get]
+// @Security ApiKeyAuth
+func (r rssHandler) Export(c *gin.Context) {
+	rows, err := r.rep.ListForExport(org.GetID(c))
+	if err != nil {
+		e.ErrorResponse(c, http.StatusInternalServerError, "Something went wrong")
+		return
+	}
+
+	var groups []opml.Group
+	for _, row := range rows {
+		if len(groups) == 0 || groups[len(groups)-1].Title != row.DomainName {
+			groups = append(groups, opml.Group{Title: row.DomainName})
+		}
+		g := &groups[len(groups)-1]
+		g.Feeds = append(g.Feeds, opml.Feed{XMLURL: row.Link})
+	}
+
+	buff := &bytes.Buffer{}
+	if err = opml.Write(buff, "OKO feeds", time.Now(), groups); err != nil {
+		e.ErrorResponse(c, http.StatusInternalServerError, "Something went wrong")
+		return
+	}
+
+	audit.Record(c, audit.Event{Action: audit.RssExport, TargetType: audit.TargetRss, Details: map[string]interface{}{
+		"domains": len(groups),
+		"feeds":   len(rows),
+	}})
+	extraHeaders := map[string]string{
+		"Content-Disposition": `attachment; filename="feeds.opml"`,
+	}
+	c.DataFromReader(http.StatusOK, int64(buff.Len()), "text/x-opml; charset=utf-8", buff, extraHeaders)
+}
@@ -0,0 +1,39 @@
+package rss
+
+import (
+	"context"
+	"oko/pkg/feed"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestImportLink(t *testing.T) {
+	entry, ok := importLink("https://WWW.Example.com/feed.xml#top")
+	require.True(t, ok)
+	require.Equal(t, importEntry{link: "https://WWW.Example.com/feed.xml", host: "www.example.com", domain: "example.com"}, entry)
+
+	_, ok = importLink("ftp://example.com/feed.xml")
+	require.False(t, ok)
+	_, ok = importLink("/feed.xml")
+	require.False(t, ok)
+}
+
+func TestCheckImportHosts(t *testing.T) {
+	hosts := []string{"localhost", "192.168.1.10", "::1", "93.184.216.34", "10.0.0.1", "1.1.1.1", "127.0.0.2",
+		"8.8.8.8", "172.16.0.1", "9.9.9.9"}
+	res := checkImportHosts(context.Background(), hosts)
+	require.Len(t, res, len(hosts))
+	for _, host := range []string{"localhost", "192.168.1.10", "::1", "10.0.0.1", "127.0.0.2", "172.16.0.1"} {
+		require.Equal(t, feed.ErrForbidden, res[host], host)
+	}
+	for _, host := range []string{"93.184.216.34", "1.1.1.1", "8.8.8.8", "9.9.9.9"} {
+		require.NoError(t, res[host], host)
+	}
+
+	// hosts are not resolved after the deadline
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	res = checkImportHosts(ctx, []string{"example.com"})
+	require.Error(t, res["example.com"])
+}
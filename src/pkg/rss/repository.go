@@ -3,10 +3,13 @@ package rss
 import (
 	"errors"
 	"oko/pkg/log"
+	"time"
 
 	"github.com/jinzhu/gorm"
 )
 
+var errDomainNotFound = errors.New("domain not found")
+
 type rssRepository struct {
 	db *gorm.DB
 }
@@ -17,27 +20,68 @@ func NewRssRepository(db *gorm.DB) *rssRepository { //nolint
 	}
 }
 
-func (r rssRepository) CreateRss(domainID uint, link string) (err error) {
-	if err = r.db.Create(&Rss{
-		Link:     link,
-		DomainID: domainID,
-	}).Error; err != nil {
+// scoped limits query to the organization.
+func (r rssRepository) scoped(orgID uint) *gorm.DB {
+	return r.db.Where("rss_links.organization_id = ?", orgID)
+}
+
+func (r rssRepository) domainExists(orgID, domainID uint) bool {
+	var count int
+	r.db.Table("domains").
+		Where("id = ? and organization_id = ? and deleted_at is null", domainID, orgID).
+		Count(&count)
+	return count > 0
+}
+
+// domainName returns name of the domain in the organization.
+func (r rssRepository) domainName(orgID, domainID uint) (string, error) {
+	var names []string
+	err := r.db.Table("domains").
+		Where("id = ? and organization_id = ? and deleted_at is null", domainID, orgID).
+		Pluck("name", &names).Error
+	if err != nil {
+		return "", err
+	}
+	if len(names) == 0 {
+		return "", errDomainNotFound
+	}
+	return names[0], nil
+}
+
+func (r rssRepository) CreateRss(orgID, domainID uint, link string) (model *Rss, err error) {
+	if !r.domainExists(orgID, domainID) {
+		return nil, errDomainNotFound
+	}
+	model = &Rss{
+		Link:           link,
+		DomainID:       domainID,
+		OrganizationID: orgID,
+	}
+	if err = r.db.Create(model).Error; err != nil {
 		log.Error("Error in RssRepository.CreateRss", err)
 	}
 	return
 }
 
-func (r rssRepository) UpdateRss(domainID uint, link string, rssID uint) (err error) {
+func (r rssRepository) UpdateRss(orgID, domainID uint, link string, rssID uint) (err error) {
+	if !r.domainExists(orgID, domainID) {
+		return errDomainNotFound
+	}
 	rss := &Rss{
 		Model: gorm.Model{
 			ID: rssID,
 		},
 	}
-	model := &Rss{
-		DomainID: domainID,
-		Link:     link,
+	// schedule state belongs to the old link, the new one is polled on the next run
+	model := map[string]interface{}{
+		"domain_id":     domainID,
+		"link":          link,
+		"etag":          "",
+		"last_modified": "",
+		"failure_count": 0,
+		"next_fetch_at": nil,
 	}
-	res := r.db.First(rss).Updates(model)
+	res := r.scoped(orgID).First(rss).Updates(model)
 	if err = res.Error; err != nil {
 		log.Print("Error in RssRepository.UpdateRss", err)
 		return
@@ -50,8 +94,8 @@ func (r rssRepository) UpdateRss(domainID uint, link string, rssID uint) (err er
 	return
 }
 
-func (r rssRepository) DeleteRss(rssID uint) (err error) {
-	if err = r.db.Delete(&Rss{
+func (r rssRepository) DeleteRss(orgID, rssID uint) (err error) {
+	if err = r.scoped(orgID).Delete(&Rss{
 		Model: gorm.Model{
 			ID: rssID,
 		},
@@ -61,20 +105,92 @@ func (r rssRepository) DeleteRss(rssID uint) (err error) {
 	return err
 }
 
-func (r rssRepository) List() (res []*Rss, err error) {
+func (r rssRepository) List(orgID uint) (res []*Rss, err error) {
 	res = []*Rss{}
-	err = r.db.Model(&res).Find(&res).Error
+	err = r.scoped(orgID).Model(&res).Find(&res).Error
 	if err != nil {
 		log.Print("Error in RssRepository.List", err)
 	}
 	return
 }
 
-func (r rssRepository) Get(id uint) (res *Rss, err error) {
+func (r rssRepository) Get(orgID, id uint) (res *Rss, err error) {
 	res = &Rss{}
-	err = r.db.Find(res, "id = ?", id).Error
+	err = r.scoped(orgID).Find(res, "id = ?", id).Error
 	if err != nil {
 		log.Print("Error in RssRepository.Get", err)
 	}
 	return
 }
+
+// ListForFetch returns rss links of existing domains due to be polled, least recently fetched first.
+func (r rssRepository) ListForFetch(now time.Time) (res []*Rss, err error) {
+	err = r.db.
+		Joins("join domains on domains.id = rss_links.domain_id and domains.deleted_at is null").
+		Where("rss_links.next_fetch_at is null or rss_links.next_fetch_at <= ?", now).
+		Order("rss_links.fetched_at nulls first").
+		Find(&res).Error
+	if err != nil {
+		log.Print("Error in RssRepository.ListForFetch", err)
+	}
+	return
+}
+
+// SaveFetchResult stores result of the feed fetch.
+func (r rssRepository) SaveFetchResult(id uint, res FetchResult) (err error) {
+	var fetchErr interface{}
+	if res.Error != "" {
+		fetchErr = res.Error
+	}
+	err = r.db.Model(&Rss{Model: gorm.Model{ID: id}}).UpdateColumns(map[string]interface{}{
+		"fetched_at":    res.FetchedAt,
+		"item_count":    res.ItemCount,
+		"error":         fetchErr,
+		"etag":          res.ETag,
+		"last_modified": res.LastModified,
+		"avg_item_gap":  int64(res.AvgItemGap / time.Second),
+		"failure_count": res.FailureCount,
+		"next_fetch_at": res.NextFetchAt,
+	}).Error
+	if err != nil {
+		log.Print("Error in RssRepository.SaveFetchResult", err)
+	}
+	return
+}
+
+// existingLinks returns those of the links the organization has already.
+func (r rssRepository) existingLinks(orgID uint, links []string) (map[string]bool, error) {
+	res := make(map[string]bool)
+	if len(links) == 0 {
+		return res, nil
+	}
+	var found []string
+	if err := r.scoped(orgID).Model(&Rss{}).Where("link in (?)", links).Pluck("link", &found).Error; err != nil {
+		return nil, err
+	}
+	for _, link := range found {
+		res[link] = true
+	}
+	return res, nil
+}
+
+// exportRow is rss link with name of its domain.
+type exportRow struct {
+	DomainName string
+	Link       string
+}
+
+// ListForExport returns rss links of the organization ordered by domain name.
+func (r rssRepository) ListForExport(orgID uint) (res []exportRow, err error) {
+	err = r.scoped(orgID).
+		Table("rss_links").
+		Select("domains.name as domain_name, rss_links.link").
+		Joins("join domains on domains.id = rss_links.domain_id and domains.deleted_at is null").
+		Where("rss_links.deleted_at is null").
+		Order("domains.name, rss_links.id").
+		Scan(&res).Error
+	if err != nil {
+		log.Print("Error in RssRepository.ListForExport", err)
+	}
+	return
+}
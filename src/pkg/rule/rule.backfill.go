@@ -0,0 +1,39 @@
+package rule
+
+import (
+	"context"
+	"oko/pkg/log"
+	pb "oko/srv/proxy/proto"
+
+	"github.com/jinzhu/gorm"
+)
+
+const backfillPerPage = 100
+
+// Backfill assigns rules of proxy service which aren't owned by any organization to the organization,
+// rules created before organizations are unassigned and can't be reached through the api otherwise.
+func Backfill(db *gorm.DB, orgID uint) (assigned int, err error) {
+	return NewHandler(db).backfill(orgID)
+}
+
+func (h handler) backfill(orgID uint) (assigned int, err error) {
+	for page := uint32(1); ; page++ {
+		list, err := h.srv.List(context.Background(), &pb.RuleListRequest{CurrentPage: page, PerPage: backfillPerPage})
+		if err != nil {
+			log.Println("Error in RuleHandler.backfill", err)
+			return assigned, err
+		}
+		for _, rule := range list.Data {
+			ok, err := h.repo.adopt(orgID, rule.Id, rule.Host)
+			if err != nil {
+				return assigned, err
+			}
+			if ok {
+				assigned++
+			}
+		}
+		if len(list.Data) == 0 || list.Meta == nil || page >= list.Meta.TotalPage {
+			return assigned, nil
+		}
+	}
+}
@@ -2,7 +2,9 @@ package rule
 
 import (
 	"oko/pkg/account"
+	"oko/pkg/db"
 	"oko/pkg/ginapp/controller"
+	"oko/pkg/org"
 
 	"github.com/gin-gonic/gin"
 )
@@ -17,17 +19,20 @@ type iHandler interface { //nolint
 }
 
 func NewController() controller.Ctrl {
-	ruleHandler := NewHandler()
+	ruleHandler := NewHandler(db.GetDB())
 	return controller.Ctrl{
 		Name:     "rule",
-		Handlers: controller.HandlerList{account.Auth(true, []int{})},
+		Handlers: controller.HandlerList{account.Auth(true, []int{}), org.Active()},
 		Acts: []controller.Act{
-			{Method: "GET", Route: "/:id", Handlers: controller.HandlerList{ruleHandler.Get}},
-			{Method: "GET", Route: "/", Handlers: controller.HandlerList{ruleHandler.List}},
-			{Method: "DELETE", Route: "/:id", Handlers: controller.HandlerList{ruleHandler.Delete}},
-			{Method: "POST", Route: "/", Handlers: controller.HandlerList{ruleHandler.Create}},
-			{Method: "PUT", Route: "/:id", Handlers: controller.HandlerList{ruleHandler.Update}},
-			{Method: "HEAD", Route: "/:id", Handlers: controller.HandlerList{ruleHandler.Exist}},
+			{Method: "GET", Route: "/:id", Handlers: controller.HandlerList{ruleHandler.Get}, Scope: account.ScopeRuleRead},
+			{Method: "GET", Route: "/", Handlers: controller.HandlerList{ruleHandler.List}, Scope: account.ScopeRuleRead},
+			{Method: "DELETE", Route: "/:id", Handlers: controller.HandlerList{ruleHandler.Delete},
+				Scope: account.ScopeRuleWrite, Permission: account.PermRuleWrite},
+			{Method: "POST", Route: "/", Handlers: controller.HandlerList{ruleHandler.Create},
+				Scope: account.ScopeRuleWrite, Permission: account.PermRuleWrite},
+			{Method: "PUT", Route: "/:id", Handlers: controller.HandlerList{ruleHandler.Update},
+				Scope: account.ScopeRuleWrite, Permission: account.PermRuleWrite},
+			{Method: "HEAD", Route: "/:id", Handlers: controller.HandlerList{ruleHandler.Exist}, Scope: account.ScopeRuleRead},
 		},
 	}
 }
@@ -2,17 +2,21 @@ package rule
 
 import (
 	"context"
+	"math"
 	"net/http"
+	"oko/pkg/audit"
+	"oko/pkg/db"
 	"oko/pkg/e"
 	"oko/pkg/env"
 	"oko/pkg/ginapp"
 	"oko/pkg/ginapp/types"
+	"oko/pkg/log"
+	"oko/pkg/org"
 	pb "oko/srv/proxy/proto"
 	"time"
 
-	"github.com/thoas/go-funk"
-
 	"github.com/gin-gonic/gin"
+	"github.com/jinzhu/gorm"
 
 	"github.com/micro/go-micro"
 	"github.com/micro/go-micro/client"
@@ -21,10 +25,11 @@ import (
 )
 
 type handler struct {
-	srv pb.RuleService
+	srv  pb.RuleService
+	repo *repository
 }
 
-func NewHandler() *handler { //nolint
+func NewHandler(db *gorm.DB) *handler { //nolint
 	reg := etcd.NewRegistry(
 		registry.Addrs(env.GetEnvOrPanic("ETCD_ADDRESS")),
 	)
@@ -38,7 +43,27 @@ func NewHandler() *handler { //nolint
 
 	ruleService := pb.NewRuleService("go.micro.srv.proxy", sdCl)
 
-	return &handler{srv: ruleService}
+	return &handler{srv: ruleService, repo: newRepository(db)}
+}
+
+// checkOwner responds with not found if the rule doesn't belong to active organization.
+func (h handler) checkOwner(c *gin.Context, id uint32) bool {
+	owns, err := h.repo.owns(org.GetID(c), id)
+	if err != nil {
+		e.ErrorResponse(c, http.StatusInternalServerError, "Something went wrong")
+		return false
+	}
+	if !owns {
+		e.ErrorResponse(c, http.StatusNotFound, "Rule not found")
+		return false
+	}
+	return true
+}
+
+// CheckOwner responds with not found if the rule doesn't belong to active organization,
+// handlers taking rule ids from other packages use it.
+func CheckOwner(c *gin.Context, id uint32) bool {
+	return handler{repo: newRepository(db.GetDB())}.checkOwner(c, id)
 }
 
 // Exist godoc
@@ -61,6 +86,15 @@ func (h handler) Exist(c *gin.Context) {
 		e.ErrorResponse(c, http.StatusBadRequest, err)
 		return
 	}
+	owns, err := h.repo.owns(org.GetID(c), id)
+	if err != nil {
+		e.ErrorResponse(c, http.StatusInternalServerError, "Something went wrong")
+		return
+	}
+	if !owns {
+		c.Status(http.StatusNotFound)
+		return
+	}
 	exist, err := h.srv.Exist(context.Background(), &pb.RuleRequest{Id: id})
 	if err != nil {
 		e.ErrorResponse(c, http.StatusInternalServerError, "Something went wrong")
@@ -102,13 +136,22 @@ func (h handler) Create(c *gin.Context) {
 		e.ErrorResponse(c, http.StatusInternalServerError, err)
 		return
 	}
+	if err = h.repo.assign(org.GetID(c), res.Data.Id, res.Data.Host); err != nil {
+		if _, delErr := h.srv.Delete(context.Background(), &pb.RuleRequest{Id: res.Data.Id}); delErr != nil {
+			log.Println("Fail to delete unassigned rule", delErr)
+		}
+		e.ErrorResponse(c, http.StatusInternalServerError, "Something went wrong")
+		return
+	}
+	audit.Record(c, audit.Event{Action: audit.RuleCreate, TargetType: audit.TargetRule, TargetID: res.Data.Id,
+		Details: req})
 
 	types.SuccessResponse(c, toView(res.Data))
 }
 
 // List godoc
 // @Summary List rules
-// @Description List rules
+// @Description List rules of active organization, page size is limited to 25
 // @ID list-rule
 // @Tags Rule
 // @Accept json
@@ -129,26 +172,36 @@ func (h handler) List(c *gin.Context) {
 		return
 	}
 
-	list, err := h.srv.List(context.Background(), &pb.RuleListRequest{
-		CurrentPage: form.CurrentPage,
-		PerPage:     form.PerPage,
-		Host:        host,
-	})
+	// rules are paginated by organization assignments, the service is asked for the page items
+	// one by one, so the page is small
+	form.BoundTo(types.MaxServicePerPage)
+	ids, count, err := h.repo.list(org.GetID(c), host, form.CurrentPage, form.PerPage)
 	if err != nil {
-		e.ErrorResponse(c, http.StatusInternalServerError, err)
+		e.ErrorResponse(c, http.StatusInternalServerError, "Something went wrong")
 		return
 	}
 
+	data := make([]View, 0, len(ids))
+	for _, id := range ids {
+		res, err := h.srv.Get(context.Background(), &pb.RuleRequest{Id: id})
+		if err != nil {
+			e.ErrorResponse(c, http.StatusInternalServerError, err)
+			return
+		}
+		if res != nil && res.Data != nil {
+			data = append(data, toView(res.Data))
+		}
+	}
+
 	meta := &types.PaginationResponse{
 		PaginationRequest: types.PaginationRequest{
-			CurrentPage: list.Meta.CurrentPage,
-			PerPage:     list.Meta.PerPage,
+			CurrentPage: form.CurrentPage,
+			PerPage:     form.PerPage,
 		},
-		TotalPages:   list.Meta.TotalPage,
-		TotalRecords: list.Meta.TotalRecord,
+		TotalPages:   uint32(math.Ceil(float64(count) / float64(form.PerPage))),
+		TotalRecords: count,
 	}
 
-	data := funk.Map(list.Data, toView)
 	result := types.Response{
 		Data: data,
 		Meta: meta,
@@ -177,6 +230,9 @@ func (h handler) Get(c *gin.Context) {
 		e.ErrorResponse(c, http.StatusBadRequest, err)
 		return
 	}
+	if !h.checkOwner(c, id) {
+		return
+	}
 
 	res, err := h.srv.Get(context.Background(), &pb.RuleRequest{Id: id})
 	if err != nil {
@@ -215,6 +271,9 @@ func (h handler) Update(c *gin.Context) {
 		e.ErrorResponse(c, http.StatusBadRequest, err)
 		return
 	}
+	if !h.checkOwner(c, id) {
+		return
+	}
 
 	res, err := h.srv.Update(context.Background(), &pb.RuleCreateRequest{
 		Id:     id,
@@ -225,6 +284,11 @@ func (h handler) Update(c *gin.Context) {
 		e.ErrorResponse(c, http.StatusInternalServerError, "Something went wrong")
 		return
 	}
+	if err = h.repo.updateHost(id, res.Data.Host); err != nil {
+		e.ErrorResponse(c, http.StatusInternalServerError, "Something went wrong")
+		return
+	}
+	audit.Record(c, audit.Event{Action: audit.RuleUpdate, TargetType: audit.TargetRule, TargetID: id, Details: req})
 
 	types.SuccessResponse(c, toView(res.Data))
 }
@@ -250,10 +314,19 @@ func (h handler) Delete(c *gin.Context) {
 		return
 	}
 
+	if !h.checkOwner(c, id) {
+		return
+	}
+
 	if _, err := h.srv.Delete(context.Background(), &pb.RuleRequest{Id: id}); err != nil {
 		e.ErrorResponse(c, http.StatusInternalServerError, "Something went wrong")
 		return
 	}
+	if err := h.repo.unassign(id); err != nil {
+		e.ErrorResponse(c, http.StatusInternalServerError, "Something went wrong")
+		return
+	}
+	audit.Record(c, audit.Event{Action: audit.RuleDelete, TargetType: audit.TargetRule, TargetID: id})
 
 	types.SuccessEmptyResponse(c)
 }
@@ -0,0 +1,94 @@
+package rule
+
+import (
+	"oko/pkg/db"
+	"oko/pkg/log"
+
+	"github.com/jinzhu/gorm"
+)
+
+// organizationRule assigns rule of proxy service to the organization owning it,
+// host is kept to filter and paginate rules of the organization without the service.
+type organizationRule struct {
+	OrganizationID uint   `gorm:"primary_key"`
+	RuleID         uint32 `gorm:"primary_key"`
+	Host           string
+}
+
+func (organizationRule) TableName() string {
+	return "organization_rule"
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+func newRepository(db *gorm.DB) *repository {
+	return &repository{db: db}
+}
+
+func (r repository) owns(orgID uint, ruleID uint32) (bool, error) {
+	var count int
+	err := r.db.Model(&organizationRule{}).
+		Where("organization_id = ? and rule_id = ?", orgID, ruleID).
+		Count(&count).Error
+	if err != nil {
+		log.Println("Error in RuleRepository.owns", err)
+	}
+	return count > 0, err
+}
+
+func (r repository) assign(orgID uint, ruleID uint32, host string) error {
+	err := r.db.Create(&organizationRule{OrganizationID: orgID, RuleID: ruleID, Host: host}).Error
+	if err != nil {
+		log.Println("Error in RuleRepository.assign", err)
+	}
+	return err
+}
+
+// adopt assigns the rule to the organization unless any organization owns it already.
+func (r repository) adopt(orgID uint, ruleID uint32, host string) (bool, error) {
+	res := r.db.Exec("INSERT INTO organization_rule (organization_id, rule_id, host) VALUES (?, ?, ?) "+
+		"ON CONFLICT (rule_id) DO NOTHING", orgID, ruleID, host)
+	if res.Error != nil {
+		log.Println("Error in RuleRepository.adopt", res.Error)
+	}
+	return res.RowsAffected > 0, res.Error
+}
+
+func (r repository) updateHost(ruleID uint32, host string) error {
+	err := r.db.Model(&organizationRule{}).Where("rule_id = ?", ruleID).Update("host", host).Error
+	if err != nil {
+		log.Println("Error in RuleRepository.updateHost", err)
+	}
+	return err
+}
+
+func (r repository) unassign(ruleID uint32) error {
+	err := r.db.Where("rule_id = ?", ruleID).Delete(&organizationRule{}).Error
+	if err != nil {
+		log.Println("Error in RuleRepository.unassign", err)
+	}
+	return err
+}
+
+// list returns page of rule ids of the organization, host filters rules by substring.
+func (r repository) list(orgID uint, host string, page, perPage uint32) (ids []uint32, count uint32, err error) {
+	var offset uint32
+	if page > 1 {
+		offset = (page - 1) * perPage
+	}
+
+	q := r.db.Model(&organizationRule{}).Where("organization_id = ?", orgID)
+	if host != "" {
+		q = q.Where("host like ?", db.LikeContains(host))
+	}
+	if err = q.Count(&count).Error; err != nil {
+		log.Println("Error in RuleRepository.list", err)
+		return
+	}
+	if err = q.Order("rule_id").Offset(offset).Limit(perPage).Pluck("rule_id", &ids).Error; err != nil {
+		log.Println("Error in RuleRepository.list", err)
+	}
+	return
+}
@@ -0,0 +1,107 @@
+package rule
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"oko/pkg/cfg"
+	"oko/pkg/db"
+	"oko/pkg/org"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/jinzhu/gorm"
+	"github.com/stretchr/testify/suite"
+)
+
+type RepositorySuite struct {
+	suite.Suite
+	mock sqlmock.Sqlmock
+	repo *repository
+}
+
+func TestRuleRepository(t *testing.T) {
+	suite.Run(t, new(RepositorySuite))
+}
+
+func (s *RepositorySuite) SetupTest() {
+	sqlDB, mock, err := sqlmock.New()
+	s.Require().NoError(err)
+	gdb, err := gorm.Open("postgres", sqlDB)
+	s.Require().NoError(err)
+	db.SetDB(gdb)
+	s.mock = mock
+	s.repo = newRepository(gdb)
+}
+
+func (s *RepositorySuite) TearDownTest() {
+	s.Require().NoError(s.mock.ExpectationsWereMet())
+}
+
+func (s *RepositorySuite) expectOwns(orgID uint, ruleID uint32, count int) {
+	s.mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "organization_rule"  WHERE (organization_id = $1 and rule_id = $2)`)).
+		WithArgs(orgID, ruleID).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(count))
+}
+
+func (s *RepositorySuite) TestOwns() {
+	s.expectOwns(1, 10, 1)
+	owns, err := s.repo.owns(1, 10)
+	s.NoError(err)
+	s.True(owns)
+
+	// rule 10 belongs to organization 1 only
+	s.expectOwns(2, 10, 0)
+	owns, err = s.repo.owns(2, 10)
+	s.NoError(err)
+	s.False(owns)
+}
+
+func (s *RepositorySuite) TestListOtherOrganization() {
+	s.mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "organization_rule"  WHERE (organization_id = $1)`)).
+		WithArgs(2).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	s.mock.ExpectQuery(regexp.QuoteMeta(`SELECT rule_id FROM "organization_rule"  WHERE (organization_id = $1) ORDER BY rule_id LIMIT 25 OFFSET 0`)).
+		WithArgs(2).WillReturnRows(sqlmock.NewRows([]string{"rule_id"}))
+	ids, count, err := s.repo.list(2, "", 1, 25)
+	s.NoError(err)
+	s.Empty(ids)
+	s.Zero(count)
+}
+
+func (s *RepositorySuite) TestListHost() {
+	s.mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "organization_rule"  WHERE (organization_id = $1) AND (host like $2)`)).
+		WithArgs(1, `%50\%\_off%`).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+	s.mock.ExpectQuery(regexp.QuoteMeta(`SELECT rule_id FROM "organization_rule"  WHERE (organization_id = $1) AND (host like $2) ORDER BY rule_id LIMIT 2 OFFSET 2`)).
+		WithArgs(1, `%50\%\_off%`).WillReturnRows(sqlmock.NewRows([]string{"rule_id"}).AddRow(12))
+	ids, count, err := s.repo.list(1, "50%_off", 2, 2)
+	s.NoError(err)
+	s.Equal([]uint32{12}, ids)
+	s.Equal(uint32(3), count)
+}
+
+func (s *RepositorySuite) TestAdoptOwned() {
+	s.mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO organization_rule (organization_id, rule_id, host) VALUES ($1, $2, $3) ON CONFLICT (rule_id) DO NOTHING`)).
+		WithArgs(2, 10, "example.com").WillReturnResult(sqlmock.NewResult(0, 0))
+	adopted, err := s.repo.adopt(2, 10, "example.com")
+	s.NoError(err)
+	s.False(adopted)
+}
+
+func (s *RepositorySuite) TestCheckOwner() {
+	cfg.App.OrgKey = "X-Organization"
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+	c.Request.Header.Set(cfg.App.OrgKey, "2")
+	c.Set("account_id", 7)
+	s.mock.ExpectQuery(`SELECT "organization_member".\* FROM "organization_member"`).
+		WithArgs(2, 7).WillReturnRows(sqlmock.NewRows([]string{"organization_id", "account_id", "role"}).AddRow(2, 7, org.RoleMember))
+	s.mock.ExpectQuery(`SELECT \* FROM "organization"`).
+		WithArgs(2).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(2))
+	org.Active()(c)
+	s.Require().False(c.IsAborted())
+
+	s.expectOwns(2, 10, 0)
+	s.False(CheckOwner(c, 10))
+	s.Equal(http.StatusNotFound, w.Code)
+}
@@ -0,0 +1,41 @@
+// Package token issues opaque random tokens, only SHA-256 hashes of the tokens are meant to be stored.
+package token
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"oko/pkg/rndstr"
+	"strings"
+)
+
+// New returns random token of length letters after the prefix, e.g. oko_live_at_<random>.
+// The prefix doesn't carry any data, it only makes leaked tokens easy to grep for.
+func New(prefix string, length int) string {
+	return prefix + rndstr.RandString(length)
+}
+
+// Hash returns hex encoded SHA-256 of the token.
+func Hash(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// Match reports if the token has the hash, the hashes are compared in constant time.
+func Match(token, hash string) bool {
+	return subtle.ConstantTimeCompare([]byte(Hash(token)), []byte(strings.ToLower(hash))) == 1
+}
+
+// Sign returns url-safe HMAC-SHA256 signature of the data, it's used for tokens verified without storage lookups.
+func Sign(secret []byte, data string) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(data)) //nolint
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// Verify reports if sig is the signature of the data, the signatures are compared in constant time.
+func Verify(secret []byte, data, sig string) bool {
+	return hmac.Equal([]byte(Sign(secret, data)), []byte(sig))
+}
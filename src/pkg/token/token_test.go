@@ -0,0 +1,40 @@
+package token
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	tok := New("oko_live_at_", 32)
+	require.True(t, strings.HasPrefix(tok, "oko_live_at_"))
+	require.Len(t, tok, len("oko_live_at_")+32)
+	require.NotEqual(t, tok, New("oko_live_at_", 32))
+}
+
+func TestHash(t *testing.T) {
+	// sha256 of "abc"
+	require.Equal(t, "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad", Hash("abc"))
+	require.NotEqual(t, Hash("abc"), Hash("abd"))
+}
+
+func TestMatch(t *testing.T) {
+	tok := New("oko_", 16)
+	hash := Hash(tok)
+	require.True(t, Match(tok, hash))
+	require.True(t, Match(tok, strings.ToUpper(hash)))
+	require.False(t, Match(tok+"x", hash))
+	require.False(t, Match(tok, ""))
+}
+
+func TestSign(t *testing.T) {
+	secret := []byte("secret")
+	sig := Sign(secret, "data")
+	require.NotContains(t, sig, "=")
+	require.True(t, Verify(secret, "data", sig))
+	require.False(t, Verify(secret, "other", sig))
+	require.False(t, Verify([]byte("other"), "data", sig))
+	require.False(t, Verify(secret, "data", ""))
+}
@@ -0,0 +1,127 @@
+// Package totp implements time-based one-time passwords (RFC 6238) compatible with authenticator apps.
+package totp
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1" //nolint:gosec
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	Period      = 30
+	Digits      = 6
+	secretBytes = 20
+)
+
+var b32 = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// Clock is a source of current time, tests replace it with fixed one.
+type Clock interface {
+	Now() time.Time
+}
+
+type SystemClock struct{}
+
+func (SystemClock) Now() time.Time {
+	return time.Now()
+}
+
+// Verifier checks codes allowing Skew periods of clock drift in both directions.
+type Verifier struct {
+	Clock Clock
+	Skew  int
+}
+
+func NewVerifier(clock Clock) Verifier {
+	return Verifier{Clock: clock, Skew: 1}
+}
+
+// GenerateSecret returns new random base32 encoded secret.
+func GenerateSecret() (string, error) {
+	buf := make([]byte, secretBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return b32.EncodeToString(buf), nil
+}
+
+// ProvisioningURI returns otpauth uri to be shown as QR code for authenticator app.
+func ProvisioningURI(issuer, accountName, secret string) string {
+	v := url.Values{}
+	v.Set("secret", secret)
+	v.Set("issuer", issuer)
+	v.Set("algorithm", "SHA1")
+	v.Set("digits", fmt.Sprint(Digits))
+	v.Set("period", fmt.Sprint(Period))
+	u := url.URL{
+		Scheme:   "otpauth",
+		Host:     "totp",
+		Path:     "/" + issuer + ":" + accountName,
+		RawQuery: v.Encode(),
+	}
+	return u.String()
+}
+
+// Step returns time step number of the time.
+func Step(t time.Time) int64 {
+	return t.Unix() / Period
+}
+
+// Code returns code of the secret for the time.
+func Code(secret string, t time.Time) (string, error) {
+	key, err := decodeSecret(secret)
+	if err != nil {
+		return "", err
+	}
+	return hotp(key, uint64(Step(t)), Digits), nil
+}
+
+// Verify checks the code against current time and returns matched time step,
+// so the caller could reject steps which were already used.
+func (v Verifier) Verify(secret, code string) (int64, bool) {
+	key, err := decodeSecret(secret)
+	if err != nil || len(key) == 0 || len(code) != Digits {
+		return 0, false
+	}
+	now := Step(v.Clock.Now())
+	for i := -v.Skew; i <= v.Skew; i++ {
+		step := now + int64(i)
+		if step < 0 {
+			continue
+		}
+		expected := hotp(key, uint64(step), Digits)
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(code)) == 1 {
+			return step, true
+		}
+	}
+	return 0, false
+}
+
+func decodeSecret(secret string) ([]byte, error) {
+	return b32.DecodeString(strings.ToUpper(strings.TrimRight(secret, "=")))
+}
+
+// hotp is HMAC-based one-time password of RFC 4226 with SHA1.
+func hotp(key []byte, counter uint64, digits int) string {
+	msg := make([]byte, 8)
+	binary.BigEndian.PutUint64(msg, counter)
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg) //nolint:errcheck
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	mod := uint32(1)
+	for i := 0; i < digits; i++ {
+		mod *= 10
+	}
+	return fmt.Sprintf("%0*d", digits, value%mod)
+}
@@ -0,0 +1,88 @@
+package totp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+// rfcSecret is the SHA1 seed of RFC 6238 appendix B.
+var rfcSecret = b32.EncodeToString([]byte("12345678901234567890"))
+
+func TestHotpRFC6238Vectors(t *testing.T) {
+	vectors := []struct {
+		unix int64
+		code string
+	}{
+		{59, "94287082"},
+		{1111111109, "07081804"},
+		{1111111111, "14050471"},
+		{1234567890, "89005924"},
+		{2000000000, "69279037"},
+		{20000000000, "65353130"},
+	}
+	key, err := decodeSecret(rfcSecret)
+	require.NoError(t, err)
+	for _, v := range vectors {
+		require.Equal(t, v.code, hotp(key, uint64(Step(time.Unix(v.unix, 0))), 8), "time %d", v.unix)
+	}
+}
+
+func TestCode(t *testing.T) {
+	code, err := Code(rfcSecret, time.Unix(59, 0))
+	require.NoError(t, err)
+	require.Equal(t, "287082", code)
+}
+
+func TestVerify(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1111111111, 0)}
+	v := NewVerifier(clock)
+	code, err := Code(rfcSecret, clock.now)
+	require.NoError(t, err)
+
+	step, ok := v.Verify(rfcSecret, code)
+	require.True(t, ok)
+	require.Equal(t, Step(clock.now), step)
+
+	// previous period is accepted because of the clock skew
+	clock.now = clock.now.Add(Period * time.Second)
+	step, ok = v.Verify(rfcSecret, code)
+	require.True(t, ok)
+	require.Equal(t, Step(clock.now)-1, step)
+
+	clock.now = clock.now.Add(2 * Period * time.Second)
+	_, ok = v.Verify(rfcSecret, code)
+	require.False(t, ok)
+}
+
+func TestVerifyRejectsMalformed(t *testing.T) {
+	v := NewVerifier(&fakeClock{now: time.Unix(59, 0)})
+	_, ok := v.Verify(rfcSecret, "28708")
+	require.False(t, ok)
+	_, ok = v.Verify("not base32!", "287082")
+	require.False(t, ok)
+}
+
+func TestGenerateSecret(t *testing.T) {
+	secret, err := GenerateSecret()
+	require.NoError(t, err)
+	key, err := decodeSecret(secret)
+	require.NoError(t, err)
+	require.Len(t, key, secretBytes)
+}
+
+func TestProvisioningURI(t *testing.T) {
+	uri := ProvisioningURI("OKO", "user@example.com", "JBSWY3DPEHPK3PXP")
+	require.Equal(t,
+		"otpauth://totp/OKO:user@example.com?algorithm=SHA1&digits=6&issuer=OKO&period=30&secret=JBSWY3DPEHPK3PXP",
+		uri)
+}
@@ -2,7 +2,9 @@ package trigger
 
 import (
 	"oko/pkg/account"
+	"oko/pkg/db"
 	"oko/pkg/ginapp/controller"
+	"oko/pkg/org"
 
 	"github.com/gin-gonic/gin"
 )
@@ -19,17 +21,21 @@ type iHandler interface { //nolint
 }
 
 func NewController() controller.Ctrl {
-	triggerHandler := NewHandler()
+	triggerHandler := NewHandler(db.GetDB())
 	return controller.Ctrl{
 		Name:     "trigger",
-		Handlers: controller.HandlerList{account.Auth(true, []int{})},
+		Handlers: controller.HandlerList{account.Auth(true, []int{}), org.Active()},
 		Acts: []controller.Act{
-			{Method: "GET", Route: "/", Handlers: controller.HandlerList{triggerHandler.List}},
-			{Method: "GET", Route: "/:id", Handlers: controller.HandlerList{triggerHandler.Get}},
-			{Method: "DELETE", Route: "/:id", Handlers: controller.HandlerList{triggerHandler.Delete}},
-			{Method: "HEAD", Route: "/:id", Handlers: controller.HandlerList{triggerHandler.Exist}},
-			{Method: "POST", Route: "/", Handlers: controller.HandlerList{triggerHandler.Create}},
-			{Method: "PUT", Route: "/:id", Handlers: controller.HandlerList{triggerHandler.Update}},
+			{Method: "GET", Route: "/", Handlers: controller.HandlerList{triggerHandler.List}, Scope: account.ScopeRuleRead},
+			{Method: "GET", Route: "/:id", Handlers: controller.HandlerList{triggerHandler.Get}, Scope: account.ScopeRuleRead},
+			{Method: "DELETE", Route: "/:id", Handlers: controller.HandlerList{triggerHandler.Delete},
+				Scope: account.ScopeRuleWrite, Permission: account.PermRuleWrite},
+			{Method: "HEAD", Route: "/:id", Handlers: controller.HandlerList{triggerHandler.Exist},
+				Scope: account.ScopeRuleRead},
+			{Method: "POST", Route: "/", Handlers: controller.HandlerList{triggerHandler.Create},
+				Scope: account.ScopeRuleWrite, Permission: account.PermRuleWrite},
+			{Method: "PUT", Route: "/:id", Handlers: controller.HandlerList{triggerHandler.Update},
+				Scope: account.ScopeRuleWrite, Permission: account.PermRuleWrite},
 		},
 	}
 }
@@ -2,20 +2,23 @@ package trigger
 
 import (
 	"context"
+	"math"
 	"net/http"
+	"oko/pkg/audit"
 	"oko/pkg/e"
 	"oko/pkg/env"
 	"oko/pkg/ginapp"
 	"oko/pkg/ginapp/types"
 	"oko/pkg/log"
+	"oko/pkg/org"
+	"oko/pkg/rule"
 	pb "oko/srv/proxy/proto"
 	"time"
 
-	"github.com/thoas/go-funk"
-
 	"github.com/golang/protobuf/ptypes/wrappers"
 
 	"github.com/gin-gonic/gin"
+	"github.com/jinzhu/gorm"
 
 	"github.com/micro/go-micro"
 	"github.com/micro/go-micro/client"
@@ -24,10 +27,11 @@ import (
 )
 
 type handler struct {
-	srv pb.TriggerService
+	srv  pb.TriggerService
+	repo *repository
 }
 
-func NewHandler() *handler { //nolint
+func NewHandler(db *gorm.DB) *handler { //nolint
 	reg := etcd.NewRegistry(
 		registry.Addrs(env.GetEnvOrPanic("ETCD_ADDRESS")),
 	)
@@ -38,7 +42,21 @@ func NewHandler() *handler { //nolint
 	cl := service.Client()
 
 	_ = cl.Init(client.RequestTimeout(time.Second * 30))
-	return &handler{srv: pb.NewTriggerService("go.micro.srv.proxy", cl)}
+	return &handler{srv: pb.NewTriggerService("go.micro.srv.proxy", cl), repo: newRepository(db)}
+}
+
+// checkOwner responds with not found if the trigger doesn't belong to active organization.
+func (h handler) checkOwner(c *gin.Context, id uint32) bool {
+	owns, err := h.repo.owns(org.GetID(c), id)
+	if err != nil {
+		e.ErrorResponse(c, http.StatusInternalServerError, "Something went wrong")
+		return false
+	}
+	if !owns {
+		e.ErrorResponse(c, http.StatusNotFound, "Trigger not found")
+		return false
+	}
+	return true
 }
 
 func ctx() context.Context {
@@ -66,6 +84,15 @@ func (h handler) Exist(c *gin.Context) {
 		return
 	}
 
+	owns, err := h.repo.owns(org.GetID(c), id)
+	if err != nil {
+		e.ErrorResponse(c, http.StatusInternalServerError, "Something went wrong")
+		return
+	}
+	if !owns {
+		c.Status(http.StatusNotFound)
+		return
+	}
 	exist, err := h.srv.Exist(ctx(), &pb.TriggerRequest{Id: id})
 	if err != nil {
 		e.ErrorResponse(c, http.StatusInternalServerError, "Something went wrong")
@@ -81,7 +108,7 @@ func (h handler) Exist(c *gin.Context) {
 
 // List godoc
 // @Summary List trigger item
-// @Description List trigger item
+// @Description List triggers of active organization, page size is limited to 25
 // @ID list-trigger
 // @Tags Trigger
 // @Accept json
@@ -108,37 +135,86 @@ func (h handler) List(c *gin.Context) {
 		return
 	}
 
-	req := &pb.TriggerListRequest{
-		CurrentPage: form.CurrentPage,
-		PerPage:     form.PerPage,
-	}
+	form.BoundTo(types.MaxServicePerPage)
 
+	var (
+		data []View
+		meta *types.PaginationResponse
+		ok   bool
+	)
 	if filter.RuleID != nil {
-		req.RuleId = &wrappers.UInt32Value{Value: *filter.RuleID}
+		data, meta, ok = h.listByRule(c, *filter.RuleID, form)
+	} else {
+		data, meta, ok = h.listByOrganization(c, form)
 	}
-	list, err := h.srv.List(ctx(), req)
-	if err != nil {
-		e.ErrorResponse(c, http.StatusInternalServerError, "Something went wrong")
+	if !ok {
 		return
 	}
 
-	meta := &types.PaginationResponse{
+	result := types.Response{
+		Data: data,
+		Meta: meta,
+	}
+
+	result.Success(c)
+}
+
+// listByRule returns page of triggers of the rule, triggers of the rule belong to the rule owner.
+func (h handler) listByRule(c *gin.Context, ruleID uint32, form types.PaginationRequest) (
+	[]View, *types.PaginationResponse, bool) {
+	if !rule.CheckOwner(c, ruleID) {
+		return nil, nil, false
+	}
+	list, err := h.srv.List(ctx(), &pb.TriggerListRequest{
+		CurrentPage: form.CurrentPage,
+		PerPage:     form.PerPage,
+		RuleId:      &wrappers.UInt32Value{Value: ruleID},
+	})
+	if err != nil || list == nil || list.Meta == nil {
+		e.ErrorResponse(c, http.StatusInternalServerError, "Something went wrong")
+		return nil, nil, false
+	}
+
+	data := make([]View, 0, len(list.Data))
+	for _, item := range list.Data {
+		data = append(data, toView(item))
+	}
+	return data, &types.PaginationResponse{
 		PaginationRequest: types.PaginationRequest{
 			CurrentPage: list.Meta.CurrentPage,
 			PerPage:     list.Meta.PerPage,
 		},
 		TotalPages:   list.Meta.TotalPage,
 		TotalRecords: list.Meta.TotalRecord,
-	}
-
-	data := funk.Map(list.Data, toView)
+	}, true
+}
 
-	result := types.Response{
-		Data: data,
-		Meta: meta,
+// listByOrganization returns page of triggers of active organization, the service is asked
+// for the page items only.
+func (h handler) listByOrganization(c *gin.Context, form types.PaginationRequest) (
+	[]View, *types.PaginationResponse, bool) {
+	ids, count, err := h.repo.list(org.GetID(c), form.CurrentPage, form.PerPage)
+	if err != nil {
+		e.ErrorResponse(c, http.StatusInternalServerError, "Something went wrong")
+		return nil, nil, false
 	}
 
-	result.Success(c)
+	data := make([]View, 0, len(ids))
+	for _, id := range ids {
+		res, err := h.srv.Get(ctx(), &pb.TriggerRequest{Id: id})
+		if err != nil {
+			e.ErrorResponse(c, http.StatusInternalServerError, "Something went wrong")
+			return nil, nil, false
+		}
+		if res != nil && res.Data != nil {
+			data = append(data, toView(res.Data))
+		}
+	}
+	return data, &types.PaginationResponse{
+		PaginationRequest: form,
+		TotalPages:        uint32(math.Ceil(float64(count) / float64(form.PerPage))),
+		TotalRecords:      count,
+	}, true
 }
 
 // Get godoc
@@ -161,6 +237,9 @@ func (h handler) Get(c *gin.Context) {
 		e.ErrorResponse(c, http.StatusBadRequest, err)
 		return
 	}
+	if !h.checkOwner(c, id) {
+		return
+	}
 	get, err := h.srv.Get(ctx(), &pb.TriggerRequest{Id: id})
 	if err != nil {
 		e.ErrorResponse(c, http.StatusInternalServerError, "Something went wrong")
@@ -201,6 +280,12 @@ func (h handler) Update(c *gin.Context) {
 		e.ErrorResponse(c, http.StatusBadRequest, err)
 		return
 	}
+	if !h.checkOwner(c, id) {
+		return
+	}
+	if trigger.RuleID != nil && !rule.CheckOwner(c, *trigger.RuleID) {
+		return
+	}
 
 	request := pb.TriggerUpdateRequest{
 		Id: &wrappers.UInt32Value{Value: id},
@@ -227,6 +312,8 @@ func (h handler) Update(c *gin.Context) {
 		e.ErrorResponse(c, http.StatusInternalServerError, "Something went wrong")
 		return
 	}
+	audit.Record(c, audit.Event{Action: audit.TriggerUpdate, TargetType: audit.TargetTrigger, TargetID: id,
+		Details: trigger})
 	types.SuccessResponse(c, toView(res.Data))
 }
 
@@ -251,6 +338,9 @@ func (h handler) Create(c *gin.Context) {
 		e.ErrorResponse(c, http.StatusBadRequest, err)
 		return
 	}
+	if !rule.CheckOwner(c, trigger.RuleID) {
+		return
+	}
 
 	res, err := h.srv.Create(context.Background(), &pb.TriggerCreateRequest{
 		Url:    trigger.URL,
@@ -262,6 +352,15 @@ func (h handler) Create(c *gin.Context) {
 		e.ErrorResponse(c, http.StatusInternalServerError, "Something went wrong")
 		return
 	}
+	if err = h.repo.assign(org.GetID(c), res.Data.Id); err != nil {
+		if _, delErr := h.srv.Delete(ctx(), &pb.TriggerRequest{Id: res.Data.Id}); delErr != nil {
+			log.Println("Fail to delete unassigned trigger", delErr)
+		}
+		e.ErrorResponse(c, http.StatusInternalServerError, "Something went wrong")
+		return
+	}
+	audit.Record(c, audit.Event{Action: audit.TriggerCreate, TargetType: audit.TargetTrigger, TargetID: res.Data.Id,
+		Details: trigger})
 
 	types.SuccessResponse(c, toView(res.Data))
 }
@@ -287,10 +386,19 @@ func (h handler) Delete(c *gin.Context) {
 		return
 	}
 
+	if !h.checkOwner(c, id) {
+		return
+	}
+
 	_, err = h.srv.Delete(ctx(), &pb.TriggerRequest{Id: id})
 	if err != nil {
 		e.ErrorResponse(c, http.StatusInternalServerError, err)
 		return
 	}
+	if err = h.repo.unassign(id); err != nil {
+		e.ErrorResponse(c, http.StatusInternalServerError, "Something went wrong")
+		return
+	}
+	audit.Record(c, audit.Event{Action: audit.TriggerDelete, TargetType: audit.TargetTrigger, TargetID: id})
 	types.SuccessEmptyResponse(c)
 }
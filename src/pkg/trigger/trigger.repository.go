@@ -0,0 +1,80 @@
+package trigger
+
+import (
+	"oko/pkg/log"
+
+	"github.com/jinzhu/gorm"
+)
+
+// organizationTrigger assigns trigger of proxy service to the organization owning it.
+type organizationTrigger struct {
+	OrganizationID uint   `gorm:"primary_key"`
+	TriggerID      uint32 `gorm:"primary_key"`
+}
+
+func (organizationTrigger) TableName() string {
+	return "organization_trigger"
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+func newRepository(db *gorm.DB) *repository {
+	return &repository{db: db}
+}
+
+func (r repository) owns(orgID uint, triggerID uint32) (bool, error) {
+	var count int
+	err := r.db.Model(&organizationTrigger{}).
+		Where("organization_id = ? and trigger_id = ?", orgID, triggerID).
+		Count(&count).Error
+	if err != nil {
+		log.Println("Error in TriggerRepository.owns", err)
+	}
+	return count > 0, err
+}
+
+func (r repository) assign(orgID uint, triggerID uint32) error {
+	err := r.db.Create(&organizationTrigger{OrganizationID: orgID, TriggerID: triggerID}).Error
+	if err != nil {
+		log.Println("Error in TriggerRepository.assign", err)
+	}
+	return err
+}
+
+// adopt assigns the trigger to the organization unless any organization owns it already.
+func (r repository) adopt(orgID uint, triggerID uint32) (bool, error) {
+	res := r.db.Exec("INSERT INTO organization_trigger (organization_id, trigger_id) VALUES (?, ?) "+
+		"ON CONFLICT (trigger_id) DO NOTHING", orgID, triggerID)
+	if res.Error != nil {
+		log.Println("Error in TriggerRepository.adopt", res.Error)
+	}
+	return res.RowsAffected > 0, res.Error
+}
+
+func (r repository) unassign(triggerID uint32) error {
+	err := r.db.Where("trigger_id = ?", triggerID).Delete(&organizationTrigger{}).Error
+	if err != nil {
+		log.Println("Error in TriggerRepository.unassign", err)
+	}
+	return err
+}
+
+// list returns page of trigger ids of the organization.
+func (r repository) list(orgID uint, page, perPage uint32) (ids []uint32, count uint32, err error) {
+	var offset uint32
+	if page > 1 {
+		offset = (page - 1) * perPage
+	}
+
+	q := r.db.Model(&organizationTrigger{}).Where("organization_id = ?", orgID)
+	if err = q.Count(&count).Error; err != nil {
+		log.Println("Error in TriggerRepository.list", err)
+		return
+	}
+	if err = q.Order("trigger_id").Offset(offset).Limit(perPage).Pluck("trigger_id", &ids).Error; err != nil {
+		log.Println("Error in TriggerRepository.list", err)
+	}
+	return
+}
@@ -4,12 +4,15 @@ import (
 	"oko/pkg/account"
 	"oko/pkg/cfg"
 	"oko/pkg/db"
+	"oko/pkg/log"
+	"oko/pkg/password"
 	"oko/pkg/repost"
 	"oko/pkg/util"
 	"oko/srv/proxy/entity"
-	"strings"
+	"reflect"
 	"time"
 
+	"github.com/jinzhu/gorm"
 	"gopkg.in/go-playground/validator.v9"
 )
 
@@ -20,32 +23,50 @@ type Item struct {
 }
 
 var Validators = []Item{
-	{"StrongPass", StrongPass, "Password must contain one lowercase letter, one uppercase letter and one number and must be at least 8 characters"}, //nolint
+	{"StrongPass", StrongPass, ""},
 	{"UniqueEmail", UniqueEmail, "Email already exist"},
 	{"ExistsEmail", ExistsEmail, "Not registered e-mail"},
 	{"NotEmpty", NotEmpty, "Field must not be empty"},
 	{"UniqueList", UniqueList, "Duplicate items in list"},
 	{"SignUpToken", SignUpToken, "Invalid sign up token"},
+	{"Invite", Invite, "Invalid invite"},
 	{"RecoverToken", RecoverToken, "Invalid recover token"},
 	{"RecoverTokenNotExp", RecoverTokenNotExp, "Recover token expired"},
 	{"RecoverTokenNotUsed", RecoverTokenNotUsed, "Recover token already used"},
 	{"UniqueRepostRequest", UniqueRepostRequest, "Repost request already exist"},
 	{"ExistsRepostRequest", ExistsRepostRequest, "Repost request not found"},
 	{"CheckRuleStatus", CheckRuleStatus, "Unknown rule status"},
+	{"AccRole", AccRole, "Unknown role"},
+	{"AccStatus", AccStatus, "Unknown account status"},
+	{"APIKeyScope", APIKeyScope, "Unknown scope"},
 	{"email", nil, "Is not a valid e-mail"},
 	{"url", nil, "Is not a valid URL"},
 	{"eqfield", nil, "Don\"t match"},
 	{"required", nil, "Field is required"},
 }
 
+// MessageFuncs build messages which depend on settings, they are applied after settings are loaded.
+var MessageFuncs = map[string]func() string{
+	"StrongPass": func() string { return cfg.App.Password.Describe() },
+}
+
+// StrongPass checks password against the password policy, Email and Name fields of the form
+// are used as the account identity.
 func StrongPass(fl validator.FieldLevel) bool {
 	if pass, ok := fl.Field().Interface().(string); ok {
-		if len(pass) < cfg.App.MinPassLen ||
-			!strings.ContainsAny(pass, "1234567890") ||
-			strings.ToLower(pass) == pass ||
-			strings.ToUpper(pass) == pass {
-			return false
+		var identity []string
+		if parent := fl.Parent(); parent.Kind() == reflect.Struct {
+			for _, name := range []string{"Email", "Name"} {
+				if f := parent.FieldByName(name); f.IsValid() && f.Kind() == reflect.String {
+					identity = append(identity, f.String())
+				}
+			}
 		}
+		ok, err := password.Check(pass, identity...)
+		if err != nil {
+			log.Println("Error in StrongPass", err)
+		}
+		return ok
 	}
 	return true
 }
@@ -105,6 +126,15 @@ func SignUpToken(fl validator.FieldLevel) bool {
 	return true
 }
 
+func Invite(fl validator.FieldLevel) bool {
+	if val, ok := fl.Field().Interface().(string); ok {
+		if _, err := account.GetByInviteToken(val); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
 func RecoverToken(fl validator.FieldLevel) bool {
 	if val, ok := fl.Field().Interface().(string); ok {
 		rt, err := account.GetByRecoverToken(val)
@@ -122,7 +152,8 @@ func RecoverTokenNotExp(fl validator.FieldLevel) bool {
 	if val, ok := fl.Field().Interface().(string); ok {
 		rt, err := account.GetRecoverToken(val)
 		if err != nil {
-			return false
+			// unknown token is reported by RecoverToken validator
+			return gorm.IsRecordNotFoundError(err)
 		}
 		if rt.ExpireAt.Before(time.Now()) {
 			return false
@@ -134,7 +165,7 @@ func RecoverTokenNotUsed(fl validator.FieldLevel) bool {
 	if val, ok := fl.Field().Interface().(string); ok {
 		rt, err := account.GetRecoverToken(val)
 		if err != nil {
-			return false
+			return gorm.IsRecordNotFoundError(err)
 		}
 		if rt.IsUsed {
 			return false
@@ -171,3 +202,25 @@ func CheckRuleStatus(fl validator.FieldLevel) bool {
 	}
 	return true
 }
+
+func AccRole(fl validator.FieldLevel) bool {
+	if val, ok := fl.Field().Interface().(string); ok {
+		return account.GetRole(val) != 0
+	}
+	return true
+}
+
+func AccStatus(fl validator.FieldLevel) bool {
+	if val, ok := fl.Field().Interface().(uint); ok {
+		_, exists := account.AccStatusStr[val]
+		return exists
+	}
+	return true
+}
+
+func APIKeyScope(fl validator.FieldLevel) bool {
+	if val, ok := fl.Field().Interface().(string); ok {
+		return account.APIKeyScopes[val]
+	}
+	return true
+}